@@ -0,0 +1,199 @@
+// backfill-boe-links links existing BoE sales, recorded before sales could
+// be tied to a roster Player and a catalog Item, to those records by
+// fuzzy-matching their free-text PlayerName/ItemName (the same matching the
+// BoE form's suggestions use).
+//
+// This is a one-off script, not part of the server: run it once after
+// deploying the links, then again only if a batch of unlinked sales shows
+// up (e.g. from an old spreadsheet import).
+//
+// A sale is only linked when its best match scores at least -min-score and
+// is strictly better than the runner-up — anything ambiguous or unmatched
+// is listed for someone to link by hand from the BoE page. Linking copies
+// the player's/item's canonical name onto the sale and, through
+// utilities.RenameBoePlayer (the same rename the BoE form does), renames the
+// team's payouts and links its other sales recorded under the old player
+// name, so balances stay together. Each sale is linked in one transaction.
+//
+// Dry run by default; pass -apply to write.
+//
+// Usage:
+//
+//	go run ./cmd/backfill-boe-links [-team 12] [-min-score 0.8] [-apply]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"krankenprep/utilities"
+	"log"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+// match is the outcome of matching one free-text name.
+type match struct {
+	id     uint
+	name   string
+	reason string // why nothing was linked, when id is 0
+}
+
+// pick applies the linking rule to ranked scores: the best must clear
+// minScore (already filtered by the suggest functions) and beat the
+// runner-up outright.
+func pick(count int, bestScore, runnerUpScore float64) string {
+	switch {
+	case count == 0:
+		return "no match"
+	case count > 1 && runnerUpScore >= bestScore:
+		return "ambiguous"
+	}
+	return ""
+}
+
+func matchPlayer(teamId uint, name string, minScore float64) (match, error) {
+	suggestions, err := utilities.SuggestTeamPlayers(teamId, name, minScore, 2)
+	if err != nil {
+		return match{}, err
+	}
+	runnerUp := 0.0
+	if len(suggestions) > 1 {
+		runnerUp = suggestions[1].Score
+	}
+	best := 0.0
+	if len(suggestions) > 0 {
+		best = suggestions[0].Score
+	}
+	if reason := pick(len(suggestions), best, runnerUp); reason != "" {
+		return match{reason: reason}, nil
+	}
+	return match{id: suggestions[0].PlayerID, name: suggestions[0].PlayerName}, nil
+}
+
+func matchItem(name string, minScore float64) (match, error) {
+	suggestions, err := utilities.SuggestItems(name, minScore, 2)
+	if err != nil {
+		return match{}, err
+	}
+	runnerUp := 0.0
+	if len(suggestions) > 1 {
+		runnerUp = suggestions[1].Score
+	}
+	best := 0.0
+	if len(suggestions) > 0 {
+		best = suggestions[0].Score
+	}
+	if reason := pick(len(suggestions), best, runnerUp); reason != "" {
+		return match{reason: reason}, nil
+	}
+	return match{id: suggestions[0].WowItemID, name: suggestions[0].Name}, nil
+}
+
+func main() {
+	teamFlag := flag.Uint("team", 0, "only backfill this team's sales (default: every team)")
+	minScore := flag.Float64("min-score", utilities.AutoLinkMinScore, "lowest match score (0-1) that gets linked")
+	apply := flag.Bool("apply", false, "write the links (default is a dry run that only reports)")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+	database.Connect()
+	db := database.DB
+
+	query := db.Where("player_id IS NULL OR wow_item_id IS NULL").Order("team_id, created_at")
+	if *teamFlag != 0 {
+		query = query.Where("team_id = ?", *teamFlag)
+	}
+	var sales []models.BoeSale
+	if err := query.Find(&sales).Error; err != nil {
+		log.Fatalf("fetching BoE sales: %v", err)
+	}
+	if !*apply {
+		fmt.Println("Dry run — pass -apply to write links.")
+	}
+	fmt.Printf("%d sales with a missing link\n", len(sales))
+
+	// Names repeat heavily (the same seller, the same popular BoE), so each
+	// distinct name is matched once.
+	playerMatches := make(map[string]match)
+	itemMatches := make(map[string]match)
+	var playersLinked, itemsLinked int
+	var payoutsRenamed int64
+	var unresolved []string
+
+	for _, sale := range sales {
+		updates := map[string]interface{}{}
+		var linkedPlayer *models.Player
+
+		if sale.PlayerID == nil {
+			key := fmt.Sprintf("%d:%s", sale.TeamID, utilities.NameMatchKey(sale.PlayerName))
+			m, ok := playerMatches[key]
+			if !ok {
+				var err error
+				if m, err = matchPlayer(sale.TeamID, sale.PlayerName, *minScore); err != nil {
+					log.Fatalf("matching player %q: %v", sale.PlayerName, err)
+				}
+				playerMatches[key] = m
+			}
+			if m.id == 0 {
+				unresolved = append(unresolved, fmt.Sprintf("sale %d: player %q — %s", sale.ID, sale.PlayerName, m.reason))
+			} else {
+				updates["player_id"] = m.id
+				updates["player_name"] = m.name
+				linkedPlayer = &models.Player{ID: m.id, Name: m.name}
+				playersLinked++
+				fmt.Printf("sale %d: player %q -> %q (player %d)\n", sale.ID, sale.PlayerName, m.name, m.id)
+			}
+		}
+
+		if sale.WowItemID == nil {
+			key := utilities.NameMatchKey(sale.ItemName)
+			m, ok := itemMatches[key]
+			if !ok {
+				var err error
+				if m, err = matchItem(sale.ItemName, *minScore); err != nil {
+					log.Fatalf("matching item %q: %v", sale.ItemName, err)
+				}
+				itemMatches[key] = m
+			}
+			if m.id == 0 {
+				unresolved = append(unresolved, fmt.Sprintf("sale %d: item %q — %s", sale.ID, sale.ItemName, m.reason))
+			} else {
+				updates["wow_item_id"] = m.id
+				updates["item_name"] = m.name
+				itemsLinked++
+				fmt.Printf("sale %d: item %q -> %q (wow item %d)\n", sale.ID, sale.ItemName, m.name, m.id)
+			}
+		}
+
+		if *apply && len(updates) > 0 {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Model(&models.BoeSale{}).Where("id = ?", sale.ID).Updates(updates).Error; err != nil {
+					return err
+				}
+				if linkedPlayer == nil {
+					return nil
+				}
+				rename, err := utilities.RenameBoePlayer(tx, sale.TeamID, sale.PlayerName, *linkedPlayer)
+				payoutsRenamed += rename.PayoutsRenamed
+				return err
+			})
+			if err != nil {
+				log.Fatalf("linking sale %d: %v", sale.ID, err)
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Players linked: %d, items linked: %d, payouts renamed: %d\n", playersLinked, itemsLinked, payoutsRenamed)
+	if len(unresolved) > 0 {
+		fmt.Printf("%d left to link by hand:\n", len(unresolved))
+		for _, line := range unresolved {
+			fmt.Println("  " + line)
+		}
+	}
+}
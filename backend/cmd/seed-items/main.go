@@ -12,7 +12,8 @@
 //
 // Weapons and trinkets pause for an interactive terminal prompt (primary
 // stat, and for trinkets only, an additional eligible-role prompt — some
-// trinkets are role-locked, e.g. Tank+DPS only, independent of stat).
+// trinkets are role-locked, e.g. Tank+DPS only, independent of stat). Tier
+// tokens prompt for the tier slot they convert into, saved as a TierToken.
 // Everything else (armor, rings, necks, cloaks, off-hand caster items) is
 // fully automatic.
 //
@@ -243,6 +244,7 @@ func seedItem(db *gorm.DB, stdin *bufio.Reader, boss models.Boss, season models.
 
 	var primaryStats []string
 	var eligibleRoles []string
+	var tierSlot string
 	slotLower := strings.ToLower(parsed.Slot)
 
 	switch {
@@ -297,6 +299,7 @@ func seedItem(db *gorm.DB, stdin *bufio.Reader, boss models.Boss, season models.
 			return fmt.Errorf("tier token %q matched prefix but armor type %q not found: %w", tt.Name, armorTypeName, err)
 		}
 		item.ArmorTypeID = &armorType.ID
+		tierSlot = promptTierSlot(stdin, tt.Name)
 
 	default:
 		// Ring / Neck / Cloak / anything else unrecognized — universal, no stat gate.
@@ -331,6 +334,16 @@ func seedItem(db *gorm.DB, stdin *bufio.Reader, boss models.Boss, season models.
 		}
 	}
 
+	if err := db.Where("item_id = ?", item.ID).Delete(&models.TierToken{}).Error; err != nil {
+		return fmt.Errorf("clearing existing tier token: %w", err)
+	}
+	if tierSlot != "" {
+		row := models.TierToken{ItemID: item.ID, Slot: tierSlot}
+		if err := db.Create(&row).Error; err != nil {
+			return fmt.Errorf("saving tier token slot %s: %w", tierSlot, err)
+		}
+	}
+
 	fmt.Printf("  %s (%d) -> %s [stats: %s] [roles: %s]\n", item.Name, item.WowItemID, boss.Name, strings.Join(primaryStats, ","), strings.Join(eligibleRoles, ","))
 	return nil
 }
@@ -443,3 +456,31 @@ func promptEligibleRoles(stdin *bufio.Reader) []string {
 	}
 	return roles
 }
+
+// promptTierSlot asks which tier slot a token converts into — token names
+// don't follow a pattern reliable enough to parse the slot from. A blank or
+// unrecognized answer saves the item without a TierToken row, so it still
+// works as a wishable item but won't feed the tier tracker.
+func promptTierSlot(stdin *bufio.Reader, name string) string {
+	fmt.Printf("\n%s (tier token)\n", name)
+	fmt.Print("  Tier slot [head/shoulder/chest/legs/gloves, blank = skip]: ")
+
+	input, _ := stdin.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "head":
+		return models.TierSlotHead
+	case "shoulder", "shoulders":
+		return models.TierSlotShoulder
+	case "chest":
+		return models.TierSlotChest
+	case "legs":
+		return models.TierSlotLegs
+	case "gloves", "hands":
+		return models.TierSlotGloves
+	case "":
+		return ""
+	default:
+		fmt.Printf("  (ignoring unrecognized tier slot %q)\n", strings.TrimSpace(input))
+		return ""
+	}
+}
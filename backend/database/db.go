@@ -67,6 +67,7 @@ func Connect() {
 		&models.ArmorType{},
 		&models.WeaponType{},
 		&models.Specialization{},
+		&models.SpecializationTag{},
 		&models.Item{},
 		&models.ItemPrimaryStat{},
 		&models.ItemEligibleRole{},
@@ -75,30 +76,52 @@ func Connect() {
 		&models.CharacterBossBonusRolls{},
 		&models.LootAuditLog{},
 		&models.CharacterTierSlot{},
+		&models.CharacterTierSlotChange{},
+		&models.TierSetSnapshot{},
+		&models.CharacterGearSync{},
 		&models.TierSimEntry{},
+		&models.TierSimImport{},
+		&models.TierToken{},
+		&models.CatalystChargeWeek{},
 		&models.BoeSale{},
+		&models.BoePayout{},
+		&models.BoeSplitRule{},
+		&models.GoldTransaction{},
+		&models.WishlistLockWindow{},
+		&models.EPGPSettings{},
+		&models.EPGPLedgerEntry{},
+		&models.RaidEvent{},
+		&models.RaidEventBoss{},
+		&models.RaidAttendance{},
+		&models.RaidSchedule{},
+		&models.ScheduledRaid{},
+		&models.RaidSignUp{},
+		&models.BossRosterEntry{},
+		&models.BossAssignment{},
+		&models.BossAssignmentSlot{},
 	); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
-	// Enable pg_trgm and create trigram index for fuzzy spell search
+	// Enable pg_trgm and create trigram indexes for fuzzy spell/item search
 	db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_spell_name_trgm ON spells USING gin (spell_name gin_trgm_ops)")
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_item_name_trgm ON items USING gin (name gin_trgm_ops)")
 
-	fixDifficultyUniqueIndexes(db)
+	fixWidenedUniqueIndexes(db)
 
 	DB = db
 	log.Println("Connected to Postgres and ran migrations")
 }
 
-// fixDifficultyUniqueIndexes re-creates the composite unique indexes on
-// CharacterItemWish/CharacterBossPriority/CharacterBossBonusRolls with their
-// current column set. AutoMigrate adds new columns (e.g. the Difficulty
-// field added after these indexes already existed) but never redefines an
-// already-existing index to include them, so the old 2-column index sticks
-// around and rejects the 3-column ON CONFLICT clauses/uniqueness these
-// models now rely on. Safe to run on every boot.
-func fixDifficultyUniqueIndexes(db *gorm.DB) {
+// fixWidenedUniqueIndexes re-creates composite unique indexes that gained
+// a column after they were first created — CharacterItemWish/
+// CharacterBossPriority/CharacterBossBonusRolls gained Difficulty, and
+// TierSimEntry gained TeamID. AutoMigrate adds new columns but never
+// redefines an already-existing index to include them, so the old narrower
+// index sticks around and rejects the wider ON CONFLICT clauses/uniqueness
+// these models now rely on. Safe to run on every boot.
+func fixWidenedUniqueIndexes(db *gorm.DB) {
 	indexes := []struct {
 		name    string
 		table   string
@@ -107,6 +130,7 @@ func fixDifficultyUniqueIndexes(db *gorm.DB) {
 		{"idx_char_item", "character_item_wishes", "character_id, item_id, difficulty"},
 		{"idx_char_boss_priority", "character_boss_priorities", "character_id, boss_id, difficulty"},
 		{"idx_char_boss_rolls", "character_boss_bonus_rolls", "character_id, boss_id, difficulty"},
+		{"idx_season_spec_build", "tier_sim_entries", "team_id, season_id, specialization_id, build_label"},
 	}
 	for _, idx := range indexes {
 		if err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", idx.name)).Error; err != nil {
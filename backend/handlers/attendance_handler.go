@@ -0,0 +1,544 @@
+package handlers
+
+import (
+	"krankenprep/database"
+	"krankenprep/models"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// seasonIDForDate resolves the season a raid event belongs to: the one
+// whose dates cover t. An event always has an explicit date, so one no
+// season covers is the caller's error rather than quietly filed under the
+// current season.
+func seasonIDForDate(t time.Time) (uint, bool) {
+	var seasons []models.Season
+	database.DB.Order("start_date").Find(&seasons)
+	if season, found := seasonForDate(seasons, t); found {
+		return season.Id, true
+	}
+	return 0, false
+}
+
+// attendanceStatusRanks orders statuses for rolling a player's characters
+// up to one status per event — if any of their characters was present,
+// the player was present, and so on down.
+var attendanceStatusRanks = map[string]int{
+	models.AttendancePresent: 5,
+	models.AttendanceLate:    4,
+	models.AttendanceBench:   3,
+	models.AttendanceExcused: 2,
+	models.AttendanceAbsent:  1,
+}
+
+type raidEventBossPayload struct {
+	BossID uint `json:"boss_id"`
+	Pulls  uint `json:"pulls"`
+	Killed bool `json:"killed"`
+}
+
+type raidAttendancePayload struct {
+	CharacterID uint   `json:"character_id"`
+	Status      string `json:"status"`
+	Note        string `json:"note"`
+}
+
+type raidEventPayload struct {
+	Date       time.Time              `json:"date"`
+	Difficulty string                 `json:"difficulty"`
+	Note       string                 `json:"note"`
+	Bosses     []raidEventBossPayload `json:"bosses"`
+	// Attendance is only read on create; afterwards it's edited through
+	// UpsertRaidAttendance.
+	Attendance []raidAttendancePayload `json:"attendance"`
+}
+
+// validateRaidEventBosses checks every pulled boss belongs to the event's
+// season and appears once. Writes a 400 and returns false if not.
+func validateRaidEventBosses(c *gin.Context, seasonId uint, bosses []raidEventBossPayload) bool {
+	valid := make(map[uint]bool)
+	for _, b := range seasonBosses(seasonId) {
+		valid[b.ID] = true
+	}
+	seen := make(map[uint]bool, len(bosses))
+	for _, b := range bosses {
+		if !valid[b.BossID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "boss is not part of this season's raids"})
+			return false
+		}
+		if seen[b.BossID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "each boss can only be listed once"})
+			return false
+		}
+		seen[b.BossID] = true
+	}
+	return true
+}
+
+// validateRaidAttendance checks statuses and that every character is on
+// the team's roster, once. Writes a 400 and returns false if not.
+func validateRaidAttendance(c *gin.Context, teamId uint, attendance []raidAttendancePayload) bool {
+	characterIds := make([]uint, 0, len(attendance))
+	seen := make(map[uint]bool, len(attendance))
+	for _, a := range attendance {
+		if _, valid := attendanceStatusRanks[a.Status]; !valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be present, late, bench, absent or excused"})
+			return false
+		}
+		if seen[a.CharacterID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "each character can only be listed once"})
+			return false
+		}
+		seen[a.CharacterID] = true
+		characterIds = append(characterIds, a.CharacterID)
+	}
+	if len(characterIds) > 0 {
+		if _, ok := loadTeamCharacters(teamId, characterIds); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "character not found on this team's roster"})
+			return false
+		}
+	}
+	return true
+}
+
+// saveRaidAttendance upserts attendance rows for an event, keyed on
+// (event, character).
+func saveRaidAttendance(tx *gorm.DB, eventId uint, attendance []raidAttendancePayload) error {
+	if len(attendance) == 0 {
+		return nil
+	}
+	rows := make([]models.RaidAttendance, len(attendance))
+	for i, a := range attendance {
+		rows[i] = models.RaidAttendance{RaidEventID: eventId, CharacterID: a.CharacterID, Status: a.Status, Note: a.Note}
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "raid_event_id"}, {Name: "character_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "note", "updated_at"}),
+	}).Create(&rows).Error
+}
+
+func raidEventBossRows(eventId uint, bosses []raidEventBossPayload) []models.RaidEventBoss {
+	rows := make([]models.RaidEventBoss, len(bosses))
+	for i, b := range bosses {
+		rows[i] = models.RaidEventBoss{RaidEventID: eventId, BossID: b.BossID, Pulls: b.Pulls, Killed: b.Killed}
+	}
+	return rows
+}
+
+// GetRaidEvents lists the team's raid events in the given (or current)
+// season, newest first, with bosses pulled and attendance. Visible to
+// every team member.
+func GetRaidEvents(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var seasonId uint
+	if q := c.Query("season_id"); q != "" {
+		parsed, err := strconv.ParseUint(q, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+			return
+		}
+		seasonId = uint(parsed)
+	} else {
+		id, ok := currentSeasonID()
+		if !ok {
+			c.JSON(http.StatusOK, gin.H{"events": []models.RaidEvent{}})
+			return
+		}
+		seasonId = id
+	}
+
+	events := []models.RaidEvent{}
+	database.DB.Preload("Bosses").Preload("Attendance").
+		Where("team_id = ? AND season_id = ?", teamId, seasonId).
+		Order("date desc").
+		Find(&events)
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// CreateRaidEvent records a raid night — its bosses and, optionally, the
+// initial attendance in one go. The season is taken from the date.
+// Loot-council/admin/owner only, since attendance feeds loot decisions.
+func CreateRaidEvent(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload raidEventPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	difficulty, ok := getDifficulty(c, payload.Difficulty)
+	if !ok {
+		return
+	}
+	if payload.Date.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is required"})
+		return
+	}
+	seasonId, found := seasonIDForDate(payload.Date)
+	if !found {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no season covers that date"})
+		return
+	}
+	if !validateRaidEventBosses(c, seasonId, payload.Bosses) || !validateRaidAttendance(c, uint(teamId), payload.Attendance) {
+		return
+	}
+
+	event := models.RaidEvent{
+		TeamID:          uint(teamId),
+		SeasonID:        seasonId,
+		Date:            payload.Date,
+		Difficulty:      difficulty,
+		Note:            payload.Note,
+		CreatedByUserID: user.ID,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Bosses", "Attendance").Create(&event).Error; err != nil {
+			return err
+		}
+		if bosses := raidEventBossRows(event.ID, payload.Bosses); len(bosses) > 0 {
+			if err := tx.Create(&bosses).Error; err != nil {
+				return err
+			}
+		}
+		return saveRaidAttendance(tx, event.ID, payload.Attendance)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save raid event"})
+		return
+	}
+
+	database.DB.Preload("Bosses").Preload("Attendance").First(&event, event.ID)
+	c.JSON(http.StatusOK, event)
+}
+
+// UpdateRaidEvent edits an event's date, difficulty, note and bosses (the
+// boss list is replaced wholesale). A new date re-stamps the season.
+// Attendance is left alone — see UpsertRaidAttendance. Loot-council/admin/
+// owner only.
+func UpdateRaidEvent(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	eventId, err := strconv.ParseUint(c.Param("eventId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid raid event ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload raidEventPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	difficulty, ok := getDifficulty(c, payload.Difficulty)
+	if !ok {
+		return
+	}
+
+	var event models.RaidEvent
+	if err := database.DB.Where("id = ? AND team_id = ?", eventId, teamId).First(&event).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "raid event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query raid event"})
+		return
+	}
+
+	if !payload.Date.IsZero() && !payload.Date.Equal(event.Date) {
+		seasonId, found := seasonIDForDate(payload.Date)
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no season covers that date"})
+			return
+		}
+		event.Date = payload.Date
+		event.SeasonID = seasonId
+	}
+	if !validateRaidEventBosses(c, event.SeasonID, payload.Bosses) {
+		return
+	}
+	event.Difficulty = difficulty
+	event.Note = payload.Note
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Bosses", "Attendance").Save(&event).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("raid_event_id = ?", event.ID).Delete(&models.RaidEventBoss{}).Error; err != nil {
+			return err
+		}
+		if bosses := raidEventBossRows(event.ID, payload.Bosses); len(bosses) > 0 {
+			return tx.Create(&bosses).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save raid event"})
+		return
+	}
+
+	database.DB.Preload("Bosses").Preload("Attendance").First(&event, event.ID)
+	c.JSON(http.StatusOK, event)
+}
+
+func DeleteRaidEvent(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	eventId, err := strconv.ParseUint(c.Param("eventId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid raid event ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := database.DB.Where("id = ? AND team_id = ?", eventId, teamId).Delete(&models.RaidEvent{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete raid event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// UpsertRaidAttendance sets the status of each listed character for an
+// event; characters not listed are left as they were. Loot-council/admin/
+// owner only.
+func UpsertRaidAttendance(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	eventId, err := strconv.ParseUint(c.Param("eventId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid raid event ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload struct {
+		Attendance []raidAttendancePayload `json:"attendance"`
+	}
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	var event models.RaidEvent
+	if err := database.DB.Where("id = ? AND team_id = ?", eventId, teamId).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "raid event not found"})
+		return
+	}
+	if !validateRaidAttendance(c, uint(teamId), payload.Attendance) {
+		return
+	}
+
+	if err := saveRaidAttendance(database.DB, event.ID, payload.Attendance); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save attendance"})
+		return
+	}
+
+	attendance := []models.RaidAttendance{}
+	database.DB.Where("raid_event_id = ?", event.ID).Order("character_id").Find(&attendance)
+	c.JSON(http.StatusOK, gin.H{"attendance": attendance})
+}
+
+type playerAttendance struct {
+	PlayerID   uint   `json:"player_id"`
+	PlayerName string `json:"player_name"`
+	// Events is how many of the season's events the player has a record
+	// for; the status counts below add up to it.
+	Events  int `json:"events"`
+	Present int `json:"present"`
+	Late    int `json:"late"`
+	Bench   int `json:"bench"`
+	Absent  int `json:"absent"`
+	Excused int `json:"excused"`
+	// AttendancePercent is present+late+bench over every recorded event
+	// that wasn't excused — nil when there's nothing to count yet.
+	AttendancePercent *float64 `json:"attendance_percent"`
+}
+
+// GetSeasonAttendance returns every roster player's attendance over the
+// given (or current) season's raid events. A player's characters are
+// rolled up to one status per event (their best, see
+// attendanceStatusRanks); events with no record for any of their
+// characters — e.g. from before they joined — don't count either way.
+// Visible to every team member.
+func GetSeasonAttendance(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var seasonId uint
+	if q := c.Query("season_id"); q != "" {
+		parsed, err := strconv.ParseUint(q, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+			return
+		}
+		seasonId = uint(parsed)
+	} else {
+		id, ok := currentSeasonID()
+		if !ok {
+			c.JSON(http.StatusOK, gin.H{"players": []playerAttendance{}, "events": 0})
+			return
+		}
+		seasonId = id
+	}
+
+	var eventIds []uint
+	database.DB.Model(&models.RaidEvent{}).Where("team_id = ? AND season_id = ?", teamId, seasonId).Pluck("id", &eventIds)
+
+	characters := teamRosterCharacters(uint(teamId))
+	playerOf := make(map[uint]uint, len(characters))
+	for _, ch := range characters {
+		playerOf[ch.ID] = ch.PlayerID
+	}
+
+	type playerEvent struct{ playerId, eventId uint }
+	best := make(map[playerEvent]string)
+	if len(eventIds) > 0 {
+		var rows []models.RaidAttendance
+		database.DB.Where("raid_event_id IN ?", eventIds).Find(&rows)
+		for _, r := range rows {
+			playerId, onRoster := playerOf[r.CharacterID]
+			if !onRoster {
+				continue
+			}
+			key := playerEvent{playerId, r.RaidEventID}
+			if attendanceStatusRanks[r.Status] > attendanceStatusRanks[best[key]] {
+				best[key] = r.Status
+			}
+		}
+	}
+
+	var players []models.Player
+	database.DB.Where("team_id = ?", teamId).Order("name").Find(&players)
+	byPlayer := make(map[uint]*playerAttendance, len(players))
+	response := make([]playerAttendance, len(players))
+	for i, p := range players {
+		response[i] = playerAttendance{PlayerID: p.ID, PlayerName: p.Name}
+		byPlayer[p.ID] = &response[i]
+	}
+	for key, status := range best {
+		pa := byPlayer[key.playerId]
+		if pa == nil {
+			continue
+		}
+		pa.Events++
+		switch status {
+		case models.AttendancePresent:
+			pa.Present++
+		case models.AttendanceLate:
+			pa.Late++
+		case models.AttendanceBench:
+			pa.Bench++
+		case models.AttendanceAbsent:
+			pa.Absent++
+		case models.AttendanceExcused:
+			pa.Excused++
+		}
+	}
+	for i := range response {
+		counted := response[i].Events - response[i].Excused
+		if counted > 0 {
+			attended := response[i].Present + response[i].Late + response[i].Bench
+			percent := math.Round(float64(attended)/float64(counted)*1000) / 10
+			response[i].AttendancePercent = &percent
+		}
+	}
+	sort.SliceStable(response, func(i, j int) bool {
+		pi, pj := response[i].AttendancePercent, response[j].AttendancePercent
+		if (pi == nil) != (pj == nil) {
+			return pj == nil
+		}
+		return pi != nil && *pi > *pj
+	})
+
+	c.JSON(http.StatusOK, gin.H{"players": response, "events": len(eventIds)})
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"krankenprep/utilities"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// boeSlotOrder is the order slot breakdowns are returned in — head to toe,
+// then jewellery and weapons, same as the character sheet.
+var boeSlotOrder = []string{
+	models.BoeSlotHead,
+	models.BoeSlotNeck,
+	models.BoeSlotShoulder,
+	models.BoeSlotBack,
+	models.BoeSlotChest,
+	models.BoeSlotWrist,
+	models.BoeSlotHands,
+	models.BoeSlotWaist,
+	models.BoeSlotLegs,
+	models.BoeSlotFeet,
+	models.BoeSlotFinger,
+	models.BoeSlotTrinket,
+	models.BoeSlotWeapon,
+	models.BoeSlotOffHand,
+}
+
+// boeAggregate is one bucket of sales — a season, slot, seller, item or
+// week. Cuts use each sale's own split rule, same as GetBoeSales.
+type boeAggregate struct {
+	Count        int     `json:"count"`
+	SalePrice    float64 `json:"sale_price"`
+	GuildCut     float64 `json:"guild_cut"`
+	PlayerCut    float64 `json:"player_cut"`
+	AveragePrice float64 `json:"average_price"`
+}
+
+func (a *boeAggregate) add(sale models.BoeSale, rule models.BoeSplitRule) {
+	a.Count++
+	a.SalePrice += sale.SalePrice
+	a.GuildCut += sale.GuildCut(rule)
+	a.PlayerCut += sale.PlayerCut(rule)
+	a.AveragePrice = a.SalePrice / float64(a.Count)
+}
+
+type boeSlotAggregate struct {
+	Slot string `json:"slot"`
+	boeAggregate
+}
+
+type boeSellerAggregate struct {
+	PlayerID   *uint  `json:"player_id"`
+	PlayerName string `json:"player_name"`
+	boeAggregate
+}
+
+type boeItemAggregate struct {
+	WowItemID *uint  `json:"wow_item_id"`
+	ItemName  string `json:"item_name"`
+	ItemSlot  string `json:"item_slot"`
+	boeAggregate
+}
+
+type boeWeekAggregate struct {
+	WeekStart time.Time `json:"week_start"`
+	boeAggregate
+}
+
+type boeSeasonAnalytics struct {
+	SeasonID   uint                 `json:"season_id"`
+	SeasonName string               `json:"season_name"`
+	Totals     boeAggregate         `json:"totals"`
+	BySlot     []boeSlotAggregate   `json:"by_slot"`
+	BySeller   []boeSellerAggregate `json:"by_seller"`
+	ByItem     []boeItemAggregate   `json:"by_item"`
+	ByWeek     []boeWeekAggregate   `json:"by_week"`
+}
+
+// buildBoeSeasonAnalytics buckets one season's sales. Every BoeSlot* is
+// present in BySlot even with no sales, so charts keep a stable x-axis.
+// Sellers and items are grouped by their Player/Item link where the sale
+// has one, falling back to the free-text name, and ordered by gold brought
+// in; weeks are chronological.
+func buildBoeSeasonAnalytics(season models.Season, sales []models.BoeSale, rules models.BoeSplitRules) boeSeasonAnalytics {
+	analytics := boeSeasonAnalytics{SeasonID: season.Id, SeasonName: season.Name}
+
+	slots := make(map[string]*boeAggregate, len(boeSlotOrder))
+	for _, slot := range boeSlotOrder {
+		slots[slot] = &boeAggregate{}
+	}
+	sellers := make(map[string]*boeSellerAggregate)
+	items := make(map[string]*boeItemAggregate)
+	weeks := make(map[time.Time]*boeAggregate)
+
+	for _, sale := range sales {
+		rule := rules.At(sale.CreatedAt)
+		analytics.Totals.add(sale, rule)
+		if bucket, ok := slots[sale.ItemSlot]; ok {
+			bucket.add(sale, rule)
+		}
+
+		sellerKey := boePlayerKey(sale.PlayerName)
+		if sale.PlayerID != nil {
+			sellerKey = fmt.Sprintf("player:%d", *sale.PlayerID)
+		}
+		if sellers[sellerKey] == nil {
+			sellers[sellerKey] = &boeSellerAggregate{PlayerID: sale.PlayerID, PlayerName: strings.TrimSpace(sale.PlayerName)}
+		}
+		sellers[sellerKey].add(sale, rule)
+
+		itemKey := utilities.NameMatchKey(sale.ItemName)
+		if sale.WowItemID != nil {
+			itemKey = fmt.Sprintf("item:%d", *sale.WowItemID)
+		}
+		if items[itemKey] == nil {
+			items[itemKey] = &boeItemAggregate{WowItemID: sale.WowItemID, ItemName: strings.TrimSpace(sale.ItemName), ItemSlot: sale.ItemSlot}
+		}
+		items[itemKey].add(sale, rule)
+
+		week := startOfWeekUTC(sale.CreatedAt)
+		if weeks[week] == nil {
+			weeks[week] = &boeAggregate{}
+		}
+		weeks[week].add(sale, rule)
+	}
+
+	analytics.BySlot = make([]boeSlotAggregate, len(boeSlotOrder))
+	for i, slot := range boeSlotOrder {
+		analytics.BySlot[i] = boeSlotAggregate{Slot: slot, boeAggregate: *slots[slot]}
+	}
+
+	analytics.BySeller = make([]boeSellerAggregate, 0, len(sellers))
+	for _, s := range sellers {
+		analytics.BySeller = append(analytics.BySeller, *s)
+	}
+	sort.Slice(analytics.BySeller, func(i, j int) bool {
+		if analytics.BySeller[i].SalePrice != analytics.BySeller[j].SalePrice {
+			return analytics.BySeller[i].SalePrice > analytics.BySeller[j].SalePrice
+		}
+		return analytics.BySeller[i].PlayerName < analytics.BySeller[j].PlayerName
+	})
+
+	analytics.ByItem = make([]boeItemAggregate, 0, len(items))
+	for _, i := range items {
+		analytics.ByItem = append(analytics.ByItem, *i)
+	}
+	sort.Slice(analytics.ByItem, func(i, j int) bool {
+		if analytics.ByItem[i].SalePrice != analytics.ByItem[j].SalePrice {
+			return analytics.ByItem[i].SalePrice > analytics.ByItem[j].SalePrice
+		}
+		return analytics.ByItem[i].ItemName < analytics.ByItem[j].ItemName
+	})
+
+	analytics.ByWeek = make([]boeWeekAggregate, 0, len(weeks))
+	for week, a := range weeks {
+		analytics.ByWeek = append(analytics.ByWeek, boeWeekAggregate{WeekStart: week, boeAggregate: *a})
+	}
+	sort.Slice(analytics.ByWeek, func(i, j int) bool { return analytics.ByWeek[i].WeekStart.Before(analytics.ByWeek[j].WeekStart) })
+
+	return analytics
+}
+
+// GetBoeAnalytics breaks the team's BoE sales in the given (or current)
+// season down by slot, seller, item and week. Visible to every team
+// member.
+func GetBoeAnalytics(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var seasonId uint
+	if q := c.Query("season_id"); q != "" {
+		parsed, err := strconv.ParseUint(q, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+			return
+		}
+		seasonId = uint(parsed)
+	} else {
+		id, ok := currentSeasonID()
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+			return
+		}
+		seasonId = id
+	}
+
+	var season models.Season
+	if err := database.DB.First(&season, seasonId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "season not found"})
+		return
+	}
+
+	rules, err := teamBoeSplitRules(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE split rules"})
+		return
+	}
+
+	var sales []models.BoeSale
+	database.DB.Where("team_id = ? AND season_id = ?", teamId, seasonId).Order("created_at").Find(&sales)
+
+	c.JSON(http.StatusOK, gin.H{"analytics": buildBoeSeasonAnalytics(season, sales, rules)})
+}
+
+// boeSeasonComparison is one season's line in the comparison chart.
+// Cumulative is gold sold by the end of each week of the season (week 0
+// being the week StartDate falls in), so seasons of different lengths and
+// calendar dates overlay on the same x-axis.
+type boeSeasonComparison struct {
+	SeasonID   uint               `json:"season_id"`
+	SeasonName string             `json:"season_name"`
+	StartDate  time.Time          `json:"start_date"`
+	Totals     boeAggregate       `json:"totals"`
+	BySlot     []boeSlotAggregate `json:"by_slot"`
+	Cumulative []float64          `json:"cumulative"`
+}
+
+// GetBoeSeasonComparison compares the team's BoE sales across seasons:
+// totals, per-slot totals and average prices, and a cumulative gold-by-
+// week-of-season series per season. season_ids (comma-separated) picks the
+// seasons; by default it's every season the team has sold anything in.
+// Seasons are returned oldest first. Visible to every team member.
+func GetBoeSeasonComparison(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var seasonIds []uint
+	if q := c.Query("season_ids"); q != "" {
+		for _, part := range strings.Split(q, ",") {
+			parsed, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+				return
+			}
+			seasonIds = append(seasonIds, uint(parsed))
+		}
+	} else {
+		database.DB.Model(&models.BoeSale{}).Where("team_id = ?", teamId).Distinct().Pluck("season_id", &seasonIds)
+	}
+
+	comparisons := []boeSeasonComparison{}
+	if len(seasonIds) == 0 {
+		c.JSON(http.StatusOK, gin.H{"seasons": comparisons, "slots": boeSlotOrder})
+		return
+	}
+
+	var seasons []models.Season
+	database.DB.Where("id IN ?", seasonIds).Order("start_date, id").Find(&seasons)
+
+	rules, err := teamBoeSplitRules(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE split rules"})
+		return
+	}
+
+	var sales []models.BoeSale
+	database.DB.Where("team_id = ? AND season_id IN ?", teamId, seasonIds).Order("created_at").Find(&sales)
+	salesBySeason := make(map[uint][]models.BoeSale)
+	for _, s := range sales {
+		salesBySeason[s.SeasonID] = append(salesBySeason[s.SeasonID], s)
+	}
+
+	for _, season := range seasons {
+		seasonSales := salesBySeason[season.Id]
+		analytics := buildBoeSeasonAnalytics(season, seasonSales, rules)
+
+		firstWeek := startOfWeekUTC(season.StartDate)
+		if season.StartDate.IsZero() && len(seasonSales) > 0 {
+			firstWeek = startOfWeekUTC(seasonSales[0].CreatedAt)
+		}
+		cumulative := []float64{}
+		running := 0.0
+		for _, w := range analytics.ByWeek {
+			index := max(int(w.WeekStart.Sub(firstWeek).Hours()/(24*7)), 0)
+			for len(cumulative) <= index {
+				cumulative = append(cumulative, running)
+			}
+			running += w.SalePrice
+			cumulative[index] = running
+		}
+
+		comparisons = append(comparisons, boeSeasonComparison{
+			SeasonID:   season.Id,
+			SeasonName: season.Name,
+			StartDate:  season.StartDate,
+			Totals:     analytics.Totals,
+			BySlot:     analytics.BySlot,
+			Cumulative: cumulative,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"seasons": comparisons, "slots": boeSlotOrder})
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"fmt"
 	"krankenprep/database"
 	"krankenprep/models"
+	"krankenprep/utilities"
 	"net/http"
 	"strconv"
 
@@ -29,19 +31,35 @@ var validBoeSlots = map[string]bool{
 
 // boeSaleResponse embeds the model plus explicit GuildCut/PlayerCut fields —
 // the only place these numbers are ever computed, per BoeSale.GuildCut/
-// PlayerCut.
+// PlayerCut. PaidOut/PayoutStatus are likewise derived on read, from the
+// team's BoePayout rows (see attachBoePayouts).
 type boeSaleResponse struct {
 	models.BoeSale
-	GuildCut  float64 `json:"guild_cut"`
-	PlayerCut float64 `json:"player_cut"`
+	GuildCut     float64 `json:"guild_cut"`
+	PlayerCut    float64 `json:"player_cut"`
+	PaidOut      float64 `json:"paid_out"`
+	PayoutStatus string  `json:"payout_status"`
 }
 
-func toBoeSaleResponse(sale models.BoeSale) boeSaleResponse {
+// toBoeSaleResponse computes the sale's cuts under rules — the team's full
+// split-rule history, so the sale gets whichever rule was in effect when it
+// was made.
+func toBoeSaleResponse(sale models.BoeSale, rules models.BoeSplitRules) boeSaleResponse {
+	rule := rules.At(sale.CreatedAt)
 	return boeSaleResponse{
 		BoeSale:   sale,
-		GuildCut:  sale.GuildCut(),
-		PlayerCut: sale.PlayerCut(),
+		GuildCut:  sale.GuildCut(rule),
+		PlayerCut: sale.PlayerCut(rule),
+	}
+}
+
+// teamBoeSplitRules loads the team's split-rule history, oldest first.
+func teamBoeSplitRules(teamId uint) (models.BoeSplitRules, error) {
+	var rules models.BoeSplitRules
+	if err := database.DB.Where("team_id = ?", teamId).Order("effective_from, id").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("fetching BoE split rules: %w", err)
 	}
+	return rules, nil
 }
 
 type boeTotals struct {
@@ -102,15 +120,25 @@ func GetBoeSales(c *gin.Context) {
 		Order("created_at desc").
 		Find(&sales)
 
+	rules, err := teamBoeSplitRules(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE split rules"})
+		return
+	}
+
 	responses := make([]boeSaleResponse, len(sales))
 	totals := boeTotals{}
 	for i, sale := range sales {
-		resp := toBoeSaleResponse(sale)
+		resp := toBoeSaleResponse(sale, rules)
 		responses[i] = resp
 		totals.SalePrice += sale.SalePrice
 		totals.GuildCut += resp.GuildCut
 		totals.PlayerCut += resp.PlayerCut
 	}
+	if err := attachBoePayouts(uint(teamId), responses); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE payouts"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"sales": responses, "totals": totals})
 }
@@ -121,6 +149,55 @@ type boeSalePayload struct {
 	ItemSlot     string  `json:"item_slot"`
 	SalePrice    float64 `json:"sale_price"`
 	SoldToPlayer bool    `json:"sold_to_player"`
+	// PlayerID/WowItemID are optional links, usually picked from
+	// GetBoeSuggestions. Null leaves (or, on update, makes) the sale unlinked.
+	PlayerID  *uint `json:"player_id"`
+	WowItemID *uint `json:"wow_item_id"`
+}
+
+// applyBoeSaleLinks validates the payload's optional Player/Item links and
+// sets them on sale, replacing PlayerName/ItemName with the linked record's
+// canonical name (saveLinkedBoeSale carries payouts over to it). Writes a
+// 400 and returns false if a link doesn't resolve — a player from another
+// team counts as not found.
+func applyBoeSaleLinks(c *gin.Context, teamId uint, sale *models.BoeSale, payload boeSalePayload) bool {
+	sale.PlayerID = nil
+	if payload.PlayerID != nil {
+		var player models.Player
+		if err := database.DB.Where("id = ? AND team_id = ?", *payload.PlayerID, teamId).First(&player).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "player not found on this team"})
+			return false
+		}
+		sale.PlayerID = &player.ID
+		sale.PlayerName = player.Name
+	}
+
+	sale.WowItemID = nil
+	if payload.WowItemID != nil {
+		var item models.Item
+		if err := database.DB.Where("wow_item_id = ?", *payload.WowItemID).First(&item).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "item not found"})
+			return false
+		}
+		sale.WowItemID = &item.WowItemID
+		sale.ItemName = item.Name
+	}
+	return true
+}
+
+// saveLinkedBoeSale saves sale and, if it's linked to a player, renames
+// that player's records from previousName (see utilities.RenameBoePlayer).
+func saveLinkedBoeSale(sale *models.BoeSale, previousName string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(sale).Error; err != nil {
+			return err
+		}
+		if sale.PlayerID == nil {
+			return nil
+		}
+		_, err := utilities.RenameBoePlayer(tx, sale.TeamID, previousName, models.Player{ID: *sale.PlayerID, Name: sale.PlayerName})
+		return err
+	})
 }
 
 // CreateBoeSale stamps the record with whichever season is currently
@@ -170,12 +247,26 @@ func CreateBoeSale(c *gin.Context) {
 		SoldToPlayer:    payload.SoldToPlayer,
 		CreatedByUserID: user.ID,
 	}
-	if err := database.DB.Create(&sale).Error; err != nil {
+	if !applyBoeSaleLinks(c, uint(teamId), &sale, payload) {
+		return
+	}
+	if err := saveLinkedBoeSale(&sale, payload.PlayerName); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save BoE sale"})
 		return
 	}
 
-	c.JSON(http.StatusOK, toBoeSaleResponse(sale))
+	rules, err := teamBoeSplitRules(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE split rules"})
+		return
+	}
+	responses := []boeSaleResponse{toBoeSaleResponse(sale, rules)}
+	if err := attachBoePayouts(uint(teamId), responses); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE payouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, responses[0])
 }
 
 // UpdateBoeSale edits only the record's fields — SeasonID is intentionally
@@ -225,17 +316,34 @@ func UpdateBoeSale(c *gin.Context) {
 		return
 	}
 
+	// Payouts for this sale were recorded under its stored name, not
+	// whatever the payload renames it to.
+	previousName := sale.PlayerName
 	sale.PlayerName = payload.PlayerName
 	sale.ItemName = payload.ItemName
 	sale.ItemSlot = payload.ItemSlot
 	sale.SalePrice = payload.SalePrice
 	sale.SoldToPlayer = payload.SoldToPlayer
-	if err := database.DB.Save(&sale).Error; err != nil {
+	if !applyBoeSaleLinks(c, uint(teamId), &sale, payload) {
+		return
+	}
+	if err := saveLinkedBoeSale(&sale, previousName); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save BoE sale"})
 		return
 	}
 
-	c.JSON(http.StatusOK, toBoeSaleResponse(sale))
+	rules, err := teamBoeSplitRules(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE split rules"})
+		return
+	}
+	responses := []boeSaleResponse{toBoeSaleResponse(sale, rules)}
+	if err := attachBoePayouts(uint(teamId), responses); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE payouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, responses[0])
 }
 
 func DeleteBoeSale(c *gin.Context) {
@@ -268,3 +376,53 @@ func DeleteBoeSale(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
+
+// boeSuggestionLimit caps each list GetBoeSuggestions returns.
+const boeSuggestionLimit = 5
+
+// boeSuggestionMinScore keeps suggestions loose enough to catch a badly
+// mangled name while typing — unlike utilities.AutoLinkMinScore, a human
+// picks from these.
+const boeSuggestionMinScore = 0.4
+
+// GetBoeSuggestions offers roster players and catalog items that the
+// free-text player_name/item_name being entered may refer to, best match
+// first, for the sale form to offer as links. Either parameter can be
+// omitted. Loot-council/admin/owner only, same as entering sales.
+func GetBoeSuggestions(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	players := []utilities.PlayerSuggestion{}
+	if name := c.Query("player_name"); name != "" {
+		players, err = utilities.SuggestTeamPlayers(uint(teamId), name, boeSuggestionMinScore, boeSuggestionLimit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to suggest players"})
+			return
+		}
+	}
+
+	items := []utilities.ItemSuggestion{}
+	if name := c.Query("item_name"); name != "" {
+		items, err = utilities.SuggestItems(name, boeSuggestionMinScore, boeSuggestionLimit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to suggest items"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"players": players, "items": items})
+}
@@ -0,0 +1,531 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"krankenprep/utilities"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// boeCSVColumns maps a normalized header (see utilities.NameMatchKey) to the
+// field it fills, for columns the import payload doesn't map explicitly —
+// the common spellings of a treasurer's spreadsheet, plus the headers
+// ExportBoeSales writes.
+var boeCSVColumns = map[string]string{
+	"date":         "sold_at",
+	"saledate":     "sold_at",
+	"solddate":     "sold_at",
+	"soldat":       "sold_at",
+	"createdat":    "sold_at",
+	"player":       "player_name",
+	"playername":   "player_name",
+	"seller":       "player_name",
+	"item":         "item_name",
+	"itemname":     "item_name",
+	"slot":         "item_slot",
+	"itemslot":     "item_slot",
+	"price":        "sale_price",
+	"saleprice":    "sale_price",
+	"gold":         "sale_price",
+	"soldtoplayer": "sold_to_player",
+	"playersale":   "sold_to_player",
+	"id":           "sale_id",
+	"saleid":       "sale_id",
+}
+
+var requiredBoeCSVColumns = []string{"sold_at", "player_name", "item_name", "item_slot", "sale_price"}
+
+// boeCSVDateLayouts are the layouts tried for each date_format, in order.
+// Spreadsheets disagree on day/month order, so that's the caller's call
+// rather than a guess per row.
+var boeCSVDateLayouts = map[string][]string{
+	"iso": {"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "2006/01/02"},
+	"us":  {"1/2/2006", "01/02/2006", "1/2/06"},
+	"eu":  {"2/1/2006", "02/01/2006", "2.1.2006", "02.01.2006"},
+}
+
+// boeCSVTruthy are the sold_to_player cell values read as true; anything
+// else (including blank) is false.
+var boeCSVTruthy = map[string]bool{"true": true, "yes": true, "y": true, "1": true, "x": true}
+
+// boeSlotByKey resolves an item slot cell case- and punctuation-
+// insensitively ("off-hand", "OFFHAND") to its BoeSlot* constant.
+var boeSlotByKey = func() map[string]string {
+	slots := make(map[string]string, len(validBoeSlots))
+	for slot := range validBoeSlots {
+		slots[utilities.NameMatchKey(slot)] = slot
+	}
+	return slots
+}()
+
+type boeImportPayload struct {
+	CSV string `json:"csv"`
+	// Columns maps a field (sold_at, player_name, item_name, item_slot,
+	// sale_price, sold_to_player) to the CSV header that holds it.
+	// Unmapped fields fall back to boeCSVColumns.
+	Columns map[string]string `json:"columns"`
+	// DateFormat is "iso" (default), "us" (month first) or "eu" (day first).
+	DateFormat string `json:"date_format"`
+}
+
+type boeImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type boeImportRow struct {
+	Line     int       `json:"line"`
+	SoldAt   time.Time `json:"sold_at"`
+	SeasonID uint      `json:"season_id"`
+	// Duplicate means this row was imported by an earlier upload, or is a
+	// sale already in the app re-uploaded from an ExportBoeSales file, and
+	// will be skipped.
+	Duplicate    bool    `json:"duplicate"`
+	PlayerName   string  `json:"player_name"`
+	ItemName     string  `json:"item_name"`
+	ItemSlot     string  `json:"item_slot"`
+	SalePrice    float64 `json:"sale_price"`
+	SoldToPlayer bool    `json:"sold_to_player"`
+	importKey    string
+	// saleId is the id column of an ExportBoeSales file, if present.
+	saleId uint
+}
+
+type boeImportPreview struct {
+	Rows       []boeImportRow      `json:"rows"`
+	New        int                 `json:"new"`
+	Duplicates int                 `json:"duplicates"`
+	Errors     []boeImportRowError `json:"errors"`
+}
+
+// parseBoeCSVPrice reads a gold amount, tolerating thousands separators
+// and a trailing "g" ("12,500g").
+func parseBoeCSVPrice(value string) (float64, error) {
+	return utilities.ParseSpreadsheetNumber(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), "g"))
+}
+
+// boeImportKey identifies a source row by its normalized content. occurrence
+// counts identical rows earlier in the same file, so two genuinely
+// identical sales on one day both import — and both are recognized on the
+// next upload.
+func boeImportKey(row boeImportRow, occurrence int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%.4f|%t|%d",
+		row.SoldAt.UTC().Format("2006-01-02"),
+		boePlayerKey(row.PlayerName),
+		utilities.NameMatchKey(row.ItemName),
+		row.ItemSlot,
+		row.SalePrice,
+		row.SoldToPlayer,
+		occurrence,
+	)))
+	return hex.EncodeToString(sum[:])
+}
+
+// seasonForDate returns the season whose StartDate..EndDate covers t. An
+// unset EndDate means the season is still running.
+func seasonForDate(seasons []models.Season, t time.Time) (models.Season, bool) {
+	for _, s := range seasons {
+		if s.StartDate.IsZero() || t.Before(s.StartDate) {
+			continue
+		}
+		if s.EndDate.IsZero() || t.Before(s.EndDate) {
+			return s, true
+		}
+	}
+	return models.Season{}, false
+}
+
+// exportedBoeSales returns the sale ids, among rows' id cells, that are
+// already this team's sales — rows of an ExportBoeSales file being
+// uploaded back. Sales entered in the app have no ImportKey, so without
+// this every one of them would import again as a new sale. A treasurer's
+// own sheet may have an unrelated ID column, so an id only counts if the
+// sale's date and price match the row too.
+func exportedBoeSales(teamId uint, rows []boeImportRow) map[uint]bool {
+	exported := make(map[uint]bool)
+	ids := []uint{}
+	for _, row := range rows {
+		if row.saleId != 0 {
+			ids = append(ids, row.saleId)
+		}
+	}
+	if len(ids) == 0 {
+		return exported
+	}
+	var sales []models.BoeSale
+	database.DB.Where("team_id = ? AND id IN ?", teamId, ids).Find(&sales)
+	byId := make(map[uint]models.BoeSale, len(sales))
+	for _, sale := range sales {
+		byId[sale.ID] = sale
+	}
+	for _, row := range rows {
+		sale, found := byId[row.saleId]
+		if found && sale.SalePrice == row.SalePrice &&
+			sale.CreatedAt.UTC().Format("2006-01-02") == row.SoldAt.UTC().Format("2006-01-02") {
+			exported[row.saleId] = true
+		}
+	}
+	return exported
+}
+
+// buildBoeImport does everything preview and import share: permission
+// check, CSV parsing, column mapping, per-row validation and duplicate
+// detection. Writes its own error response and returns ok=false when the
+// upload as a whole is unusable; per-row problems are collected in
+// preview.Errors instead.
+func buildBoeImport(c *gin.Context) (preview boeImportPreview, teamId uint, user *models.User, ok bool) {
+	user, userOk := getRequestingUser(c)
+	if !userOk {
+		return preview, 0, nil, false
+	}
+
+	parsedTeamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return preview, 0, nil, false
+	}
+	teamId = uint(parsedTeamId)
+
+	if !isLootCouncilOrAdmin(teamId, user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return preview, 0, nil, false
+	}
+
+	var payload boeImportPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return preview, 0, nil, false
+	}
+	if payload.DateFormat == "" {
+		payload.DateFormat = "iso"
+	}
+	layouts, validFormat := boeCSVDateLayouts[payload.DateFormat]
+	if !validFormat {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_format must be iso, us or eu"})
+		return preview, 0, nil, false
+	}
+
+	reader := csv.NewReader(strings.NewReader(payload.CSV))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid CSV: %v", err)})
+		return preview, 0, nil, false
+	}
+	if len(records) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV must have a header row and at least one data row"})
+		return preview, 0, nil, false
+	}
+
+	headers := make(map[string]int, len(records[0]))
+	for i, header := range records[0] {
+		if _, seen := headers[utilities.NameMatchKey(header)]; !seen {
+			headers[utilities.NameMatchKey(header)] = i
+		}
+	}
+	columns := make(map[string]int)
+	for field, header := range payload.Columns {
+		i, found := headers[utilities.NameMatchKey(header)]
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("column %q (mapped to %s) not found in CSV header", header, field)})
+			return preview, 0, nil, false
+		}
+		columns[field] = i
+	}
+	for header, i := range headers {
+		if field, known := boeCSVColumns[header]; known {
+			if _, mapped := columns[field]; !mapped {
+				columns[field] = i
+			}
+		}
+	}
+	for _, field := range requiredBoeCSVColumns {
+		if _, found := columns[field]; !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("CSV is missing a %s column — map it in columns", field)})
+			return preview, 0, nil, false
+		}
+	}
+
+	var seasons []models.Season
+	database.DB.Order("start_date").Find(&seasons)
+
+	preview.Rows = []boeImportRow{}
+	preview.Errors = []boeImportRowError{}
+	occurrences := make(map[string]int)
+	for i, record := range records[1:] {
+		line := i + 2
+		cell := func(field string) string {
+			col, mapped := columns[field]
+			if !mapped || col >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[col])
+		}
+		rowError := func(format string, args ...interface{}) {
+			preview.Errors = append(preview.Errors, boeImportRowError{Line: line, Error: fmt.Sprintf(format, args...)})
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		row := boeImportRow{
+			Line:         line,
+			PlayerName:   cell("player_name"),
+			ItemName:     cell("item_name"),
+			SoldToPlayer: boeCSVTruthy[strings.ToLower(cell("sold_to_player"))],
+		}
+
+		parsedDate := false
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, cell("sold_at")); err == nil {
+				row.SoldAt = t
+				parsedDate = true
+				break
+			}
+		}
+		if !parsedDate {
+			rowError("invalid %s date %q", payload.DateFormat, cell("sold_at"))
+			continue
+		}
+		season, found := seasonForDate(seasons, row.SoldAt)
+		if !found {
+			rowError("no season covers %s", row.SoldAt.Format("2006-01-02"))
+			continue
+		}
+		row.SeasonID = season.Id
+
+		if row.PlayerName == "" || row.ItemName == "" {
+			rowError("player name and item name are required")
+			continue
+		}
+		slot, validSlot := boeSlotByKey[utilities.NameMatchKey(cell("item_slot"))]
+		if !validSlot {
+			rowError("unknown item slot %q", cell("item_slot"))
+			continue
+		}
+		row.ItemSlot = slot
+		price, err := parseBoeCSVPrice(cell("sale_price"))
+		if err != nil || price < 0 {
+			rowError("invalid sale price %q", cell("sale_price"))
+			continue
+		}
+		row.SalePrice = price
+		if id, err := strconv.ParseUint(cell("sale_id"), 10, 32); err == nil {
+			row.saleId = uint(id)
+		}
+
+		base := boeImportKey(row, 0)
+		row.importKey = boeImportKey(row, occurrences[base])
+		occurrences[base]++
+		preview.Rows = append(preview.Rows, row)
+	}
+
+	if len(preview.Rows) > 0 {
+		keys := make([]string, len(preview.Rows))
+		for i, row := range preview.Rows {
+			keys[i] = row.importKey
+		}
+		var existing []string
+		database.DB.Model(&models.BoeSale{}).Where("team_id = ? AND import_key IN ?", teamId, keys).Pluck("import_key", &existing)
+		imported := make(map[string]bool, len(existing))
+		for _, key := range existing {
+			imported[key] = true
+		}
+		exported := exportedBoeSales(teamId, preview.Rows)
+		for i := range preview.Rows {
+			row := preview.Rows[i]
+			preview.Rows[i].Duplicate = imported[row.importKey] || exported[row.saleId]
+			if preview.Rows[i].Duplicate {
+				preview.Duplicates++
+			} else {
+				preview.New++
+			}
+		}
+	}
+
+	return preview, teamId, user, true
+}
+
+// PreviewBoeImport reports what ImportBoeSales would do with the same
+// payload without writing anything.
+func PreviewBoeImport(c *gin.Context) {
+	preview, _, _, ok := buildBoeImport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// ImportBoeSales imports historic BoE sales from a CSV spreadsheet. Unlike
+// the tier-sim import it's not all-or-nothing: valid rows are imported and
+// bad ones reported by line, and since every imported row is keyed by its
+// content (see boeImportKey), the treasurer can fix the bad rows and upload
+// the whole sheet again without duplicating the rest. Each sale is stamped
+// with the season its date falls in, and dated to it, so split rules and
+// weekly analytics treat it as of when it was actually sold.
+// Loot-council/admin/owner only, same as entering sales.
+func ImportBoeSales(c *gin.Context) {
+	preview, teamId, user, ok := buildBoeImport(c)
+	if !ok {
+		return
+	}
+
+	sales := make([]models.BoeSale, 0, preview.New)
+	for _, row := range preview.Rows {
+		if row.Duplicate {
+			continue
+		}
+		key := row.importKey
+		sales = append(sales, models.BoeSale{
+			TeamID:          teamId,
+			SeasonID:        row.SeasonID,
+			PlayerName:      row.PlayerName,
+			ItemName:        row.ItemName,
+			ItemSlot:        row.ItemSlot,
+			SalePrice:       row.SalePrice,
+			SoldToPlayer:    row.SoldToPlayer,
+			CreatedByUserID: user.ID,
+			CreatedAt:       row.SoldAt,
+			ImportKey:       &key,
+		})
+	}
+
+	if len(sales) > 0 {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&sales).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import BoE sales"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"imported":   len(sales),
+		"duplicates": preview.Duplicates,
+		"errors":     preview.Errors,
+	})
+}
+
+var boeSaleCSVHeader = []string{
+	"id", "sold_at", "season_id", "season_name", "player_name", "player_id",
+	"item_name", "wow_item_id", "item_slot", "sale_price", "sold_to_player",
+	"guild_cut", "player_cut", "paid_out", "payout_status",
+}
+
+func optionalUintCSV(v *uint) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*v), 10)
+}
+
+func boeSaleCSVRecord(sale boeSaleResponse) []string {
+	return []string{
+		strconv.FormatUint(uint64(sale.ID), 10),
+		sale.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatUint(uint64(sale.SeasonID), 10),
+		sale.Season.Name,
+		sale.PlayerName,
+		optionalUintCSV(sale.PlayerID),
+		sale.ItemName,
+		optionalUintCSV(sale.WowItemID),
+		sale.ItemSlot,
+		strconv.FormatFloat(sale.SalePrice, 'f', -1, 64),
+		strconv.FormatBool(sale.SoldToPlayer),
+		strconv.FormatFloat(sale.GuildCut, 'f', 2, 64),
+		strconv.FormatFloat(sale.PlayerCut, 'f', 2, 64),
+		strconv.FormatFloat(sale.PaidOut, 'f', 2, 64),
+		sale.PayoutStatus,
+	}
+}
+
+// ExportBoeSales downloads the same sales GetBoeSales returns — the given
+// (or current) season — as CSV, oldest first, with the computed cuts and
+// payout status. The id/date/player/item/slot/price/sold_to_player columns
+// use headers ImportBoeSales recognizes, and the id lets it skip sales
+// already in the app if the file is uploaded back. Visible to every team
+// member, same as GetBoeSales.
+func ExportBoeSales(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var seasonId uint
+	if q := c.Query("season_id"); q != "" {
+		parsed, err := strconv.ParseUint(q, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+			return
+		}
+		seasonId = uint(parsed)
+	} else {
+		id, ok := currentSeasonID()
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+			return
+		}
+		seasonId = id
+	}
+
+	var sales []models.BoeSale
+	database.DB.Preload("Season").
+		Where("team_id = ? AND season_id = ?", teamId, seasonId).
+		Order("created_at, id").
+		Find(&sales)
+
+	rules, err := teamBoeSplitRules(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE split rules"})
+		return
+	}
+	responses := make([]boeSaleResponse, len(sales))
+	for i, sale := range sales {
+		responses[i] = toBoeSaleResponse(sale, rules)
+	}
+	if err := attachBoePayouts(uint(teamId), responses); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE payouts"})
+		return
+	}
+
+	filename := fmt.Sprintf("boe-sales-team-%d-season-%d.csv", teamId, seasonId)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	csvWriter := csv.NewWriter(c.Writer)
+	csvWriter.Write(boeSaleCSVHeader)
+	for _, sale := range responses {
+		csvWriter.Write(boeSaleCSVRecord(sale))
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		// Headers are already sent, so all that's left is to log it.
+		log.Printf("Error writing BoE sales export: %v", err)
+	}
+}
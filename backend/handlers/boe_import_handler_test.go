@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"krankenprep/models"
+	"testing"
+	"time"
+)
+
+func TestSeasonForDate(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+	seasons := []models.Season{
+		{Id: 1, StartDate: day(time.January, 10), EndDate: day(time.March, 1)},
+		// A season with no start date yet can't claim any sales.
+		{Id: 2, EndDate: day(time.April, 1)},
+		{Id: 3, StartDate: day(time.March, 15), EndDate: day(time.June, 1)},
+		{Id: 4, StartDate: day(time.July, 1)},
+	}
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		wantID uint
+		wantOK bool
+	}{
+		{"before the first season", day(time.January, 1), 0, false},
+		{"on a start date", day(time.January, 10), 1, true},
+		{"inside a season", day(time.February, 14), 1, true},
+		{"end date is exclusive", day(time.March, 1), 0, false},
+		{"gap between seasons", day(time.March, 10), 0, false},
+		{"seasons without a start are skipped", day(time.March, 20), 3, true},
+		{"open-ended season", day(time.December, 31), 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			season, ok := seasonForDate(seasons, tt.at)
+			if ok != tt.wantOK || season.Id != tt.wantID {
+				t.Fatalf("seasonForDate(%v) = (season %d, %v), want (season %d, %v)", tt.at, season.Id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
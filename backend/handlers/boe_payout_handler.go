@@ -0,0 +1,442 @@
+package handlers
+
+import (
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"krankenprep/utilities"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// boePayoutEpsilon absorbs float rounding when comparing a sale's paid
+// amount to its player cut — gold amounts are entered to at most copper
+// precision, so anything under a hundredth of a copper is noise.
+const boePayoutEpsilon = 1e-6
+
+// boePlayerKey is how sales and payouts are grouped per player (see
+// utilities.BoePlayerKey).
+func boePlayerKey(name string) string {
+	return utilities.BoePlayerKey(name)
+}
+
+// boePayoutState is a team's complete BoE payout picture: every sale and
+// payout ever recorded — payouts are allocated across seasons, so a
+// per-season subset isn't enough to know what's been paid — plus each
+// sale's player cut under the split rule it was made under, and the result
+// of allocating payouts against those cuts.
+type boePayoutState struct {
+	Sales      []models.BoeSale
+	Payouts    []models.BoePayout
+	Rules      models.BoeSplitRules
+	PlayerCuts map[uint]float64
+	// Paid is the amount paid per sale ID.
+	Paid map[uint]float64
+	// Credit is, per boePlayerKey, gold paid beyond every cut they're owed.
+	Credit map[string]float64
+}
+
+// loadBoePayoutState loads the team's sales, payouts and split rules and
+// allocates the payouts (see allocateBoePayouts).
+func loadBoePayoutState(teamId uint) (boePayoutState, error) {
+	var sales []models.BoeSale
+	if err := database.DB.Where("team_id = ?", teamId).Order("created_at, id").Find(&sales).Error; err != nil {
+		return boePayoutState{}, fmt.Errorf("fetching BoE sales: %w", err)
+	}
+	var payouts []models.BoePayout
+	if err := database.DB.Where("team_id = ?", teamId).Order("paid_at, id").Find(&payouts).Error; err != nil {
+		return boePayoutState{}, fmt.Errorf("fetching BoE payouts: %w", err)
+	}
+	rules, err := teamBoeSplitRules(teamId)
+	if err != nil {
+		return boePayoutState{}, err
+	}
+	return allocateBoePayouts(sales, payouts, rules), nil
+}
+
+// allocateBoePayouts works out each sale's player cut under the rule it was
+// made under and allocates the payouts against those cuts: payouts tied to
+// a sale go to that sale first; lump sums, and anything over a sale's cut,
+// are applied to the same player's oldest outstanding sales. sales must be
+// oldest first and payouts in the order they were paid.
+func allocateBoePayouts(sales []models.BoeSale, payouts []models.BoePayout, rules models.BoeSplitRules) boePayoutState {
+	state := boePayoutState{Sales: sales, Payouts: payouts, Rules: rules}
+
+	state.PlayerCuts = make(map[uint]float64, len(state.Sales))
+	for _, s := range state.Sales {
+		state.PlayerCuts[s.ID] = s.PlayerCut(rules.At(s.CreatedAt))
+	}
+
+	state.Paid = make(map[uint]float64, len(state.Sales))
+	state.Credit = make(map[string]float64)
+	for _, p := range state.Payouts {
+		key := boePlayerKey(p.PlayerName)
+		if p.BoeSaleID != nil {
+			if cut, ok := state.PlayerCuts[*p.BoeSaleID]; ok {
+				applied := math.Min(p.Amount, cut-state.Paid[*p.BoeSaleID])
+				if applied > 0 {
+					state.Paid[*p.BoeSaleID] += applied
+					state.Credit[key] += p.Amount - applied
+					continue
+				}
+			}
+		}
+		state.Credit[key] += p.Amount
+	}
+
+	for _, s := range state.Sales {
+		key := boePlayerKey(s.PlayerName)
+		remaining := state.Remaining(s.ID)
+		if remaining <= boePayoutEpsilon || state.Credit[key] <= boePayoutEpsilon {
+			continue
+		}
+		applied := math.Min(remaining, state.Credit[key])
+		state.Paid[s.ID] += applied
+		state.Credit[key] -= applied
+	}
+	return state
+}
+
+// Remaining is how much of a sale's player cut is still unpaid.
+func (state boePayoutState) Remaining(saleId uint) float64 {
+	return state.PlayerCuts[saleId] - state.Paid[saleId]
+}
+
+// Status classifies a sale from its paid amount.
+func (state boePayoutState) Status(saleId uint) string {
+	switch {
+	case state.Remaining(saleId) <= boePayoutEpsilon:
+		return models.BoePayoutPaid
+	case state.Paid[saleId] > boePayoutEpsilon:
+		return models.BoePayoutPartial
+	default:
+		return models.BoePayoutUnpaid
+	}
+}
+
+// attachBoePayouts fills PaidOut/PayoutStatus on sale responses.
+func attachBoePayouts(teamId uint, responses []boeSaleResponse) error {
+	state, err := loadBoePayoutState(teamId)
+	if err != nil {
+		return err
+	}
+	for i := range responses {
+		responses[i].PaidOut = state.Paid[responses[i].ID]
+		responses[i].PayoutStatus = state.Status(responses[i].ID)
+	}
+	return nil
+}
+
+// GetBoePayouts lists the team's payouts, newest first, optionally for one
+// player. Visible to every team member, same as the sales themselves.
+func GetBoePayouts(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	query := database.DB.Where("team_id = ?", teamId)
+	if name := c.Query("player_name"); name != "" {
+		query = query.Where("LOWER(TRIM(player_name)) = ?", boePlayerKey(name))
+	}
+	payouts := []models.BoePayout{}
+	query.Order("paid_at desc, id desc").Find(&payouts)
+
+	c.JSON(http.StatusOK, gin.H{"payouts": payouts})
+}
+
+type boePayoutPayload struct {
+	// BoeSaleID pays out against one sale; PlayerName is then taken from
+	// the sale. Without it, PlayerName is required and the payout is a
+	// lump sum.
+	BoeSaleID  *uint      `json:"boe_sale_id"`
+	PlayerName string     `json:"player_name"`
+	Amount     float64    `json:"amount"`
+	Note       string     `json:"note"`
+	PaidAt     *time.Time `json:"paid_at"`
+}
+
+// CreateBoePayout records gold paid to a player. Overpaying a sale isn't
+// rejected — the excess becomes credit against the player's other sales.
+// Loot-council/admin/owner only, same as editing sales.
+func CreateBoePayout(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload boePayoutPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	if payload.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be positive"})
+		return
+	}
+
+	payout := models.BoePayout{
+		TeamID:       uint(teamId),
+		BoeSaleID:    payload.BoeSaleID,
+		PlayerName:   strings.TrimSpace(payload.PlayerName),
+		Amount:       payload.Amount,
+		Note:         payload.Note,
+		PaidByUserID: user.ID,
+		PaidAt:       time.Now(),
+	}
+	if payload.PaidAt != nil {
+		payout.PaidAt = *payload.PaidAt
+	}
+	if payload.BoeSaleID != nil {
+		var sale models.BoeSale
+		if err := database.DB.Where("id = ? AND team_id = ?", *payload.BoeSaleID, teamId).First(&sale).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "BoE sale not found"})
+			return
+		}
+		payout.PlayerName = sale.PlayerName
+	}
+	if payout.PlayerName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "player_name is required for a payout not tied to a sale"})
+		return
+	}
+
+	if err := database.DB.Create(&payout).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save payout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, payout)
+}
+
+// DeleteBoePayout removes a payout recorded in error. Loot-council/admin/
+// owner only.
+func DeleteBoePayout(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	payoutId, err := strconv.ParseUint(c.Param("payoutId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payout ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := database.DB.Where("id = ? AND team_id = ?", payoutId, teamId).Delete(&models.BoePayout{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete payout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+type boeLedgerEntry struct {
+	Date time.Time `json:"date"`
+	// Kind is "sale" (a player cut owed) or "payout".
+	Kind      string  `json:"kind"`
+	BoeSaleID *uint   `json:"boe_sale_id"`
+	PayoutID  *uint   `json:"payout_id"`
+	SeasonID  *uint   `json:"season_id"`
+	ItemName  string  `json:"item_name,omitempty"`
+	Note      string  `json:"note,omitempty"`
+	Amount    float64 `json:"amount"`
+	Balance   float64 `json:"balance"`
+}
+
+type boePlayerBalance struct {
+	PlayerName string  `json:"player_name"`
+	Owed       float64 `json:"owed"`
+	Paid       float64 `json:"paid"`
+	// Balance is Owed-Paid: positive means the guild still owes the player,
+	// negative that they've been paid ahead.
+	Balance float64          `json:"balance"`
+	Ledger  []boeLedgerEntry `json:"ledger,omitempty"`
+}
+
+// GetBoeBalances returns every player's running BoE balance across all
+// seasons — player cuts owed vs payouts made — largest outstanding first.
+// With player_name, only that player is returned, along with their full
+// chronological ledger and the balance after each entry. Visible to every
+// team member.
+func GetBoeBalances(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	state, err := loadBoePayoutState(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute balances"})
+		return
+	}
+
+	filter := boePlayerKey(c.Query("player_name"))
+	balances := make(map[string]*boePlayerBalance)
+	balanceFor := func(name string) *boePlayerBalance {
+		key := boePlayerKey(name)
+		if balances[key] == nil {
+			balances[key] = &boePlayerBalance{PlayerName: strings.TrimSpace(name)}
+		}
+		return balances[key]
+	}
+
+	var ledger []boeLedgerEntry
+	for _, s := range state.Sales {
+		if filter != "" && boePlayerKey(s.PlayerName) != filter {
+			continue
+		}
+		balanceFor(s.PlayerName).Owed += state.PlayerCuts[s.ID]
+		if filter != "" {
+			saleId, seasonId := s.ID, s.SeasonID
+			ledger = append(ledger, boeLedgerEntry{Date: s.CreatedAt, Kind: "sale", BoeSaleID: &saleId, SeasonID: &seasonId, ItemName: s.ItemName, Amount: state.PlayerCuts[s.ID]})
+		}
+	}
+	for _, p := range state.Payouts {
+		if filter != "" && boePlayerKey(p.PlayerName) != filter {
+			continue
+		}
+		balanceFor(p.PlayerName).Paid += p.Amount
+		if filter != "" {
+			payoutId := p.ID
+			ledger = append(ledger, boeLedgerEntry{Date: p.PaidAt, Kind: "payout", BoeSaleID: p.BoeSaleID, PayoutID: &payoutId, Note: p.Note, Amount: -p.Amount})
+		}
+	}
+
+	response := []boePlayerBalance{}
+	for _, b := range balances {
+		b.Balance = b.Owed - b.Paid
+		response = append(response, *b)
+	}
+	sort.SliceStable(response, func(i, j int) bool {
+		if response[i].Balance != response[j].Balance {
+			return response[i].Balance > response[j].Balance
+		}
+		return response[i].PlayerName < response[j].PlayerName
+	})
+
+	if filter != "" && len(response) == 1 {
+		sort.SliceStable(ledger, func(i, j int) bool { return ledger[i].Date.Before(ledger[j].Date) })
+		running := 0.0
+		for i := range ledger {
+			running += ledger[i].Amount
+			ledger[i].Balance = running
+		}
+		response[0].Ledger = ledger
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balances": response})
+}
+
+type boeOutstandingSale struct {
+	boeSaleResponse
+	Remaining float64 `json:"remaining"`
+}
+
+// GetBoeOutstanding lists every sale, across all seasons, whose player cut
+// hasn't been fully paid out, oldest first, with the amount still owed and
+// a per-player total. Loot-council/admin/owner only — it's the payout
+// officer's worklist.
+func GetBoeOutstanding(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	state, err := loadBoePayoutState(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute outstanding payouts"})
+		return
+	}
+
+	outstanding := []boeOutstandingSale{}
+	byPlayer := make(map[string]float64)
+	playerNames := make(map[string]string)
+	total := 0.0
+	for _, s := range state.Sales {
+		remaining := state.Remaining(s.ID)
+		if remaining <= boePayoutEpsilon {
+			continue
+		}
+		resp := toBoeSaleResponse(s, state.Rules)
+		resp.PaidOut = state.Paid[s.ID]
+		resp.PayoutStatus = state.Status(s.ID)
+		outstanding = append(outstanding, boeOutstandingSale{boeSaleResponse: resp, Remaining: remaining})
+		key := boePlayerKey(s.PlayerName)
+		if _, seen := playerNames[key]; !seen {
+			playerNames[key] = strings.TrimSpace(s.PlayerName)
+		}
+		byPlayer[key] += remaining
+		total += remaining
+	}
+
+	players := []gin.H{}
+	for key, amount := range byPlayer {
+		players = append(players, gin.H{"player_name": playerNames[key], "remaining": amount})
+	}
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i]["remaining"].(float64) > players[j]["remaining"].(float64)
+	})
+
+	c.JSON(http.StatusOK, gin.H{"outstanding": outstanding, "by_player": players, "total": total})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"krankenprep/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAllocateBoePayouts(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 20, 0, 0, 0, time.UTC) }
+	saleId := func(id uint) *uint { return &id }
+
+	// Under the default 50/50 split each 1000g sale owes the seller 500g.
+	sales := []models.BoeSale{
+		{ID: 1, PlayerName: "Alice", SalePrice: 1000, CreatedAt: day(1)},
+		{ID: 2, PlayerName: "Alice", SalePrice: 1000, CreatedAt: day(2)},
+		{ID: 3, PlayerName: "Bob", SalePrice: 1000, CreatedAt: day(3)},
+		{ID: 4, PlayerName: "Bob", SalePrice: 1000, SoldToPlayer: true, CreatedAt: day(4)},
+	}
+	flatFeeFromDay10 := models.BoeSplitRules{{Kind: models.BoeSplitFlatFee, FlatFee: 100, EffectiveFrom: day(10)}}
+
+	tests := []struct {
+		name       string
+		sales      []models.BoeSale
+		payouts    []models.BoePayout
+		rules      models.BoeSplitRules
+		wantPaid   map[uint]float64
+		wantStatus map[uint]string
+		wantCredit map[string]float64
+	}{
+		{
+			name:       "nothing paid",
+			sales:      sales,
+			wantPaid:   map[uint]float64{},
+			wantStatus: map[uint]string{1: models.BoePayoutUnpaid, 3: models.BoePayoutUnpaid},
+		},
+		{
+			name:       "payout for one sale",
+			sales:      sales,
+			payouts:    []models.BoePayout{{PlayerName: "Alice", BoeSaleID: saleId(2), Amount: 500}},
+			wantPaid:   map[uint]float64{2: 500},
+			wantStatus: map[uint]string{1: models.BoePayoutUnpaid, 2: models.BoePayoutPaid},
+		},
+		{
+			name:       "partial payout",
+			sales:      sales,
+			payouts:    []models.BoePayout{{PlayerName: "Bob", BoeSaleID: saleId(3), Amount: 200}},
+			wantPaid:   map[uint]float64{3: 200},
+			wantStatus: map[uint]string{3: models.BoePayoutPartial},
+		},
+		{
+			name:       "overpaying a sale credits the player's other sales",
+			sales:      sales,
+			payouts:    []models.BoePayout{{PlayerName: "Alice", BoeSaleID: saleId(2), Amount: 700}},
+			wantPaid:   map[uint]float64{1: 200, 2: 500},
+			wantStatus: map[uint]string{1: models.BoePayoutPartial, 2: models.BoePayoutPaid},
+		},
+		{
+			name:       "lump sum pays oldest first, matching names loosely",
+			sales:      sales,
+			payouts:    []models.BoePayout{{PlayerName: "  alice ", Amount: 600}},
+			wantPaid:   map[uint]float64{1: 500, 2: 100},
+			wantStatus: map[uint]string{1: models.BoePayoutPaid, 2: models.BoePayoutPartial, 3: models.BoePayoutUnpaid},
+		},
+		{
+			name:  "payout for an already paid sale becomes credit",
+			sales: sales,
+			payouts: []models.BoePayout{
+				{PlayerName: "Bob", BoeSaleID: saleId(3), Amount: 500},
+				{PlayerName: "Bob", BoeSaleID: saleId(3), Amount: 300},
+			},
+			wantPaid:   map[uint]float64{3: 500, 4: 300},
+			wantStatus: map[uint]string{3: models.BoePayoutPaid, 4: models.BoePayoutPartial},
+		},
+		{
+			name:       "credit beyond every sale is kept",
+			sales:      sales,
+			payouts:    []models.BoePayout{{PlayerName: "Bob", Amount: 1250}},
+			wantPaid:   map[uint]float64{3: 500, 4: 500},
+			wantStatus: map[uint]string{3: models.BoePayoutPaid, 4: models.BoePayoutPaid},
+			wantCredit: map[string]float64{"bob": 250},
+		},
+		{
+			name:       "payout for an unknown sale is a lump sum",
+			sales:      sales,
+			payouts:    []models.BoePayout{{PlayerName: "Alice", BoeSaleID: saleId(99), Amount: 500}},
+			wantPaid:   map[uint]float64{1: 500},
+			wantStatus: map[uint]string{1: models.BoePayoutPaid, 2: models.BoePayoutUnpaid},
+		},
+		{
+			name: "each sale is cut by the rule in effect when it was made",
+			sales: []models.BoeSale{
+				{ID: 1, PlayerName: "Alice", SalePrice: 1000, CreatedAt: day(9)},
+				{ID: 2, PlayerName: "Alice", SalePrice: 1000, CreatedAt: day(11)},
+			},
+			payouts:    []models.BoePayout{{PlayerName: "Alice", Amount: 1300}},
+			rules:      flatFeeFromDay10,
+			wantPaid:   map[uint]float64{1: 500, 2: 800},
+			wantStatus: map[uint]string{1: models.BoePayoutPaid, 2: models.BoePayoutPartial},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := allocateBoePayouts(tt.sales, tt.payouts, tt.rules)
+			for _, sale := range tt.sales {
+				if got, want := state.Paid[sale.ID], tt.wantPaid[sale.ID]; math.Abs(got-want) > boePayoutEpsilon {
+					t.Errorf("sale %d paid = %v, want %v", sale.ID, got, want)
+				}
+			}
+			for id, want := range tt.wantStatus {
+				if got := state.Status(id); got != want {
+					t.Errorf("sale %d status = %q, want %q", id, got, want)
+				}
+			}
+			for key, got := range state.Credit {
+				if want := tt.wantCredit[key]; math.Abs(got-want) > boePayoutEpsilon {
+					t.Errorf("credit[%q] = %v, want %v", key, got, want)
+				}
+			}
+			for key, want := range tt.wantCredit {
+				if got := state.Credit[key]; math.Abs(got-want) > boePayoutEpsilon {
+					t.Errorf("credit[%q] = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
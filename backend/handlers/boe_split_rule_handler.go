@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"encoding/json"
+	"krankenprep/database"
+	"krankenprep/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// boeSplitRuleBackdateGrace tolerates clock skew between the browser and
+// the server when a new rule is meant to take effect "now".
+const boeSplitRuleBackdateGrace = time.Minute
+
+var validBoeSplitKinds = map[string]bool{
+	models.BoeSplitPercent: true,
+	models.BoeSplitFlatFee: true,
+	models.BoeSplitTiered:  true,
+}
+
+// GetBoeSplitRules returns the team's split-rule history, newest first, and
+// the rule currently in effect — DefaultBoeSplitRule (ID 0) if the team has
+// never configured one. Visible to every team member.
+func GetBoeSplitRules(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	rules, err := teamBoeSplitRules(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE split rules"})
+		return
+	}
+
+	current := rules.At(time.Now())
+	history := make([]models.BoeSplitRule, len(rules))
+	for i, r := range rules {
+		history[len(rules)-1-i] = r
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": history, "current": current})
+}
+
+type boeSplitRulePayload struct {
+	Kind                       string                `json:"kind"`
+	GuildPercent               float64               `json:"guild_percent"`
+	FlatFee                    float64               `json:"flat_fee"`
+	Tiers                      []models.BoeSplitTier `json:"tiers"`
+	SoldToPlayerWaivesGuildCut bool                  `json:"sold_to_player_waives_guild_cut"`
+	// EffectiveFrom defaults to now. It can be in the future, or in the
+	// past as long as no sale falls between it and the next rule — so a
+	// team can set the split it has always used before recording its first
+	// sale, but never re-cut a sale made under another rule.
+	EffectiveFrom *time.Time `json:"effective_from"`
+}
+
+// CreateBoeSplitRule adds a new version of the team's split. There's no
+// update: rules already in effect are part of the financial record, so the
+// only way to change the split is a new rule from now (or later) on, or a
+// backdated one covering a stretch with no sales yet. Owner/admin only.
+func CreateBoeSplitRule(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload boeSplitRulePayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if !validBoeSplitKinds[payload.Kind] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid split rule kind"})
+		return
+	}
+
+	rule := models.BoeSplitRule{
+		TeamID:                     uint(teamId),
+		Kind:                       payload.Kind,
+		EffectiveFrom:              time.Now(),
+		SoldToPlayerWaivesGuildCut: payload.SoldToPlayerWaivesGuildCut,
+		CreatedByUserID:            user.ID,
+	}
+	switch payload.Kind {
+	case models.BoeSplitPercent:
+		if payload.GuildPercent < 0 || payload.GuildPercent > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "guild_percent must be between 0 and 100"})
+			return
+		}
+		rule.GuildPercent = payload.GuildPercent
+	case models.BoeSplitFlatFee:
+		if payload.FlatFee < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "flat_fee can't be negative"})
+			return
+		}
+		rule.FlatFee = payload.FlatFee
+	case models.BoeSplitTiered:
+		if len(payload.Tiers) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a tiered split needs at least one tier"})
+			return
+		}
+		seen := make(map[float64]bool, len(payload.Tiers))
+		for _, t := range payload.Tiers {
+			if t.MinPrice < 0 || t.GuildPercent < 0 || t.GuildPercent > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "each tier needs a min_price of 0 or more and a guild_percent between 0 and 100"})
+				return
+			}
+			if seen[t.MinPrice] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "tiers must have distinct min_price values"})
+				return
+			}
+			seen[t.MinPrice] = true
+		}
+		tiers, err := json.Marshal(payload.Tiers)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encode tiers"})
+			return
+		}
+		rule.Tiers = tiers
+	}
+
+	if payload.EffectiveFrom != nil {
+		rule.EffectiveFrom = *payload.EffectiveFrom
+	}
+	if rule.EffectiveFrom.Before(time.Now().Add(-boeSplitRuleBackdateGrace)) {
+		// A backdated rule takes over every sale from EffectiveFrom until
+		// the next rule starts, so it's only allowed while there are none.
+		rules, err := teamBoeSplitRules(uint(teamId))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load BoE split rules"})
+			return
+		}
+		query := database.DB.Model(&models.BoeSale{}).Where("team_id = ? AND created_at >= ?", teamId, rule.EffectiveFrom)
+		for _, r := range rules {
+			if r.EffectiveFrom.After(rule.EffectiveFrom) {
+				query = query.Where("created_at < ?", r.EffectiveFrom)
+				break
+			}
+		}
+		var affected int64
+		if err := query.Count(&affected).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check BoE sales"})
+			return
+		}
+		if affected > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "effective_from would re-cut sales already made under another split — existing sales keep the split they were made under", "affected_sales": affected})
+			return
+		}
+	}
+
+	if err := database.DB.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save BoE split rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteBoeSplitRule withdraws a rule that hasn't taken effect yet. Rules
+// already in effect can't be deleted, since that would silently re-cut the
+// sales made under them. Owner/admin only.
+func DeleteBoeSplitRule(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	ruleId, err := strconv.ParseUint(c.Param("ruleId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid split rule ID"})
+		return
+	}
+
+	if !isTeamAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var rule models.BoeSplitRule
+	if err := database.DB.Where("id = ? AND team_id = ?", ruleId, teamId).First(&rule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "BoE split rule not found"})
+		return
+	}
+	if !rule.EffectiveFrom.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "split rule is already in effect — add a new rule instead"})
+		return
+	}
+
+	if err := database.DB.Delete(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete BoE split rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
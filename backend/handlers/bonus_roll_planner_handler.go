@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseBonusRollSimValues reads the planner's optional sim_values query
+// parameter — comma-separated item_id:gain pairs keyed by Item.ID, e.g. the
+// dps_gain from a droptimizer preview ("412:1.8,415:0.6"). Without it every
+// wished item counts as 1, so expected value reduces to plain "chance to hit
+// something I want"; a nil map means the parameter was absent.
+func parseBonusRollSimValues(q string) (map[uint]float64, error) {
+	if strings.TrimSpace(q) == "" {
+		return nil, nil
+	}
+	values := make(map[uint]float64)
+	for _, part := range strings.Split(q, ",") {
+		idText, gainText, found := strings.Cut(strings.TrimSpace(part), ":")
+		if !found {
+			return nil, fmt.Errorf("sim value %q is not item_id:gain", part)
+		}
+		itemId, err := strconv.ParseUint(strings.TrimSpace(idText), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("sim value %q has an invalid item ID", part)
+		}
+		gain, err := strconv.ParseFloat(strings.TrimSpace(gainText), 64)
+		if err != nil {
+			return nil, fmt.Errorf("sim value %q has an invalid gain", part)
+		}
+		values[uint(itemId)] = gain
+	}
+	return values, nil
+}
+
+type bonusRollPlanBoss struct {
+	BossID   uint   `json:"boss_id"`
+	BossName string `json:"boss_name"`
+	PoolSize uint   `json:"pool_size"`
+	// RemainingWished is how many wished-but-not-obtained items this boss
+	// still has for the character.
+	RemainingWished uint `json:"remaining_wished"`
+	// ExpectedValue is the value of the remaining wished items divided by
+	// the character's eligible pool on this boss — roughly what one bonus
+	// roll here is worth.
+	ExpectedValue     float64 `json:"expected_value"`
+	SuggestedPriority uint    `json:"suggested_priority"`
+	ChosenPriority    *uint   `json:"chosen_priority"`
+}
+
+type bonusRollPlanResponse struct {
+	CharacterID uint                `json:"character_id"`
+	Difficulty  string              `json:"difficulty"`
+	UsesSimData bool                `json:"uses_sim_data"`
+	Bosses      []bonusRollPlanBoss `json:"bosses"`
+	// MatchesChosenOrder is true when the character's own priorities put
+	// every suggested boss in the same order the solver did.
+	MatchesChosenOrder bool `json:"matches_chosen_order"`
+}
+
+// rankBonusRollPlan sorts bosses by expected value (highest first, ties
+// broken by boss order so the output is stable) and stamps each with its
+// suggested priority. Bosses with nothing left to win are dropped — a roll
+// there can't hit anything on the wishlist.
+func rankBonusRollPlan(bosses []bonusRollPlanBoss, bossOrder map[uint]int64) []bonusRollPlanBoss {
+	ranked := []bonusRollPlanBoss{}
+	for _, b := range bosses {
+		if b.ExpectedValue > 0 {
+			ranked = append(ranked, b)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].ExpectedValue != ranked[j].ExpectedValue {
+			return ranked[i].ExpectedValue > ranked[j].ExpectedValue
+		}
+		return bossOrder[ranked[i].BossID] < bossOrder[ranked[j].BossID]
+	})
+	for i := range ranked {
+		ranked[i].SuggestedPriority = uint(i + 1)
+	}
+	return ranked
+}
+
+// chosenOrderMatches reports whether the character's own priorities rank
+// the suggested bosses in the same relative order as the solver. Bosses the
+// character never prioritized count as a mismatch.
+func chosenOrderMatches(ranked []bonusRollPlanBoss) bool {
+	var previous uint
+	for _, b := range ranked {
+		if b.ChosenPriority == nil || *b.ChosenPriority <= previous {
+			return false
+		}
+		previous = *b.ChosenPriority
+	}
+	return true
+}
+
+// GetBonusRollPlan suggests the best boss order for a character's bonus
+// rolls in the current season: each boss is scored by the expected value of
+// a single roll (remaining wished value over eligible pool size, using the
+// same pool size computeBossRollStats reports everywhere else), and returned
+// alongside the character's own CharacterBossPriority so council can compare
+// the two. Read-only, so it takes difficulty and the optional sim_values
+// (see parseBonusRollSimValues) as query parameters. Gated by
+// canAccessCharacterWishlist, same as the wishlist itself.
+func GetBonusRollPlan(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	characterId, err := strconv.ParseUint(c.Param("characterId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+		return
+	}
+
+	difficulty, ok := getDifficulty(c, c.Query("difficulty"))
+	if !ok {
+		return
+	}
+
+	simValues, err := parseBonusRollSimValues(c.Query("sim_values"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !canAccessCharacterWishlist(uint(teamId), user.ID, uint(characterId)) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var priorities []models.CharacterBossPriority
+	database.DB.Where("character_id = ? AND difficulty = ?", characterId, difficulty).Find(&priorities)
+	chosenByBoss := make(map[uint]uint, len(priorities))
+	for _, p := range priorities {
+		chosenByBoss[p.BossID] = p.Priority
+	}
+
+	bosses := currentSeasonBosses()
+	bossIds := make([]uint, len(bosses))
+	for i, boss := range bosses {
+		bossIds[i] = boss.ID
+	}
+	itemsByBoss, rollsByBoss, err := computeBossesRollStats(bossIds, difficulty)
+	if err != nil {
+		log.Printf("Error computing roll stats for bonus roll plan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute bonus roll plan"})
+		return
+	}
+
+	bossOrder := make(map[uint]int64, len(bosses))
+	planBosses := []bonusRollPlanBoss{}
+	for _, boss := range bosses {
+		bossOrder[boss.ID] = boss.Order
+		items, rolls := itemsByBoss[boss.ID], rollsByBoss[boss.ID]
+
+		var poolSize uint
+		for _, roll := range rolls {
+			if roll.CharacterID == uint(characterId) {
+				poolSize = roll.PoolSize
+				break
+			}
+		}
+
+		var remaining uint
+		var value float64
+		for _, item := range items {
+			for _, wisher := range item.Wishers {
+				if wisher.CharacterID != uint(characterId) || wisher.Obtained {
+					continue
+				}
+				remaining++
+				if simValues != nil {
+					value += simValues[item.ID]
+				} else {
+					value++
+				}
+			}
+		}
+
+		entry := bonusRollPlanBoss{
+			BossID:          boss.ID,
+			BossName:        boss.Name,
+			PoolSize:        poolSize,
+			RemainingWished: remaining,
+		}
+		if poolSize > 0 {
+			entry.ExpectedValue = value / float64(poolSize)
+		}
+		if p, ok := chosenByBoss[boss.ID]; ok {
+			entry.ChosenPriority = &p
+		}
+		planBosses = append(planBosses, entry)
+	}
+
+	ranked := rankBonusRollPlan(planBosses, bossOrder)
+	c.JSON(http.StatusOK, gin.H{"bonus_roll_plan": bonusRollPlanResponse{
+		CharacterID:        uint(characterId),
+		Difficulty:         difficulty,
+		UsesSimData:        simValues != nil,
+		Bosses:             ranked,
+		MatchesChosenOrder: chosenOrderMatches(ranked),
+	}})
+}
@@ -0,0 +1,494 @@
+package handlers
+
+import (
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"krankenprep/utilities"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type bossAssignmentSlotPayload struct {
+	CharacterID uint `json:"character_id"`
+	SpellID     *int `json:"spell_id"`
+}
+
+type bossAssignmentPayload struct {
+	Heading    string                      `json:"heading"`
+	Subheading string                      `json:"subheading"`
+	SpellID    *int                        `json:"spell_id"`
+	Slots      []bossAssignmentSlotPayload `json:"slots"`
+}
+
+func normalizeAssignmentHeading(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// findAssignmentLine locates a heading/subheading pair in the map, matching
+// either the heading or its alias case-insensitively like the frontend
+// does. Returns the indices into def.Assignments and its Subheadings.
+func findAssignmentLine(def models.AssignmentMapDef, heading, subheading string) (int, int, bool) {
+	h, s := normalizeAssignmentHeading(heading), normalizeAssignmentHeading(subheading)
+	for i, entry := range def.Assignments {
+		if normalizeAssignmentHeading(entry.Heading) != h &&
+			(entry.HeadingAlias == nil || normalizeAssignmentHeading(*entry.HeadingAlias) != h) {
+			continue
+		}
+		for j, sub := range entry.Subheadings {
+			if normalizeAssignmentHeading(sub.Heading) == s ||
+				(sub.HeadingAlias != nil && *sub.HeadingAlias != "" && normalizeAssignmentHeading(*sub.HeadingAlias) == s) {
+				return i, j, true
+			}
+		}
+		return i, -1, false
+	}
+	return -1, -1, false
+}
+
+// bossAssignmentProblems checks lines against the boss's AssignmentMap:
+// every line must name a heading/subheading in the map, appear once, fit
+// in the subheading's available slots (0 means unlimited) and list each
+// character once, and positional lines must be filled in order. Run on
+// save to reject bad input, and on read to surface lines a later map
+// change has invalidated.
+func bossAssignmentProblems(def models.AssignmentMapDef, lines []models.BossAssignment) []string {
+	problems := []string{}
+	seen := make(map[[2]int]bool, len(lines))
+	filled := make(map[[2]int]bool, len(lines))
+	for _, line := range lines {
+		i, j, found := findAssignmentLine(def, line.Heading, line.Subheading)
+		if i < 0 {
+			problems = append(problems, fmt.Sprintf("%q is not a heading in this boss's assignment map", line.Heading))
+			continue
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%q has no subheading %q", line.Heading, line.Subheading))
+			continue
+		}
+		if seen[[2]int{i, j}] {
+			problems = append(problems, fmt.Sprintf("%q / %q is listed more than once", line.Heading, line.Subheading))
+			continue
+		}
+		seen[[2]int{i, j}] = true
+		if len(line.Slots) > 0 {
+			filled[[2]int{i, j}] = true
+		}
+
+		if slots := def.Assignments[i].Subheadings[j].AvailableSlots; slots > 0 && len(line.Slots) > slots {
+			problems = append(problems, fmt.Sprintf("%q / %q has %d slots but %d characters are assigned", line.Heading, line.Subheading, slots, len(line.Slots)))
+		}
+		characters := make(map[uint]bool, len(line.Slots))
+		for _, slot := range line.Slots {
+			if characters[slot.CharacterID] {
+				problems = append(problems, fmt.Sprintf("%q / %q lists the same character more than once", line.Heading, line.Subheading))
+				break
+			}
+			characters[slot.CharacterID] = true
+		}
+	}
+
+	// Positional lines are told apart only by their order in the note, so
+	// an empty one ahead of a filled one would shift everyone after it.
+	for i, entry := range def.Assignments {
+		gap := ""
+		for j, sub := range entry.Subheadings {
+			if !sub.Positional() {
+				continue
+			}
+			if !filled[[2]int{i, j}] {
+				if gap == "" {
+					gap = sub.Heading
+				}
+			} else if gap != "" {
+				problems = append(problems, fmt.Sprintf("%q / %q must be filled before %q — unlabelled lines are matched by position", entry.Heading, gap, sub.Heading))
+				break
+			}
+		}
+	}
+	return problems
+}
+
+// sortBossAssignments orders lines the way the AssignmentMap lists them,
+// with lines the map no longer knows at the end.
+func sortBossAssignments(def models.AssignmentMapDef, lines []models.BossAssignment) {
+	rank := func(line models.BossAssignment) int {
+		i, j, found := findAssignmentLine(def, line.Heading, line.Subheading)
+		if !found {
+			return 1 << 30
+		}
+		return i*1000 + j
+	}
+	sort.SliceStable(lines, func(a, b int) bool { return rank(lines[a]) < rank(lines[b]) })
+}
+
+func loadBossAssignments(teamId, bossId uint) []models.BossAssignment {
+	lines := []models.BossAssignment{}
+	database.DB.Preload("Slots", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Where("team_id = ? AND boss_id = ?", teamId, bossId).
+		Find(&lines)
+	return lines
+}
+
+// noteTimelineLines returns the note's NSRT header and timeline lines —
+// the part of an AssignmentNote the structured assignments don't model —
+// as ParseRaidNote sets them aside.
+func noteTimelineLines(note string) []string {
+	return utilities.ParseRaidNote(note).Skipped
+}
+
+// assignmentNoteRenderer writes structured assignments as note blocks in
+// the layout ParseRaidNote reads back: a heading line, then one
+// "Label: names" line per subheading (bare names for positional ones),
+// with "...start" headings closed by a matching "...end" line. For the
+// in-game note, Game adds class colors (|cffRRGGBBName|r) and {spell:id}
+// icons; the plain form is what's stored in AssignmentNote, since the
+// frontend splits names on spaces.
+type assignmentNoteRenderer struct {
+	Game        bool
+	Characters  map[uint]models.Character
+	ClassColors map[string]string
+}
+
+func newAssignmentNoteRenderer(lines []models.BossAssignment, game bool) assignmentNoteRenderer {
+	r := assignmentNoteRenderer{Game: game, Characters: map[uint]models.Character{}, ClassColors: map[string]string{}}
+	ids := []uint{}
+	for _, line := range lines {
+		for _, slot := range line.Slots {
+			ids = append(ids, slot.CharacterID)
+		}
+	}
+	if len(ids) > 0 {
+		var characters []models.Character
+		database.DB.Where("id IN ?", ids).Find(&characters)
+		for _, ch := range characters {
+			r.Characters[ch.ID] = ch
+		}
+	}
+	if game {
+		var classes []models.Class
+		database.DB.Find(&classes)
+		for _, class := range classes {
+			r.ClassColors[class.Name] = strings.TrimPrefix(class.Color, "#")
+		}
+	}
+	return r
+}
+
+func (r assignmentNoteRenderer) name(slot models.BossAssignmentSlot) string {
+	ch, found := r.Characters[slot.CharacterID]
+	if !found {
+		return ""
+	}
+	if !r.Game {
+		return ch.Name
+	}
+	name := ch.Name
+	if color := r.ClassColors[ch.Class]; color != "" {
+		name = "|cff" + color + name + "|r"
+	}
+	if slot.SpellID != nil {
+		name += fmt.Sprintf(" {spell:%d}", *slot.SpellID)
+	}
+	return name
+}
+
+func (r assignmentNoteRenderer) Render(def models.AssignmentMapDef, lines []models.BossAssignment) string {
+	byLine := make(map[[2]int]models.BossAssignment, len(lines))
+	for _, line := range lines {
+		if i, j, found := findAssignmentLine(def, line.Heading, line.Subheading); found {
+			byLine[[2]int{i, j}] = line
+		}
+	}
+
+	var blocks []string
+	for i, entry := range def.Assignments {
+		var block []string
+		for j, sub := range entry.Subheadings {
+			line, found := byLine[[2]int{i, j}]
+			if !found || len(line.Slots) == 0 {
+				continue
+			}
+			names := make([]string, 0, len(line.Slots))
+			for _, slot := range line.Slots {
+				if name := r.name(slot); name != "" {
+					names = append(names, name)
+				}
+			}
+			text := strings.Join(names, " ")
+			if !sub.Positional() {
+				text = sub.NoteLabel() + ": " + text
+			}
+			if r.Game && line.SpellID != nil {
+				text = fmt.Sprintf("{spell:%d} ", *line.SpellID) + text
+			}
+			block = append(block, text)
+		}
+		if len(block) == 0 {
+			continue
+		}
+		heading := entry.NoteHeading()
+		block = append([]string{heading}, block...)
+		if strings.Contains(heading, "start") {
+			block = append(block, strings.Replace(heading, "start", "end", 1))
+		}
+		blocks = append(blocks, strings.Join(block, "\n"))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// unmappedNoteBlocks returns, as written, the note's blocks whose heading
+// isn't in the AssignmentMap — hand-written blocks and free text the
+// structured assignments can't represent, which regenerating the note
+// must carry over rather than drop.
+func unmappedNoteBlocks(def models.AssignmentMapDef, note string) []string {
+	blocks := []string{}
+	for _, section := range utilities.ParseRaidNote(note).Sections {
+		if i, _, _ := findAssignmentLine(def, section.Heading.Text, ""); i < 0 {
+			blocks = append(blocks, strings.Join(section.RawLines(), "\n"))
+		}
+	}
+	return blocks
+}
+
+// composeAssignmentNote rebuilds a note around freshly rendered blocks:
+// the existing note's timeline lines above them and its unmapped blocks
+// below, each part separated by a blank line.
+func composeAssignmentNote(def models.AssignmentMapDef, existing string, blocks string) string {
+	parts := []string{}
+	if timeline := noteTimelineLines(existing); len(timeline) > 0 {
+		parts = append(parts, strings.Join(timeline, "\n"))
+	}
+	if blocks != "" {
+		parts = append(parts, blocks)
+	}
+	parts = append(parts, unmappedNoteBlocks(def, existing)...)
+	return strings.Join(parts, "\n\n")
+}
+
+// GetBossAssignments returns the structured assignments for a boss in
+// AssignmentMap order, plus any problems against the current map (e.g. a
+// subheading that has since lost slots). Visible to every team member.
+func GetBossAssignments(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	bossId, err := strconv.ParseUint(c.Param("bossId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid boss ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var boss models.Boss
+	if err := database.DB.First(&boss, bossId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "boss not found"})
+		return
+	}
+	def, err := boss.DecodedAssignmentMap()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read assignment map"})
+		return
+	}
+
+	lines := loadBossAssignments(uint(teamId), boss.ID)
+	sortBossAssignments(def, lines)
+
+	c.JSON(http.StatusOK, gin.H{"assignments": lines, "problems": bossAssignmentProblems(def, lines)})
+}
+
+// SetBossAssignments replaces a boss's structured assignments after
+// validating them against its AssignmentMap, the team roster and the Spell
+// table. The AssignmentNote is rewritten from them so the frontend's
+// note-based views stay in step — the blocks for the map's headings are
+// regenerated, while its timeline lines and any blocks under headings the
+// map doesn't have are kept as written. Admin/owner only, same as the
+// note.
+func SetBossAssignments(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	bossId, err := strconv.ParseUint(c.Param("bossId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid boss ID"})
+		return
+	}
+
+	if !isTeamAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload struct {
+		Assignments []bossAssignmentPayload `json:"assignments"`
+	}
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	var boss models.Boss
+	if err := database.DB.First(&boss, bossId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "boss not found"})
+		return
+	}
+	def, err := boss.DecodedAssignmentMap()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read assignment map"})
+		return
+	}
+
+	lines := make([]models.BossAssignment, 0, len(payload.Assignments))
+	characterIds := []uint{}
+	seenCharacters := make(map[uint]bool)
+	spellIds := []int{}
+	for _, p := range payload.Assignments {
+		line := models.BossAssignment{
+			TeamID:          uint(teamId),
+			BossID:          boss.ID,
+			Heading:         p.Heading,
+			Subheading:      p.Subheading,
+			SpellID:         p.SpellID,
+			UpdatedByUserID: user.ID,
+		}
+		// Store the map's own spelling, however the caller cased it.
+		if i, j, found := findAssignmentLine(def, p.Heading, p.Subheading); found {
+			line.Heading = def.Assignments[i].Heading
+			line.Subheading = def.Assignments[i].Subheadings[j].Heading
+		}
+		if p.SpellID != nil {
+			spellIds = append(spellIds, *p.SpellID)
+		}
+		for position, slot := range p.Slots {
+			line.Slots = append(line.Slots, models.BossAssignmentSlot{Position: position, CharacterID: slot.CharacterID, SpellID: slot.SpellID})
+			if !seenCharacters[slot.CharacterID] {
+				seenCharacters[slot.CharacterID] = true
+				characterIds = append(characterIds, slot.CharacterID)
+			}
+			if slot.SpellID != nil {
+				spellIds = append(spellIds, *slot.SpellID)
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	if problems := bossAssignmentProblems(def, lines); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "assignments don't match the boss's assignment map", "problems": problems})
+		return
+	}
+	if len(characterIds) > 0 {
+		if _, ok := loadTeamCharacters(uint(teamId), characterIds); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "character not found on this team's roster"})
+			return
+		}
+	}
+	if len(spellIds) > 0 {
+		distinct := make(map[int]bool, len(spellIds))
+		for _, id := range spellIds {
+			distinct[id] = true
+		}
+		var found int64
+		database.DB.Model(&models.Spell{}).Where("spell_id IN ?", spellIds).Count(&found)
+		if int(found) != len(distinct) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "spell not found"})
+			return
+		}
+	}
+
+	var note models.AssignmentNote
+	database.DB.Where("team_id = ? AND boss_id = ?", teamId, boss.ID).Limit(1).Find(&note)
+	blocks := newAssignmentNoteRenderer(lines, false).Render(def, lines)
+	note.TeamID = uint(teamId)
+	note.BossID = boss.ID
+	note.Note = composeAssignmentNote(def, note.Note, blocks)
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ? AND boss_id = ?", teamId, boss.ID).Delete(&models.BossAssignment{}).Error; err != nil {
+			return err
+		}
+		if len(lines) > 0 {
+			if err := tx.Create(&lines).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Team", "Boss").Save(&note).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save assignments"})
+		return
+	}
+
+	sortBossAssignments(def, lines)
+	c.JSON(http.StatusOK, gin.H{"assignments": lines, "assignment_note": note})
+}
+
+// GenerateAssignmentNote renders the boss's structured assignments as an
+// in-game raid note (MRT/ERT), with class-colored names and spell icons,
+// between the saved note's timeline lines and its hand-written blocks —
+// ready to paste. Visible to every team member.
+func GenerateAssignmentNote(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	bossId, err := strconv.ParseUint(c.Param("bossId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid boss ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var boss models.Boss
+	if err := database.DB.First(&boss, bossId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "boss not found"})
+		return
+	}
+	def, err := boss.DecodedAssignmentMap()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read assignment map"})
+		return
+	}
+
+	var note models.AssignmentNote
+	database.DB.Where("team_id = ? AND boss_id = ?", teamId, boss.ID).Limit(1).Find(&note)
+	lines := loadBossAssignments(uint(teamId), boss.ID)
+	blocks := newAssignmentNoteRenderer(lines, true).Render(def, lines)
+
+	c.JSON(http.StatusOK, gin.H{"note": composeAssignmentNote(def, note.Note, blocks)})
+}
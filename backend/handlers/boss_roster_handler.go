@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"krankenprep/utilities"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// noteSuggestionMinScore is the NameSimilarity a roster character needs to
+// be offered as "did you mean" for a name the note validation can't match.
+const noteSuggestionMinScore = 0.5
+
+// loadBossRoster returns the team's selection for a boss as comp
+// selections, in the order they were picked.
+func loadBossRoster(teamId, bossId uint) []compSelection {
+	var entries []models.BossRosterEntry
+	database.DB.Preload("Character.Player").
+		Preload("Character.Specialization.ArmorType").
+		Preload("Character.Specialization.Tags").
+		Preload("Specialization.ArmorType").
+		Preload("Specialization.Tags").
+		Where("team_id = ? AND boss_id = ?", teamId, bossId).
+		Order("id").
+		Find(&entries)
+
+	selections := make([]compSelection, len(entries))
+	for i := range entries {
+		spec := entries[i].Specialization
+		if spec == nil {
+			spec = entries[i].Character.Specialization
+		}
+		selections[i] = compSelection{Character: &entries[i].Character, Spec: spec}
+	}
+	return selections
+}
+
+// GetBossRoster returns which characters (and specs) play a boss, with the
+// comp breakdown for that selection. Visible to every team member.
+func GetBossRoster(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	bossId, err := strconv.ParseUint(c.Param("bossId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid boss ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	comp := buildCompProjection(loadBossRoster(uint(teamId), uint(bossId)))
+	c.JSON(http.StatusOK, gin.H{"boss_id": bossId, "roster": comp.Members, "comp": comp})
+}
+
+// SetBossRoster replaces the selected roster for a boss. Each player can
+// be selected on one character; a spec override must match the character's
+// class. Admin/owner only, same as editing the assignment note.
+func SetBossRoster(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	bossId, err := strconv.ParseUint(c.Param("bossId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid boss ID"})
+		return
+	}
+
+	if !isTeamAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload struct {
+		Characters []compCharacterPayload `json:"characters"`
+	}
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	var boss models.Boss
+	if err := database.DB.First(&boss, bossId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "boss not found"})
+		return
+	}
+
+	selections, ok := loadCompSelections(c, uint(teamId), payload.Characters)
+	if !ok {
+		return
+	}
+	players := make(map[uint]bool, len(selections))
+	for _, sel := range selections {
+		if players[sel.Character.PlayerID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "each player can only be selected on one character"})
+			return
+		}
+		players[sel.Character.PlayerID] = true
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ? AND boss_id = ?", teamId, bossId).Delete(&models.BossRosterEntry{}).Error; err != nil {
+			return err
+		}
+		if len(payload.Characters) == 0 {
+			return nil
+		}
+		entries := make([]models.BossRosterEntry, len(payload.Characters))
+		for i, pick := range payload.Characters {
+			entries[i] = models.BossRosterEntry{
+				TeamID:           uint(teamId),
+				BossID:           boss.ID,
+				CharacterID:      pick.CharacterID,
+				SpecializationID: pick.SpecializationID,
+			}
+		}
+		return tx.Create(&entries).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save boss roster"})
+		return
+	}
+
+	comp := buildCompProjection(selections)
+	c.JSON(http.StatusOK, gin.H{"boss_id": bossId, "roster": comp.Members, "comp": comp})
+}
+
+type noteNameIssue struct {
+	Name string `json:"name"`
+	// Locations are where in the note the name appears: "Heading / Sub" for
+	// assignment lines, "Timeline (phase N, Ts)" for timeline tags.
+	Locations     []string `json:"locations"`
+	CharacterID   *uint    `json:"character_id,omitempty"`
+	CharacterName string   `json:"character_name,omitempty"`
+	PlayerName    string   `json:"player_name,omitempty"`
+	// Suggestion is the closest roster character name for an unknown name,
+	// if any comes close — usually a typo or a renamed character.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+type noteUnassigned struct {
+	CharacterID   uint   `json:"character_id"`
+	CharacterName string `json:"character_name"`
+	PlayerName    string `json:"player_name"`
+}
+
+// noteIssueList collects issues keyed by name, keeping first-seen order.
+type noteIssueList struct {
+	issues []noteNameIssue
+	index  map[string]int
+}
+
+func (l *noteIssueList) add(name, location string) *noteNameIssue {
+	key := utilities.NameMatchKey(name)
+	if i, found := l.index[key]; found {
+		l.issues[i].Locations = append(l.issues[i].Locations, location)
+		return &l.issues[i]
+	}
+	if l.index == nil {
+		l.index = make(map[string]int)
+	}
+	l.index[key] = len(l.issues)
+	l.issues = append(l.issues, noteNameIssue{Name: name, Locations: []string{location}})
+	return &l.issues[len(l.issues)-1]
+}
+
+func (l *noteIssueList) list() []noteNameIssue {
+	if l.issues == nil {
+		return []noteNameIssue{}
+	}
+	return l.issues
+}
+
+// ValidateAssignmentNote checks a boss's AssignmentNote against the team
+// roster and the boss's selected roster. It flags names that match no
+// roster character (with a suggestion when one is close), names of roster
+// characters who aren't selected for the boss, and selected characters
+// the note never assigns anywhere. Names are matched with the same
+// rosterNameMatcher ImportRaidNote uses — ignoring realm suffixes, case,
+// punctuation and accents, and forgiving small typos — so a note that
+// validates imports the same way. Until a boss roster is selected only the
+// unknown-name check applies. Visible to every team member.
+func ValidateAssignmentNote(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	bossId, err := strconv.ParseUint(c.Param("bossId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid boss ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var note models.AssignmentNote
+	database.DB.Where("team_id = ? AND boss_id = ?", teamId, bossId).Limit(1).Find(&note)
+	parsed := utilities.ParseRaidNote(note.Note)
+
+	matcher := newRosterNameMatcher(teamRosterCharacters(uint(teamId)))
+
+	roster := loadBossRoster(uint(teamId), uint(bossId))
+	selected := make(map[uint]bool, len(roster))
+	for _, sel := range roster {
+		selected[sel.Character.ID] = true
+	}
+
+	var unknown, notSelected noteIssueList
+	assigned := make(map[uint]bool)
+	check := func(name, location string) {
+		matches, _ := matcher.Candidates(name)
+		if len(matches) == 0 {
+			issue := unknown.add(name, location)
+			if len(issue.Locations) == 1 {
+				issue.Suggestion = matcher.Suggest(name)
+			}
+			return
+		}
+		for _, ch := range matches {
+			if selected[ch.ID] {
+				assigned[ch.ID] = true
+				return
+			}
+		}
+		if len(roster) > 0 {
+			match := matcher.pick(matches, name)
+			issue := notSelected.add(name, location)
+			issue.CharacterID = &match.ID
+			issue.CharacterName = match.Name
+			issue.PlayerName = match.Player.Name
+		}
+	}
+
+	for _, section := range parsed.Sections {
+		for _, line := range section.Lines {
+			location := section.Heading.Text
+			if line.Label != "" {
+				location += " / " + line.Label
+			}
+			for _, entry := range line.Entries {
+				check(entry.Name, location)
+			}
+		}
+	}
+	for _, tag := range parsed.Timeline {
+		location := fmt.Sprintf("Timeline (phase %s, %ss)", tag.Phase, tag.Time)
+		for _, name := range tag.Players {
+			check(name, location)
+		}
+	}
+
+	unassigned := []noteUnassigned{}
+	for _, sel := range roster {
+		if !assigned[sel.Character.ID] {
+			unassigned = append(unassigned, noteUnassigned{
+				CharacterID:   sel.Character.ID,
+				CharacterName: sel.Character.Name,
+				PlayerName:    sel.Character.Player.Name,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"boss_id":            bossId,
+		"roster_selected":    len(roster) > 0,
+		"valid":              len(unknown.issues) == 0 && len(notSelected.issues) == 0 && len(unassigned) == 0,
+		"unknown_names":      unknown.list(),
+		"not_in_boss_roster": notSelected.list(),
+		"unassigned":         unassigned,
+		"parsed":             parsed,
+	})
+}
@@ -18,6 +18,7 @@ type CreateCharacterPayload struct {
 	Region           string `json:"region"`
 	IsMain           bool   `json:"is_main"`
 	SpecializationID *uint  `json:"specialization_id"`
+	BuildLabel       string `json:"build_label"`
 }
 
 func CreateCharacter(c *gin.Context) {
@@ -64,6 +65,7 @@ func CreateCharacter(c *gin.Context) {
 		Region:           payload.Region,
 		IsMain:           payload.IsMain,
 		SpecializationID: payload.SpecializationID,
+		BuildLabel:       payload.BuildLabel,
 	}
 
 	err = database.DB.Transaction(func(tx *gorm.DB) error {
@@ -94,6 +96,8 @@ type UpdateCharacterPayload struct {
 	Region           string `json:"region"`
 	IsMain           bool   `json:"is_main"`
 	SpecializationID *uint  `json:"specialization_id"`
+	// BuildLabel is optional so clients that predate it don't blank it out.
+	BuildLabel *string `json:"build_label"`
 }
 
 func UpdateCharacter(c *gin.Context) {
@@ -144,6 +148,9 @@ func UpdateCharacter(c *gin.Context) {
 	character.Region = payload.Region
 	character.IsMain = payload.IsMain
 	character.SpecializationID = payload.SpecializationID
+	if payload.BuildLabel != nil {
+		character.BuildLabel = *payload.BuildLabel
+	}
 
 	err = database.DB.Transaction(func(tx *gorm.DB) error {
 		if character.IsMain {
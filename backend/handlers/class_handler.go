@@ -11,7 +11,7 @@ import (
 func GetClasses(c *gin.Context) {
 	var classes []models.Class
 
-	if err := database.DB.Preload("Specializations").Order("name ASC").Find(&classes).Error; err != nil {
+	if err := database.DB.Preload("Specializations").Preload("Specializations.Tags").Order("name ASC").Find(&classes).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query classes"})
 		return
 	}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"krankenprep/database"
+	"krankenprep/models"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type compMember struct {
+	PlayerID      uint   `json:"player_id"`
+	PlayerName    string `json:"player_name"`
+	Status        string `json:"status,omitempty"`
+	CharacterID   *uint  `json:"character_id"`
+	CharacterName string `json:"character_name"`
+	Class         string `json:"class"`
+	Spec          string `json:"spec"`
+	// Role, ArmorType and Tags are empty when no spec is known for the
+	// character.
+	Role      string   `json:"role"`
+	ArmorType string   `json:"armor_type"`
+	Tags      []string `json:"tags"`
+}
+
+// newCompMember describes character playing spec. Either may be nil; spec
+// needs ArmorType and Tags preloaded.
+func newCompMember(character *models.Character, spec *models.Specialization) compMember {
+	m := compMember{Tags: []string{}}
+	if character != nil {
+		m.CharacterID = &character.ID
+		m.CharacterName = character.Name
+		m.Class = character.Class
+	}
+	if spec != nil {
+		m.Spec = spec.Name
+		m.Role = spec.Role
+		m.ArmorType = spec.ArmorType.Name
+		for _, t := range spec.Tags {
+			m.Tags = append(m.Tags, t.Tag)
+		}
+	}
+	return m
+}
+
+type compCoverage struct {
+	models.RaidCoverageCheck
+	Covered bool `json:"covered"`
+	// ProvidedBy lists the character names that bring it, so the
+	// checklist doubles as "who do we lose if X sits out".
+	ProvidedBy []string `json:"provided_by"`
+}
+
+type compProjection struct {
+	Total       int            `json:"total"`
+	Tanks       int            `json:"tanks"`
+	Healers     int            `json:"healers"`
+	DPS         int            `json:"dps"`
+	UnknownRole int            `json:"unknown_role"`
+	ArmorTypes  map[string]int `json:"armor_types"`
+	Members     []compMember   `json:"members"`
+	Coverage    []compCoverage `json:"coverage"`
+	// Missing is the labels of every uncovered checklist line — the
+	// flagged gaps, without having to filter Coverage client-side.
+	Missing []string `json:"missing"`
+}
+
+func newCompProjection() compProjection {
+	return compProjection{ArmorTypes: map[string]int{}, Members: []compMember{}}
+}
+
+func (p *compProjection) add(m compMember) {
+	p.Total++
+	switch m.Role {
+	case models.RoleTank:
+		p.Tanks++
+	case models.RoleHealer:
+		p.Healers++
+	case models.RoleDPS:
+		p.DPS++
+	default:
+		p.UnknownRole++
+	}
+	if m.ArmorType != "" {
+		p.ArmorTypes[m.ArmorType]++
+	}
+	p.Members = append(p.Members, m)
+}
+
+// finish sorts the members and works out the coverage checklist. Call once
+// after the last add.
+func (p *compProjection) finish() {
+	sort.SliceStable(p.Members, func(i, j int) bool {
+		if p.Members[i].PlayerName != p.Members[j].PlayerName {
+			return p.Members[i].PlayerName < p.Members[j].PlayerName
+		}
+		return p.Members[i].CharacterName < p.Members[j].CharacterName
+	})
+
+	providers := make(map[string][]string)
+	for _, m := range p.Members {
+		for _, tag := range m.Tags {
+			providers[tag] = append(providers[tag], m.CharacterName)
+		}
+	}
+	p.Coverage = make([]compCoverage, len(models.RaidCoverageChecklist))
+	p.Missing = []string{}
+	for i, check := range models.RaidCoverageChecklist {
+		by := providers[check.Tag]
+		if by == nil {
+			by = []string{}
+			p.Missing = append(p.Missing, check.Label)
+		}
+		p.Coverage[i] = compCoverage{RaidCoverageCheck: check, Covered: len(by) > 0, ProvidedBy: by}
+	}
+}
+
+type compCharacterPayload struct {
+	CharacterID uint `json:"character_id"`
+	// SpecializationID overrides the character's own spec (e.g. a shaman
+	// swapping to Restoration for one boss). Must match the class.
+	SpecializationID *uint `json:"specialization_id"`
+}
+
+// compSelection is a roster character and the spec they'll play (nil when
+// unknown), with Player, ArmorType and Tags loaded.
+type compSelection struct {
+	Character *models.Character
+	Spec      *models.Specialization
+}
+
+// loadCompSelections resolves picks to team roster characters and their
+// specs — the override if given, else the character's own. Writes a 400
+// and returns false if a character isn't on the roster, is listed twice,
+// or is given a spec from another class.
+func loadCompSelections(c *gin.Context, teamId uint, picks []compCharacterPayload) ([]compSelection, bool) {
+	characterIds := make([]uint, 0, len(picks))
+	specIds := []uint{}
+	seen := make(map[uint]bool, len(picks))
+	for _, pick := range picks {
+		if seen[pick.CharacterID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "each character can only be listed once"})
+			return nil, false
+		}
+		seen[pick.CharacterID] = true
+		characterIds = append(characterIds, pick.CharacterID)
+		if pick.SpecializationID != nil {
+			specIds = append(specIds, *pick.SpecializationID)
+		}
+	}
+
+	characters := []models.Character{}
+	if len(characterIds) > 0 {
+		database.DB.Preload("Player").
+			Preload("Specialization.ArmorType").
+			Preload("Specialization.Tags").
+			Joins("JOIN players ON players.id = characters.player_id").
+			Where("players.team_id = ? AND characters.id IN ?", teamId, characterIds).
+			Find(&characters)
+	}
+	if len(characters) != len(characterIds) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "character not found on this team's roster"})
+		return nil, false
+	}
+	byId := make(map[uint]*models.Character, len(characters))
+	for i := range characters {
+		byId[characters[i].ID] = &characters[i]
+	}
+
+	overrides := make(map[uint]*models.Specialization)
+	if len(specIds) > 0 {
+		var specs []models.Specialization
+		database.DB.Preload("Class").Preload("ArmorType").Preload("Tags").Where("id IN ?", specIds).Find(&specs)
+		for i := range specs {
+			overrides[specs[i].ID] = &specs[i]
+		}
+	}
+
+	selections := make([]compSelection, len(picks))
+	for i, pick := range picks {
+		character := byId[pick.CharacterID]
+		spec := character.Specialization
+		if pick.SpecializationID != nil {
+			override, found := overrides[*pick.SpecializationID]
+			if !found {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "specialization not found"})
+				return nil, false
+			}
+			if override.Class.Name != character.Class {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "specialization does not match the character's class"})
+				return nil, false
+			}
+			spec = override
+		}
+		selections[i] = compSelection{Character: character, Spec: spec}
+	}
+	return selections, true
+}
+
+func buildCompProjection(selections []compSelection) compProjection {
+	comp := newCompProjection()
+	for _, sel := range selections {
+		m := newCompMember(sel.Character, sel.Spec)
+		m.PlayerID = sel.Character.PlayerID
+		m.PlayerName = sel.Character.Player.Name
+		comp.add(m)
+	}
+	comp.finish()
+	return comp
+}
+
+// BuildRaidComp reports role counts, armor-type distribution (what tier
+// tokens will be contested) and buff/utility coverage for an arbitrary set
+// of roster characters — a night's roster or a single boss's. Characters
+// without a known spec still count toward the total but can't cover
+// anything. Visible to every team member, since it doesn't save anything.
+func BuildRaidComp(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload struct {
+		Characters []compCharacterPayload `json:"characters"`
+	}
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	selections, ok := loadCompSelections(c, uint(teamId), payload.Characters)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comp": buildCompProjection(selections)})
+}
+
+// GetRaidCoverageChecklist lists every buff/debuff/utility the comp builder
+// checks, with the specs that bring each — for "who could we bring for X".
+func GetRaidCoverageChecklist(c *gin.Context) {
+	var tags []models.SpecializationTag
+	database.DB.Find(&tags)
+	specIdsByTag := make(map[string][]uint)
+	for _, t := range tags {
+		specIdsByTag[t.Tag] = append(specIdsByTag[t.Tag], t.SpecializationID)
+	}
+
+	type checklistEntry struct {
+		models.RaidCoverageCheck
+		SpecializationIDs []uint `json:"specialization_ids"`
+	}
+	checklist := make([]checklistEntry, len(models.RaidCoverageChecklist))
+	for i, check := range models.RaidCoverageChecklist {
+		ids := specIdsByTag[check.Tag]
+		if ids == nil {
+			ids = []uint{}
+		}
+		checklist[i] = checklistEntry{RaidCoverageCheck: check, SpecializationIDs: ids}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"checklist": checklist})
+}
@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// defaultMinUpgradePercent is the DPS gain (as a percent of the report's
+// baseline) an item has to clear to be imported as a wish when the request
+// doesn't specify its own threshold. Anything under half a percent is
+// usually sim noise rather than a real upgrade.
+const defaultMinUpgradePercent = 0.5
+
+// droptimizerUpgrade is one report profileset resolved down to the WoW item
+// it simmed and its gain over the report's baseline.
+type droptimizerUpgrade struct {
+	WowItemID      uint
+	DPSGain        float64
+	UpgradePercent float64
+}
+
+// parseDroptimizerProfilesetItemID pulls the WoW item ID out of a droptimizer
+// profileset name. Raidbots names these
+// "<instanceId>/<encounterId>/<difficulty>/<itemId>/<ilvl>/<bonusIds>/<slot>",
+// so the item is always the 4th segment — if Raidbots ever changes that
+// layout this returns ok=false and the row is skipped rather than guessed at.
+func parseDroptimizerProfilesetItemID(name string) (uint, bool) {
+	parts := strings.Split(name, "/")
+	if len(parts) < 4 {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(parts[3], 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseDroptimizerUpgrades reads the baseline DPS and every profileset result
+// from raw report JSON. When the same item shows up in more than one
+// profileset (e.g. a ring simmed in both finger slots), the best result wins.
+func parseDroptimizerUpgrades(data map[string]interface{}) ([]droptimizerUpgrade, error) {
+	sim, _ := data["sim"].(map[string]interface{})
+	players, _ := sim["players"].([]interface{})
+	if len(players) == 0 {
+		return nil, fmt.Errorf("report has no baseline player")
+	}
+	player, _ := players[0].(map[string]interface{})
+	baseline := getNestedFloat(player, "collected_data", "dps", "mean")
+	if baseline <= 0 {
+		return nil, fmt.Errorf("report has no baseline DPS")
+	}
+
+	profilesets, _ := sim["profilesets"].(map[string]interface{})
+	results, _ := profilesets["results"].([]interface{})
+
+	bestByItem := make(map[uint]droptimizerUpgrade, len(results))
+	for _, r := range results {
+		result, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := result["name"].(string)
+		wowItemID, ok := parseDroptimizerProfilesetItemID(name)
+		if !ok {
+			continue
+		}
+		mean, _ := result["mean"].(float64)
+		gain := mean - baseline
+		if existing, seen := bestByItem[wowItemID]; seen && existing.DPSGain >= gain {
+			continue
+		}
+		bestByItem[wowItemID] = droptimizerUpgrade{
+			WowItemID:      wowItemID,
+			DPSGain:        gain,
+			UpgradePercent: gain / baseline * 100,
+		}
+	}
+
+	upgrades := make([]droptimizerUpgrade, 0, len(bestByItem))
+	for _, u := range bestByItem {
+		upgrades = append(upgrades, u)
+	}
+	sort.Slice(upgrades, func(i, j int) bool { return upgrades[i].DPSGain > upgrades[j].DPSGain })
+	return upgrades, nil
+}
+
+type droptimizerWishesPayload struct {
+	URL               string   `json:"url"`
+	MinUpgradePercent *float64 `json:"min_upgrade_percent"`
+	// PreviewFingerprint is the fingerprint the preview returned; import
+	// requires it (see ImportDroptimizerWishes).
+	PreviewFingerprint string `json:"preview_fingerprint"`
+}
+
+type droptimizerWishEntry struct {
+	ItemID         uint    `json:"item_id"`
+	WowItemID      uint    `json:"wow_item_id"`
+	Name           string  `json:"name"`
+	IconUrl        string  `json:"icon_url"`
+	Slot           string  `json:"slot"`
+	BossID         uint    `json:"boss_id"`
+	BossName       string  `json:"boss_name"`
+	DPSGain        float64 `json:"dps_gain"`
+	UpgradePercent float64 `json:"upgrade_percent"`
+	// AlreadyWished items are shown in the preview for context but never
+	// re-created (and never re-logged) on import.
+	AlreadyWished bool `json:"already_wished"`
+}
+
+type droptimizerWishesResponse struct {
+	ReportID          string                 `json:"report_id"`
+	Difficulty        string                 `json:"difficulty"`
+	MinUpgradePercent float64                `json:"min_upgrade_percent"`
+	Wishes            []droptimizerWishEntry `json:"wishes"`
+	// UnmatchedItemIDs are report items above the threshold that don't
+	// exist in the Item catalog (not seeded yet, or not raid loot at all).
+	UnmatchedItemIDs []uint `json:"unmatched_item_ids"`
+	// Fingerprint identifies exactly what the preview showed, so import can
+	// tell whether the report (or the catalog) changed in between.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// droptimizerFingerprint hashes what a preview shows the player — the
+// report, difficulty, threshold, and every above-threshold item with its
+// gain. AlreadyWished is left out: import skips those either way.
+func droptimizerFingerprint(response droptimizerWishesResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%g", response.ReportID, response.Difficulty, response.MinUpgradePercent)
+	for _, w := range response.Wishes {
+		fmt.Fprintf(&b, "|%d:%d:%.2f", w.ItemID, w.WowItemID, w.DPSGain)
+	}
+	for _, id := range response.UnmatchedItemIDs {
+		fmt.Fprintf(&b, "|?%d", id)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// buildDroptimizerWishes does everything PreviewDroptimizerWishes and
+// ImportDroptimizerWishes share: validate the request, fetch and parse the
+// report, and resolve every above-threshold item against the catalog. It
+// writes the error response itself and returns ok=false on any failure.
+func buildDroptimizerWishes(c *gin.Context) (response droptimizerWishesResponse, payloadFingerprint string, teamId uint, characterId uint, user *models.User, ok bool) {
+	user, userOk := getRequestingUser(c)
+	if !userOk {
+		return
+	}
+
+	parsedTeamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+	teamId = uint(parsedTeamId)
+
+	parsedCharacterId, err := strconv.ParseUint(c.Param("characterId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+		return
+	}
+	characterId = uint(parsedCharacterId)
+
+	var payload droptimizerWishesPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if !canAccessCharacterWishlist(teamId, user.ID, characterId) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	minUpgradePercent := defaultMinUpgradePercent
+	if payload.MinUpgradePercent != nil {
+		if *payload.MinUpgradePercent < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_upgrade_percent must not be negative"})
+			return
+		}
+		minUpgradePercent = *payload.MinUpgradePercent
+	}
+
+	valid, reportID := parseDroptimizerURL(payload.URL)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid URL: must be a Raidbots droptimizer report (https://www.raidbots.com/simbot/report/...)"})
+		return
+	}
+
+	reportData, err := fetchRaidbotsReportData(reportID)
+	if err != nil {
+		log.Printf("failed to fetch raidbots report %s: %v", reportID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch report from Raidbots"})
+		return
+	}
+
+	meta, err := parseRaidbotsDroptimizerData(reportData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to parse report: %v", err)})
+		return
+	}
+	if meta.SimType != "droptimizer" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "simulation type must be droptimizer"})
+		return
+	}
+	// Wishes only exist for Heroic/Mythic, so a Normal/LFR report has
+	// nothing it could be imported into.
+	if meta.Difficulty != models.DifficultyHeroic && meta.Difficulty != models.DifficultyMythic {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("report difficulty must be Heroic or Mythic (got '%s')", meta.Difficulty)})
+		return
+	}
+
+	var character models.Character
+	if err := database.DB.First(&character, characterId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "character not found"})
+		return
+	}
+	if !strings.EqualFold(character.Name, meta.Character) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("report is for '%s', not '%s'", meta.Character, character.Name)})
+		return
+	}
+
+	upgrades, err := parseDroptimizerUpgrades(reportData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to parse report: %v", err)})
+		return
+	}
+
+	wowItemIDs := make([]uint, 0, len(upgrades))
+	for _, u := range upgrades {
+		if u.UpgradePercent >= minUpgradePercent {
+			wowItemIDs = append(wowItemIDs, u.WowItemID)
+		}
+	}
+
+	itemByWowID := make(map[uint]models.Item, len(wowItemIDs))
+	if len(wowItemIDs) > 0 {
+		var items []models.Item
+		database.DB.Preload("Boss").Where("wow_item_id IN ?", wowItemIDs).Find(&items)
+		for _, item := range items {
+			itemByWowID[item.WowItemID] = item
+		}
+	}
+
+	wishedItemIds := make(map[uint]bool)
+	var existing []models.CharacterItemWish
+	database.DB.Where("character_id = ? AND difficulty = ?", characterId, meta.Difficulty).Find(&existing)
+	for _, w := range existing {
+		wishedItemIds[w.ItemID] = true
+	}
+
+	response = droptimizerWishesResponse{
+		ReportID:          reportID,
+		Difficulty:        meta.Difficulty,
+		MinUpgradePercent: minUpgradePercent,
+		Wishes:            []droptimizerWishEntry{},
+		UnmatchedItemIDs:  []uint{},
+	}
+	for _, u := range upgrades {
+		if u.UpgradePercent < minUpgradePercent {
+			continue
+		}
+		item, found := itemByWowID[u.WowItemID]
+		if !found {
+			response.UnmatchedItemIDs = append(response.UnmatchedItemIDs, u.WowItemID)
+			continue
+		}
+		response.Wishes = append(response.Wishes, droptimizerWishEntry{
+			ItemID:         item.ID,
+			WowItemID:      item.WowItemID,
+			Name:           item.Name,
+			IconUrl:        item.IconUrl,
+			Slot:           item.Slot,
+			BossID:         item.BossID,
+			BossName:       item.Boss.Name,
+			DPSGain:        u.DPSGain,
+			UpgradePercent: u.UpgradePercent,
+			AlreadyWished:  wishedItemIds[item.ID],
+		})
+	}
+
+	response.Fingerprint = droptimizerFingerprint(response)
+	payloadFingerprint = payload.PreviewFingerprint
+	ok = true
+	return
+}
+
+// PreviewDroptimizerWishes shows which wishes ImportDroptimizerWishes would
+// create from a Raidbots droptimizer report, without writing anything — so
+// the player can sanity-check the threshold before committing.
+func PreviewDroptimizerWishes(c *gin.Context) {
+	response, _, _, _, _, ok := buildDroptimizerWishes(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"droptimizer_wishes": response})
+}
+
+// ImportDroptimizerWishes bulk-creates a character's item wishes from a
+// Raidbots droptimizer report, for the difficulty the report was simmed at.
+// Items already wished are left alone; every newly created wish gets its own
+// item_wished audit entry, same as wishing it by hand through UpsertItemWish.
+// The report is fetched again rather than trusted from the client, so the
+// payload must carry the preview's fingerprint: if the rebuilt list differs
+// from what the player confirmed (the report was re-simmed, the catalog
+// changed), the import is rejected with 409 and the new preview instead.
+func ImportDroptimizerWishes(c *gin.Context) {
+	response, previewFingerprint, teamId, characterId, user, ok := buildDroptimizerWishes(c)
+	if !ok {
+		return
+	}
+	if previewFingerprint == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "preview_fingerprint is required — preview the report first"})
+		return
+	}
+	if previewFingerprint != response.Fingerprint {
+		c.JSON(http.StatusConflict, gin.H{"error": "the report has changed since it was previewed", "droptimizer_wishes": response})
+		return
+	}
+
+	lockOverride, ok := checkWishlistLock(c, teamId, user.ID)
+	if !ok {
+		return
+	}
+
+	var character models.Character
+	if err := database.DB.First(&character, characterId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "character not found"})
+		return
+	}
+
+	created := []droptimizerWishEntry{}
+	for _, entry := range response.Wishes {
+		if entry.AlreadyWished {
+			continue
+		}
+		wish := models.CharacterItemWish{CharacterID: characterId, ItemID: entry.ItemID, Difficulty: response.Difficulty}
+		result := database.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "character_id"}, {Name: "item_id"}, {Name: "difficulty"}},
+			DoNothing: true,
+		}).Create(&wish)
+		if result.Error != nil {
+			log.Printf("failed to import droptimizer wish for item %d: %v", entry.ItemID, result.Error)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save wishes"})
+			return
+		}
+		// A concurrent UpsertItemWish may have won the race since the
+		// preview was built — nothing was created, so nothing to log.
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		itemId := entry.ItemID
+		itemName := entry.Name
+		recordAuditLog(models.LootAuditLog{
+			TeamID:         teamId,
+			EventType:      models.AuditEventItemWished,
+			ActingUserID:   user.ID,
+			ActingUserBTag: user.BTag,
+			CharacterID:    characterId,
+			CharacterName:  character.Name,
+			BossID:         entry.BossID,
+			BossName:       entry.BossName,
+			Difficulty:     response.Difficulty,
+			ItemID:         &itemId,
+			ItemName:       &itemName,
+			LockOverride:   lockOverride,
+		})
+		created = append(created, entry)
+	}
+
+	response.Wishes = created
+	c.JSON(http.StatusOK, gin.H{"droptimizer_wishes": response})
+}
@@ -0,0 +1,765 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// getEPGPSettings returns the team's EPGP settings, or the defaults (with a
+// zero ID) if the team never saved any, so callers never have to
+// special-case "not configured yet". Nothing is written — reads stay reads;
+// the row is created by the first save (UpdateEPGPSettings) or by
+// lockEPGPSettings.
+func getEPGPSettings(teamId uint) (models.EPGPSettings, error) {
+	var settings models.EPGPSettings
+	err := database.DB.Where("team_id = ?", teamId).First(&settings).Error
+	if err == nil {
+		return settings, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return settings, fmt.Errorf("fetching epgp settings: %w", err)
+	}
+	return models.DefaultEPGPSettings(teamId), nil
+}
+
+// lockEPGPSettings loads the team's settings row inside tx with a row lock,
+// creating it from the defaults first if it doesn't exist yet, so there is
+// always a row to lock.
+func lockEPGPSettings(tx *gorm.DB, teamId uint) (models.EPGPSettings, error) {
+	defaults := models.DefaultEPGPSettings(teamId)
+	if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "team_id"}}, DoNothing: true}).Create(&defaults).Error; err != nil {
+		return defaults, fmt.Errorf("creating default epgp settings: %w", err)
+	}
+	var settings models.EPGPSettings
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("team_id = ?", teamId).First(&settings).Error; err != nil {
+		return settings, fmt.Errorf("locking epgp settings: %w", err)
+	}
+	return settings, nil
+}
+
+// requireEPGPTeam loads the team and its EPGP settings, writing a 400 if the
+// team isn't in EPGP loot mode — ledger writes make no sense for a team
+// running council loot.
+func requireEPGPTeam(c *gin.Context, teamId uint) (models.EPGPSettings, bool) {
+	var team models.Team
+	if err := database.DB.First(&team, teamId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return models.EPGPSettings{}, false
+	}
+	if team.LootMode != models.LootModeEPGP {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "team is not using EPGP loot mode"})
+		return models.EPGPSettings{}, false
+	}
+	settings, err := getEPGPSettings(teamId)
+	if err != nil {
+		log.Printf("Error loading EPGP settings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load EPGP settings"})
+		return models.EPGPSettings{}, false
+	}
+	return settings, true
+}
+
+// loadTeamCharacters loads the given characters, returning ok=false if any
+// of them doesn't exist or isn't on the team's roster.
+func loadTeamCharacters(teamId uint, characterIds []uint) ([]models.Character, bool) {
+	var characters []models.Character
+	database.DB.Preload("Player").Where("id IN ?", characterIds).Find(&characters)
+	if len(characters) != len(characterIds) {
+		return nil, false
+	}
+	for _, ch := range characters {
+		if ch.Player.TeamID != teamId {
+			return nil, false
+		}
+	}
+	return characters, true
+}
+
+// epgpTotals sums the ledger into current EP/GP per character.
+func epgpTotals(teamId uint) (map[uint][2]float64, error) {
+	return epgpTotalsIn(database.DB, teamId)
+}
+
+// epgpTotalsIn is epgpTotals read through db, so a transaction can sum the
+// ledger it's about to write to.
+func epgpTotalsIn(db *gorm.DB, teamId uint) (map[uint][2]float64, error) {
+	var rows []struct {
+		CharacterID uint
+		EP          float64
+		GP          float64
+	}
+	if err := db.Model(&models.EPGPLedgerEntry{}).
+		Select("character_id, SUM(ep) AS ep, SUM(gp) AS gp").
+		Where("team_id = ?", teamId).
+		Group("character_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("summing epgp ledger: %w", err)
+	}
+	totals := make(map[uint][2]float64, len(rows))
+	for _, r := range rows {
+		totals[r.CharacterID] = [2]float64{r.EP, r.GP}
+	}
+	return totals, nil
+}
+
+type lootModePayload struct {
+	LootMode string `json:"loot_mode"`
+}
+
+// UpdateLootMode switches a team between council and EPGP loot. Switching
+// doesn't touch either side's data — wishlists and the EPGP ledger both
+// survive a round trip. Owner/admin only, like every other team setting.
+func UpdateLootMode(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload lootModePayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	if payload.LootMode != models.LootModeCouncil && payload.LootMode != models.LootModeEPGP {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "loot_mode must be council or epgp"})
+		return
+	}
+
+	if err := database.DB.Model(&models.Team{}).Where("id = ?", teamId).Update("loot_mode", payload.LootMode).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update loot mode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"loot_mode": payload.LootMode})
+}
+
+type epgpSettingsPayload struct {
+	EPPerAttendance    float64 `json:"ep_per_attendance"`
+	EPPerBossKill      float64 `json:"ep_per_boss_kill"`
+	BaseGP             float64 `json:"base_gp"`
+	ReferenceItemLevel uint    `json:"reference_item_level"`
+	DecayPercent       float64 `json:"decay_percent"`
+}
+
+// UpdateEPGPSettings replaces the team's EPGP tuning. Changes only affect
+// entries written afterwards — the ledger stores the EP/GP actually
+// charged, never a formula to re-evaluate. Owner/admin only.
+func UpdateEPGPSettings(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload epgpSettingsPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	if payload.BaseGP <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base_gp must be positive"})
+		return
+	}
+	if payload.EPPerAttendance < 0 || payload.EPPerBossKill < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "EP awards must not be negative"})
+		return
+	}
+	if payload.DecayPercent < 0 || payload.DecayPercent >= 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "decay_percent must be between 0 and 100"})
+		return
+	}
+
+	settings, err := getEPGPSettings(uint(teamId))
+	if err != nil {
+		log.Printf("Error loading EPGP settings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load EPGP settings"})
+		return
+	}
+	settings.EPPerAttendance = payload.EPPerAttendance
+	settings.EPPerBossKill = payload.EPPerBossKill
+	settings.BaseGP = payload.BaseGP
+	settings.ReferenceItemLevel = payload.ReferenceItemLevel
+	settings.DecayPercent = payload.DecayPercent
+	if err := database.DB.Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save EPGP settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+type epgpStanding struct {
+	CharacterID   uint    `json:"character_id"`
+	CharacterName string  `json:"character_name"`
+	PlayerName    string  `json:"player_name"`
+	EP            float64 `json:"ep"`
+	GP            float64 `json:"gp"`
+	Priority      float64 `json:"priority"`
+}
+
+// GetEPGPStandings returns every roster character's current EP, GP and
+// priority (EP / (GP + BaseGP)), highest priority first, alongside the
+// team's settings. Visible to every team member — standings are public in
+// EPGP by design.
+func GetEPGPStandings(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	settings, err := getEPGPSettings(uint(teamId))
+	if err != nil {
+		log.Printf("Error loading EPGP settings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load EPGP settings"})
+		return
+	}
+
+	totals, err := epgpTotals(uint(teamId))
+	if err != nil {
+		log.Printf("Error computing EPGP standings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute standings"})
+		return
+	}
+
+	standings := []epgpStanding{}
+	for _, ch := range teamRosterCharacters(uint(teamId)) {
+		total := totals[ch.ID]
+		standings = append(standings, epgpStanding{
+			CharacterID:   ch.ID,
+			CharacterName: ch.Name,
+			PlayerName:    ch.Player.Name,
+			EP:            total[0],
+			GP:            total[1],
+			Priority:      settings.Priority(total[0], total[1]),
+		})
+	}
+	sort.SliceStable(standings, func(i, j int) bool { return standings[i].Priority > standings[j].Priority })
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings, "standings": standings})
+}
+
+type epAwardPayload struct {
+	CharacterIDs []uint `json:"character_ids"`
+	Kind         string `json:"kind"`
+	// Amount overrides the settings default for Kind when set.
+	Amount *float64 `json:"amount"`
+	BossID *uint    `json:"boss_id"`
+	Reason string   `json:"reason"`
+}
+
+// AwardEP credits EP to a set of characters for attendance or a boss kill,
+// defaulting the amount from the team's settings. Loot-council/admin/owner
+// only.
+func AwardEP(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload epAwardPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	settings, ok := requireEPGPTeam(c, uint(teamId))
+	if !ok {
+		return
+	}
+
+	var amount float64
+	switch payload.Kind {
+	case models.EPGPKindAttendance:
+		amount = settings.EPPerAttendance
+	case models.EPGPKindBossKill:
+		if payload.BossID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "boss_id is required for a boss kill award"})
+			return
+		}
+		amount = settings.EPPerBossKill
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be ep_attendance or ep_boss_kill"})
+		return
+	}
+	if payload.Amount != nil {
+		amount = *payload.Amount
+	}
+	if amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "EP award must be positive"})
+		return
+	}
+	if len(payload.CharacterIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "character_ids must not be empty"})
+		return
+	}
+
+	characters, ok := loadTeamCharacters(uint(teamId), payload.CharacterIDs)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "every character must be on this team's roster"})
+		return
+	}
+
+	var boss models.Boss
+	if payload.BossID != nil {
+		if err := database.DB.First(&boss, *payload.BossID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "boss not found"})
+			return
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, ch := range characters {
+			if err := tx.Create(&models.EPGPLedgerEntry{
+				TeamID:       uint(teamId),
+				CharacterID:  ch.ID,
+				Kind:         payload.Kind,
+				EP:           amount,
+				BossID:       payload.BossID,
+				Reason:       payload.Reason,
+				ActingUserID: user.ID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to award EP"})
+		return
+	}
+
+	for _, ch := range characters {
+		recordAuditLog(models.LootAuditLog{
+			TeamID:         uint(teamId),
+			EventType:      models.AuditEventEPAwarded,
+			ActingUserID:   user.ID,
+			ActingUserBTag: user.BTag,
+			CharacterID:    ch.ID,
+			CharacterName:  ch.Name,
+			BossID:         boss.ID,
+			BossName:       boss.Name,
+			EPDelta:        &amount,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+type gpChargePayload struct {
+	CharacterID uint   `json:"character_id"`
+	ItemID      uint   `json:"item_id"`
+	Difficulty  string `json:"difficulty"`
+	// GP overrides the slot/item-level formula when set (e.g. an offspec
+	// item the team charges a flat discount for).
+	GP     *float64 `json:"gp"`
+	Reason string   `json:"reason"`
+}
+
+// ChargeGP records a character receiving an item, charging GP from the
+// item's slot and item level (see EPGPSettings.ItemGP). If the item was on
+// the character's wishlist for that difficulty it's marked obtained too, so
+// an EPGP team's wishlists stay as accurate as a council team's.
+// Loot-council/admin/owner only.
+func ChargeGP(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload gpChargePayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	difficulty, ok := getDifficulty(c, payload.Difficulty)
+	if !ok {
+		return
+	}
+
+	settings, ok := requireEPGPTeam(c, uint(teamId))
+	if !ok {
+		return
+	}
+
+	characters, ok := loadTeamCharacters(uint(teamId), []uint{payload.CharacterID})
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "character must be on this team's roster"})
+		return
+	}
+	character := characters[0]
+
+	var item models.Item
+	if err := database.DB.Preload("Boss").First(&item, payload.ItemID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+		return
+	}
+
+	gp := settings.ItemGP(item)
+	if payload.GP != nil {
+		gp = *payload.GP
+	}
+	if gp < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GP must not be negative"})
+		return
+	}
+
+	var markedObtained bool
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.EPGPLedgerEntry{
+			TeamID:       uint(teamId),
+			CharacterID:  character.ID,
+			Kind:         models.EPGPKindItem,
+			GP:           gp,
+			BossID:       &item.BossID,
+			ItemID:       &item.ID,
+			Difficulty:   difficulty,
+			Reason:       payload.Reason,
+			ActingUserID: user.ID,
+		}).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.CharacterItemWish{}).
+			Where("character_id = ? AND item_id = ? AND difficulty = ? AND obtained = ?", character.ID, item.ID, difficulty, false).
+			Update("obtained", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		markedObtained = result.RowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to charge GP"})
+		return
+	}
+
+	if _, err := applyTierTokenObtained(character.ID, item.ID, difficulty, user.ID); err != nil {
+		log.Printf("Error updating tier slot from token: %v", err)
+	}
+
+	itemName := item.Name
+	recordAuditLog(models.LootAuditLog{
+		TeamID:         uint(teamId),
+		EventType:      models.AuditEventGPCharged,
+		ActingUserID:   user.ID,
+		ActingUserBTag: user.BTag,
+		CharacterID:    character.ID,
+		CharacterName:  character.Name,
+		BossID:         item.BossID,
+		BossName:       item.Boss.Name,
+		Difficulty:     difficulty,
+		ItemID:         &item.ID,
+		ItemName:       &itemName,
+		GPDelta:        &gp,
+	})
+	if markedObtained {
+		recordAuditLog(models.LootAuditLog{
+			TeamID:         uint(teamId),
+			EventType:      models.AuditEventItemObtained,
+			ActingUserID:   user.ID,
+			ActingUserBTag: user.BTag,
+			CharacterID:    character.ID,
+			CharacterName:  character.Name,
+			BossID:         item.BossID,
+			BossName:       item.Boss.Name,
+			Difficulty:     difficulty,
+			ItemID:         &item.ID,
+			ItemName:       &itemName,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"gp": gp, "wish_marked_obtained": markedObtained})
+}
+
+type epgpAdjustPayload struct {
+	CharacterID uint    `json:"character_id"`
+	EP          float64 `json:"ep"`
+	GP          float64 `json:"gp"`
+	Reason      string  `json:"reason"`
+}
+
+// AdjustEPGP applies a manual signed EP/GP correction. A reason is required
+// — adjustments are the one ledger entry with no mechanical justification,
+// so the reason is the only record of why. Loot-council/admin/owner only.
+func AdjustEPGP(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload epgpAdjustPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	if strings.TrimSpace(payload.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required for an adjustment"})
+		return
+	}
+	if payload.EP == 0 && payload.GP == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "adjustment must change EP or GP"})
+		return
+	}
+
+	if _, ok := requireEPGPTeam(c, uint(teamId)); !ok {
+		return
+	}
+
+	characters, ok := loadTeamCharacters(uint(teamId), []uint{payload.CharacterID})
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "character must be on this team's roster"})
+		return
+	}
+	character := characters[0]
+
+	entry := models.EPGPLedgerEntry{
+		TeamID:       uint(teamId),
+		CharacterID:  character.ID,
+		Kind:         models.EPGPKindAdjustment,
+		EP:           payload.EP,
+		GP:           payload.GP,
+		Reason:       payload.Reason,
+		ActingUserID: user.ID,
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save adjustment"})
+		return
+	}
+
+	recordAuditLog(models.LootAuditLog{
+		TeamID:         uint(teamId),
+		EventType:      models.AuditEventEPGPAdjusted,
+		ActingUserID:   user.ID,
+		ActingUserBTag: user.BTag,
+		CharacterID:    character.ID,
+		CharacterName:  character.Name,
+		EPDelta:        &entry.EP,
+		GPDelta:        &entry.GP,
+	})
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// startOfWeekUTC returns 00:00 UTC on the Monday of t's week.
+func startOfWeekUTC(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+var errEPGPAlreadyDecayed = errors.New("decay already applied this week")
+
+// ApplyEPGPDecay shrinks every character's EP and GP by the team's
+// DecayPercent, written as one negative ledger entry per character. Only
+// allowed once per (Monday-based, UTC) week, so a double-click or a retried
+// request can't decay the team twice. Loot-council/admin/owner only.
+func ApplyEPGPDecay(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if _, ok := requireEPGPTeam(c, uint(teamId)); !ok {
+		return
+	}
+
+	// The once-a-week check and the totals being decayed are read inside
+	// the transaction with the settings row locked, so two concurrent
+	// requests can't both pass the check and decay the team twice.
+	now := time.Now()
+	var settings models.EPGPSettings
+	decayed := map[uint][2]float64{}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		settings, err = lockEPGPSettings(tx, uint(teamId))
+		if err != nil {
+			return err
+		}
+		if settings.LastDecayAt != nil && !settings.LastDecayAt.Before(startOfWeekUTC(now)) {
+			return errEPGPAlreadyDecayed
+		}
+
+		totals, err := epgpTotalsIn(tx, uint(teamId))
+		if err != nil {
+			return err
+		}
+		factor := settings.DecayPercent / 100
+		for characterId, total := range totals {
+			if total[0] == 0 && total[1] == 0 {
+				continue
+			}
+			entry := models.EPGPLedgerEntry{
+				TeamID:       uint(teamId),
+				CharacterID:  characterId,
+				Kind:         models.EPGPKindDecay,
+				EP:           -total[0] * factor,
+				GP:           -total[1] * factor,
+				Reason:       fmt.Sprintf("%g%% weekly decay", settings.DecayPercent),
+				ActingUserID: user.ID,
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+			decayed[characterId] = [2]float64{entry.EP, entry.GP}
+		}
+		settings.LastDecayAt = &now
+		return tx.Save(&settings).Error
+	})
+	if errors.Is(err, errEPGPAlreadyDecayed) {
+		c.JSON(http.StatusConflict, gin.H{"error": "decay has already been applied this week"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error applying EPGP decay: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply decay"})
+		return
+	}
+
+	if len(decayed) > 0 {
+		characterIds := make([]uint, 0, len(decayed))
+		for characterId := range decayed {
+			characterIds = append(characterIds, characterId)
+		}
+		var characters []models.Character
+		database.DB.Where("id IN ?", characterIds).Find(&characters)
+		for _, ch := range characters {
+			delta := decayed[ch.ID]
+			recordAuditLog(models.LootAuditLog{
+				TeamID:         uint(teamId),
+				EventType:      models.AuditEventEPGPDecayed,
+				ActingUserID:   user.ID,
+				ActingUserBTag: user.BTag,
+				CharacterID:    ch.ID,
+				CharacterName:  ch.Name,
+				EPDelta:        &delta[0],
+				GPDelta:        &delta[1],
+				DecayPercent:   &settings.DecayPercent,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"decayed_characters": len(decayed), "last_decay_at": now})
+}
+
+// GetEPGPLedger returns one character's full ledger, newest first — the
+// breakdown behind their standings row. Visible to every team member.
+func GetEPGPLedger(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	characterId, err := strconv.ParseUint(c.Param("characterId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var entries []models.EPGPLedgerEntry
+	if err := database.DB.Where("team_id = ? AND character_id = ?", teamId, characterId).
+		Order("id DESC").
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch ledger"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ledger": entries})
+}
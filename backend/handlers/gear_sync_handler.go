@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"krankenprep/types"
+	"krankenprep/utilities"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultGearSyncInterval is how often the background gear sync walks
+	// every roster — gear only changes meaningfully around raid nights, so
+	// a few times a day keeps the tracker fresh without hammering the API.
+	DefaultGearSyncInterval = 6 * time.Hour
+	// gearSyncRequestDelay spaces out characters during a full run,
+	// keeping well under Blizzard's per-second rate limit.
+	gearSyncRequestDelay = 200 * time.Millisecond
+)
+
+// bnetEquipmentTierSlots maps the profile API's slot.type to our tier slot.
+var bnetEquipmentTierSlots = map[string]string{
+	"HEAD":     models.TierSlotHead,
+	"SHOULDER": models.TierSlotShoulder,
+	"CHEST":    models.TierSlotChest,
+	"HANDS":    models.TierSlotGloves,
+	"LEGS":     models.TierSlotLegs,
+}
+
+// gearSyncClient is set by StartGearSyncJob; nil means the sync isn't
+// configured (no Battle.net credentials) and SyncTeamGear reports as much.
+// gearSyncMu keeps background passes from overlapping each other;
+// gearSyncTeamLocks holds one mutex per team, taken by whichever sync is
+// working through that team's roster — background or manual — so two never
+// write the same character's slot changes at once, while a manual sync for
+// one team doesn't wait on (or block) any other team.
+var (
+	gearSyncClient    *utilities.BnetClient
+	gearSyncMu        sync.Mutex
+	gearSyncTeamLocks sync.Map
+)
+
+// gearSyncTeamLock returns the team's gear sync mutex, creating it on
+// first use.
+func gearSyncTeamLock(teamId uint) *sync.Mutex {
+	lock, _ := gearSyncTeamLocks.LoadOrStore(teamId, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// StartGearSyncJob starts the background gear sync: every interval, every
+// character on any team's roster is synced via syncCharacterGear. The
+// first run starts immediately.
+func StartGearSyncJob(client *utilities.BnetClient, interval time.Duration) {
+	gearSyncClient = client
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			syncAllRosterGear(client)
+			<-ticker.C
+		}
+	}()
+}
+
+// syncAllRosterGear runs one full pass over every rostered character, one
+// team at a time under that team's lock (waiting out a manual sync of the
+// same team). A pass still in progress (e.g. a slow API) makes the next
+// tick a no-op rather than overlapping it.
+func syncAllRosterGear(client *utilities.BnetClient) {
+	if !gearSyncMu.TryLock() {
+		log.Println("Gear sync: previous run still in progress, skipping")
+		return
+	}
+	defer gearSyncMu.Unlock()
+
+	var season models.Season
+	if err := database.DB.Where("is_current = ?", true).First(&season).Error; err != nil {
+		log.Println("Gear sync: no current season set, skipping")
+		return
+	}
+
+	var characters []models.Character
+	if err := database.DB.Preload("Player").Joins("JOIN players ON players.id = characters.player_id").Order("players.team_id, characters.id").Find(&characters).Error; err != nil {
+		log.Printf("Gear sync: fetching characters: %v", err)
+		return
+	}
+
+	failed := 0
+	for start := 0; start < len(characters); {
+		teamId := characters[start].Player.TeamID
+		end := start
+		for end < len(characters) && characters[end].Player.TeamID == teamId {
+			end++
+		}
+		lock := gearSyncTeamLock(teamId)
+		lock.Lock()
+		for _, ch := range characters[start:end] {
+			if _, err := syncCharacterGear(client, ch, season); err != nil {
+				failed++
+			}
+			time.Sleep(gearSyncRequestDelay)
+		}
+		lock.Unlock()
+		start = end
+	}
+	log.Printf("Gear sync: synced %d characters (%d failed)", len(characters), failed)
+}
+
+// tierTrackFromDescription reads an upgrade track from an item's
+// name_description ("Mythic", "Heroic", "Champion 4/8"...) when the API
+// includes one. Raid difficulty names map onto the track they drop on.
+func tierTrackFromDescription(item types.EquippedItem) string {
+	if item.NameDescription == nil {
+		return ""
+	}
+	description := strings.ToLower(item.NameDescription.DisplayString)
+	switch {
+	case strings.Contains(description, "myth"):
+		return models.TierSourceMyth
+	case strings.Contains(description, "hero"):
+		return models.TierSourceHero
+	case strings.Contains(description, "champion"):
+		return models.TierSourceChampion
+	case strings.Contains(description, "veteran"):
+		return models.TierSourceVeteran
+	}
+	return ""
+}
+
+// gearSyncErrorMessage is what a failed sync stores on the character's
+// CharacterGearSync row — a not-found gets an actionable message, anything
+// else the error itself.
+func gearSyncErrorMessage(err error) string {
+	if errors.Is(err, utilities.ErrBnetNotFound) {
+		return "character not found on Battle.net — check name, realm and region"
+	}
+	return err.Error()
+}
+
+// fetchCharacterGear fetches the profile summary and equipment the sync
+// compares against.
+func fetchCharacterGear(client *utilities.BnetClient, character models.Character) (types.CharacterProfileSummary, types.CharacterEquipment, error) {
+	profile, err := client.GetCharacterProfile(character.Region, character.Realm, character.Name)
+	if err != nil {
+		return profile, types.CharacterEquipment{}, fmt.Errorf("fetching profile: %w", err)
+	}
+	equipment, err := client.GetCharacterEquipment(character.Region, character.Realm, character.Name)
+	if err != nil {
+		return profile, equipment, fmt.Errorf("fetching equipment: %w", err)
+	}
+	return profile, equipment, nil
+}
+
+// gearSyncPlan is what one character's sync decided, before anything is
+// written: the specialization to fill in (only when the character has
+// none), the tier slots to move and to what, and everything else reported
+// as a mismatch.
+type gearSyncPlan struct {
+	SpecializationID *uint
+	SlotSources      map[string]string
+	Mismatches       []models.GearSyncMismatch
+	ItemLevel        uint
+}
+
+// planGearSync compares what the API reports against the character's
+// current spec and tier slots. It only ever moves a slot to what's actually
+// equipped — a tracked piece that isn't equipped (in bags, swapped out for
+// a sim) is flagged, not erased.
+func planGearSync(character models.Character, specs []models.Specialization, slots []models.CharacterTierSlot, season models.Season, profile types.CharacterProfileSummary, equipment types.CharacterEquipment) gearSyncPlan {
+	plan := gearSyncPlan{SlotSources: map[string]string{}, Mismatches: []models.GearSyncMismatch{}}
+
+	if character.Class != "" && utilities.NameMatchKey(character.Class) != utilities.NameMatchKey(profile.CharacterClass.Name) {
+		plan.Mismatches = append(plan.Mismatches, models.GearSyncMismatch{Kind: models.GearMismatchClass, Expected: character.Class, Actual: profile.CharacterClass.Name})
+	}
+
+	var activeSpec *models.Specialization
+	for i, s := range specs {
+		if utilities.NameMatchKey(s.Class.Name) == utilities.NameMatchKey(profile.CharacterClass.Name) &&
+			utilities.NameMatchKey(s.Name) == utilities.NameMatchKey(profile.ActiveSpec.Name) {
+			activeSpec = &specs[i]
+			break
+		}
+	}
+	if activeSpec != nil {
+		if character.SpecializationID == nil {
+			plan.SpecializationID = &activeSpec.ID
+		} else if *character.SpecializationID != activeSpec.ID {
+			// Deliberately not overwritten — a character's loot spec in
+			// the app can legitimately differ from whatever they last
+			// logged out in.
+			expected := ""
+			for _, s := range specs {
+				if s.ID == *character.SpecializationID {
+					expected = s.Name
+				}
+			}
+			plan.Mismatches = append(plan.Mismatches, models.GearSyncMismatch{Kind: models.GearMismatchSpec, Expected: expected, Actual: activeSpec.Name})
+		}
+	}
+
+	equippedTier := make(map[string]types.EquippedItem)
+	var levelSum, levelCount int
+	for _, item := range equipment.EquippedItems {
+		if item.Slot.Type == "SHIRT" || item.Slot.Type == "TABARD" {
+			continue
+		}
+		levelSum += item.Level.Value
+		levelCount++
+		if slot, ok := bnetEquipmentTierSlots[item.Slot.Type]; ok && item.Set != nil {
+			equippedTier[slot] = item
+		}
+	}
+	if levelCount > 0 {
+		plan.ItemLevel = uint(levelSum / levelCount)
+	}
+
+	currentSources := make(map[string]string, len(slots))
+	for _, s := range slots {
+		currentSources[s.Slot] = s.Source
+	}
+
+	for _, slot := range tierSlotOrder {
+		currentSource, exists := currentSources[slot]
+		if !exists {
+			currentSource = models.TierSourceNone
+		}
+
+		item, equipped := equippedTier[slot]
+		if !equipped {
+			if models.TierSourceCountsTowardSet(currentSource) {
+				plan.Mismatches = append(plan.Mismatches, models.GearSyncMismatch{Kind: models.GearMismatchTierSlot, Slot: slot, Expected: currentSource, Actual: models.TierSourceNone})
+			}
+			continue
+		}
+
+		track := tierTrackFromDescription(item)
+		if track == "" {
+			track = season.TierSourceForItemLevel(uint(item.Level.Value))
+		}
+		if track == "" {
+			plan.Mismatches = append(plan.Mismatches, models.GearSyncMismatch{Kind: models.GearMismatchUnknownTrack, Slot: slot, Expected: currentSource, Actual: fmt.Sprintf("%s (item level %d)", item.Name, item.Level.Value)})
+			continue
+		}
+		if track != currentSource {
+			plan.SlotSources[slot] = track
+		}
+	}
+	return plan
+}
+
+// syncCharacterGear fetches one character's profile and equipment, writes
+// the tier slot sources the API can vouch for, fills in a missing
+// specialization, and records everything else it disagrees with as
+// mismatches on the character's CharacterGearSync row (see planGearSync).
+func syncCharacterGear(client *utilities.BnetClient, character models.Character, season models.Season) (models.CharacterGearSync, error) {
+	var status models.CharacterGearSync
+	database.DB.Where("character_id = ?", character.ID).First(&status)
+	status.CharacterID = character.ID
+
+	fail := func(err error) (models.CharacterGearSync, error) {
+		status.Error = gearSyncErrorMessage(err)
+		if saveErr := database.DB.Save(&status).Error; saveErr != nil {
+			log.Printf("Gear sync: saving status for character %d: %v", character.ID, saveErr)
+		}
+		return status, err
+	}
+
+	profile, equipment, err := fetchCharacterGear(client, character)
+	if err != nil {
+		return fail(err)
+	}
+
+	var specs []models.Specialization
+	database.DB.Preload("Class").Find(&specs)
+	var slots []models.CharacterTierSlot
+	database.DB.Where("character_id = ?", character.ID).Find(&slots)
+
+	plan := planGearSync(character, specs, slots, season, profile, equipment)
+
+	if plan.SpecializationID != nil {
+		if err := database.DB.Model(&models.Character{}).Where("id = ?", character.ID).Update("specialization_id", *plan.SpecializationID).Error; err != nil {
+			log.Printf("Gear sync: setting specialization for character %d: %v", character.ID, err)
+		}
+	}
+
+	slotRows := make(map[string]models.CharacterTierSlot, len(slots))
+	for _, s := range slots {
+		slotRows[s.Slot] = s
+	}
+	for _, slot := range tierSlotOrder {
+		source, changed := plan.SlotSources[slot]
+		if !changed {
+			continue
+		}
+		tierSlot, exists := slotRows[slot]
+		previousSource := models.TierSourceNone
+		if exists {
+			previousSource = tierSlot.Source
+		} else {
+			tierSlot = models.CharacterTierSlot{CharacterID: character.ID, Slot: slot}
+		}
+		tierSlot.Source = source
+		if err := saveCharacterTierSlot(&tierSlot, previousSource, models.TierChangeSync, nil); err != nil {
+			log.Printf("Gear sync: saving %s tier slot for character %d: %v", slot, character.ID, err)
+		}
+	}
+
+	mismatchesJSON, err := json.Marshal(plan.Mismatches)
+	if err != nil {
+		return fail(fmt.Errorf("encoding mismatches: %w", err))
+	}
+	now := time.Now()
+	status.LastSyncedAt = &now
+	status.Error = ""
+	status.ActiveSpec = profile.ActiveSpec.Name
+	status.ItemLevel = plan.ItemLevel
+	status.Mismatches = mismatchesJSON
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "character_id"}},
+		UpdateAll: true,
+	}).Save(&status).Error; err != nil {
+		return status, fmt.Errorf("saving gear sync status: %w", err)
+	}
+	return status, nil
+}
+
+// GetTeamGearSync returns the latest gear-sync result for every character
+// on the team's roster (characters never synced are omitted). Visible to
+// every team member.
+func GetTeamGearSync(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	characters := teamRosterCharacters(uint(teamId))
+	characterIds := make([]uint, len(characters))
+	for i, ch := range characters {
+		characterIds[i] = ch.ID
+	}
+
+	statuses := []models.CharacterGearSync{}
+	if len(characterIds) > 0 {
+		database.DB.Where("character_id IN ?", characterIds).Order("character_id").Find(&statuses)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"gear_sync": statuses, "enabled": gearSyncClient != nil})
+}
+
+// SyncTeamGear runs the gear sync for the team's roster right away instead
+// of waiting for the next background pass — e.g. straight after raid.
+// Owner/admin only, since it spends the app's shared API quota. Returns 409
+// while another sync is working through this team's roster rather than
+// overlapping it; syncs of other teams don't get in the way.
+func SyncTeamGear(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if gearSyncClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "gear sync is not configured"})
+		return
+	}
+
+	var season models.Season
+	if err := database.DB.Where("is_current = ?", true).First(&season).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load season"})
+		return
+	}
+
+	lock := gearSyncTeamLock(uint(teamId))
+	if !lock.TryLock() {
+		c.JSON(http.StatusConflict, gin.H{"error": "a gear sync is already running for this team, try again shortly"})
+		return
+	}
+	defer lock.Unlock()
+
+	statuses := []models.CharacterGearSync{}
+	for _, ch := range teamRosterCharacters(uint(teamId)) {
+		status, err := syncCharacterGear(gearSyncClient, ch, season)
+		if err != nil {
+			log.Printf("Gear sync: character %d: %v", ch.ID, err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"gear_sync": statuses})
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"krankenprep/models"
+	"krankenprep/utilities"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// stubBnetEquipment is one equipped item as the profile API returns it.
+func stubBnetEquipment(slot string, level int, set bool, description string) map[string]any {
+	item := map[string]any{
+		"item":  map[string]any{"id": 1},
+		"slot":  map[string]any{"type": slot, "name": slot},
+		"name":  slot + " piece",
+		"level": map[string]any{"value": level},
+	}
+	if set {
+		item["set"] = map[string]any{"item_set": map[string]any{"name": "Tier Set", "id": 1}}
+	}
+	if description != "" {
+		item["name_description"] = map[string]any{"display_string": description}
+	}
+	return item
+}
+
+// newStubBnet serves a token endpoint and the two profile documents the
+// gear sync reads, for Thrall on Mal'Ganis only — "gone" is a 404 and
+// "broken" a 500. tokenRequests counts calls to the token endpoint.
+func newStubBnet(t *testing.T, tokenRequests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "stub-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("/profile/wow/character/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer stub-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got := r.URL.Query().Get("namespace"); got != "profile-us" {
+			t.Errorf("namespace = %q, want profile-us", got)
+		}
+		switch r.URL.Path {
+		case "/profile/wow/character/malganis/thrall":
+			json.NewEncoder(w).Encode(map[string]any{
+				"name":            "Thrall",
+				"character_class": map[string]any{"name": "Shaman", "id": 7},
+				"active_spec":     map[string]any{"name": "Enhancement", "id": 263},
+			})
+		case "/profile/wow/character/malganis/thrall/equipment":
+			json.NewEncoder(w).Encode(map[string]any{"equipped_items": []any{
+				stubBnetEquipment("HEAD", 665, true, ""),
+				stubBnetEquipment("CHEST", 672, true, "Mythic 2/6"),
+				stubBnetEquipment("HANDS", 660, true, "Heroic"),
+				stubBnetEquipment("LEGS", 659, false, ""),
+				stubBnetEquipment("SHIRT", 1, false, ""),
+			}})
+		case "/profile/wow/character/malganis/broken", "/profile/wow/character/malganis/broken/equipment":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newStubBnetClient(server *httptest.Server) *utilities.BnetClient {
+	return &utilities.BnetClient{
+		APIBaseURL:   server.URL,
+		TokenURL:     server.URL + "/token",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		HTTPClient:   server.Client(),
+	}
+}
+
+func TestGearSyncAgainstStubbedBattleNet(t *testing.T) {
+	var tokenRequests atomic.Int32
+	client := newStubBnetClient(newStubBnet(t, &tokenRequests))
+
+	shaman := models.Class{ID: 7, Name: "Shaman"}
+	specs := []models.Specialization{
+		{ID: 70, Name: "Restoration", ClassID: 7, Class: shaman},
+		{ID: 71, Name: "Enhancement", ClassID: 7, Class: shaman},
+	}
+	season := models.Season{ChampionItemLevel: 645, HeroItemLevel: 658, MythItemLevel: 671}
+	slots := []models.CharacterTierSlot{
+		{CharacterID: 1, Slot: models.TierSlotGloves, Source: models.TierSourceHero},
+		{CharacterID: 1, Slot: models.TierSlotLegs, Source: models.TierSourceChampion},
+	}
+	restoration := uint(70)
+
+	tests := []struct {
+		name           string
+		character      models.Character
+		season         models.Season
+		wantSpec       *uint
+		wantSlots      map[string]string
+		wantMismatches []models.GearSyncMismatch
+	}{
+		{
+			name:      "updates tier slots and fills in a missing spec",
+			character: models.Character{ID: 1, Name: "Thrall", Realm: "Mal'Ganis", Region: "na", Class: "Shaman"},
+			season:    season,
+			wantSpec:  &specs[1].ID,
+			wantSlots: map[string]string{
+				models.TierSlotHead:  models.TierSourceHero,
+				models.TierSlotChest: models.TierSourceMyth,
+			},
+			wantMismatches: []models.GearSyncMismatch{
+				{Kind: models.GearMismatchTierSlot, Slot: models.TierSlotLegs, Expected: models.TierSourceChampion, Actual: models.TierSourceNone},
+			},
+		},
+		{
+			name:      "reports class and spec disagreements without overwriting",
+			character: models.Character{ID: 1, Name: "Thrall", Realm: "Mal'Ganis", Region: "na", Class: "Warrior", SpecializationID: &restoration},
+			season:    season,
+			wantSlots: map[string]string{
+				models.TierSlotHead:  models.TierSourceHero,
+				models.TierSlotChest: models.TierSourceMyth,
+			},
+			wantMismatches: []models.GearSyncMismatch{
+				{Kind: models.GearMismatchClass, Expected: "Warrior", Actual: "Shaman"},
+				{Kind: models.GearMismatchSpec, Expected: "Restoration", Actual: "Enhancement"},
+				{Kind: models.GearMismatchTierSlot, Slot: models.TierSlotLegs, Expected: models.TierSourceChampion, Actual: models.TierSourceNone},
+			},
+		},
+		{
+			name:      "flags an unknown track when the season has no thresholds",
+			character: models.Character{ID: 1, Name: "Thrall", Realm: "Mal'Ganis", Region: "na", Class: "Shaman", SpecializationID: &specs[1].ID},
+			season:    models.Season{},
+			wantSlots: map[string]string{
+				models.TierSlotChest: models.TierSourceMyth,
+			},
+			wantMismatches: []models.GearSyncMismatch{
+				{Kind: models.GearMismatchUnknownTrack, Slot: models.TierSlotHead, Expected: models.TierSourceNone, Actual: "HEAD piece (item level 665)"},
+				{Kind: models.GearMismatchTierSlot, Slot: models.TierSlotLegs, Expected: models.TierSourceChampion, Actual: models.TierSourceNone},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, equipment, err := fetchCharacterGear(client, tt.character)
+			if err != nil {
+				t.Fatalf("fetchCharacterGear: %v", err)
+			}
+			plan := planGearSync(tt.character, specs, slots, tt.season, profile, equipment)
+
+			if !reflect.DeepEqual(plan.SpecializationID, tt.wantSpec) {
+				t.Errorf("SpecializationID = %v, want %v", plan.SpecializationID, tt.wantSpec)
+			}
+			if !reflect.DeepEqual(plan.SlotSources, tt.wantSlots) {
+				t.Errorf("SlotSources = %v, want %v", plan.SlotSources, tt.wantSlots)
+			}
+			if !reflect.DeepEqual(plan.Mismatches, tt.wantMismatches) {
+				t.Errorf("Mismatches = %+v, want %+v", plan.Mismatches, tt.wantMismatches)
+			}
+			// Shirt excluded: (665 + 672 + 660 + 659) / 4.
+			if plan.ItemLevel != 664 {
+				t.Errorf("ItemLevel = %d, want 664", plan.ItemLevel)
+			}
+		})
+	}
+
+	if got := tokenRequests.Load(); got != 1 {
+		t.Errorf("token requested %d times, want 1 (cached across calls)", got)
+	}
+}
+
+func TestGearSyncFailures(t *testing.T) {
+	var tokenRequests atomic.Int32
+	server := newStubBnet(t, &tokenRequests)
+
+	badCredentials := newStubBnetClient(server)
+	badCredentials.ClientSecret = "wrong"
+
+	tests := []struct {
+		name         string
+		client       *utilities.BnetClient
+		character    models.Character
+		wantNotFound bool
+		wantMessage  string
+	}{
+		{
+			name:         "unknown character",
+			client:       newStubBnetClient(server),
+			character:    models.Character{Name: "Gone", Realm: "Mal'Ganis", Region: "na"},
+			wantNotFound: true,
+			wantMessage:  "character not found on Battle.net",
+		},
+		{
+			name:        "server error",
+			client:      newStubBnetClient(server),
+			character:   models.Character{Name: "Broken", Realm: "Mal'Ganis", Region: "na"},
+			wantMessage: "fetching profile: battle.net profile: unexpected status 500",
+		},
+		{
+			name:        "rejected credentials",
+			client:      badCredentials,
+			character:   models.Character{Name: "Thrall", Realm: "Mal'Ganis", Region: "na"},
+			wantMessage: "battle.net token: unexpected status 401",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := fetchCharacterGear(tt.client, tt.character)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Is(err, utilities.ErrBnetNotFound); got != tt.wantNotFound {
+				t.Errorf("errors.Is(err, ErrBnetNotFound) = %v, want %v", got, tt.wantNotFound)
+			}
+			if message := gearSyncErrorMessage(err); !strings.Contains(message, tt.wantMessage) {
+				t.Errorf("gearSyncErrorMessage = %q, want it to contain %q", message, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// goldTransactionSigns is the direction each manually recorded type moves
+// gold: payloads always carry a positive amount and the sign comes from
+// here. 0 means either way (the payload's own sign). GoldTxBoeSale is
+// absent — it can't be recorded by hand.
+var goldTransactionSigns = map[string]float64{
+	models.GoldTxConsumables: -1,
+	models.GoldTxRepairs:     -1,
+	models.GoldTxPayout:      -1,
+	models.GoldTxDonation:    1,
+	models.GoldTxAdjustment:  0,
+}
+
+// goldLedgerEntry is one line of the ledger — a GoldTransaction or a BoE
+// sale's guild cut. Balance is the guild's balance after this entry,
+// counted from the team's very first entry regardless of season.
+type goldLedgerEntry struct {
+	Date          time.Time `json:"date"`
+	SeasonID      uint      `json:"season_id"`
+	Type          string    `json:"type"`
+	Category      string    `json:"category"`
+	Description   string    `json:"description"`
+	Amount        float64   `json:"amount"`
+	Balance       float64   `json:"balance"`
+	TransactionID *uint     `json:"transaction_id"`
+	BoeSaleID     *uint     `json:"boe_sale_id"`
+}
+
+// teamGoldLedger builds the team's full ledger, oldest first, with running
+// balances: every GoldTransaction plus the guild cut of every BoE sale
+// (under the split rule it was made under). Sales whose guild cut is zero,
+// e.g. waived player sales, are left out — they never moved guild gold.
+func teamGoldLedger(teamId uint) ([]goldLedgerEntry, error) {
+	var transactions []models.GoldTransaction
+	if err := database.DB.Where("team_id = ?", teamId).Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("fetching gold transactions: %w", err)
+	}
+	var sales []models.BoeSale
+	if err := database.DB.Where("team_id = ?", teamId).Find(&sales).Error; err != nil {
+		return nil, fmt.Errorf("fetching BoE sales: %w", err)
+	}
+	rules, err := teamBoeSplitRules(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]goldLedgerEntry, 0, len(transactions)+len(sales))
+	for _, t := range transactions {
+		id := t.ID
+		entries = append(entries, goldLedgerEntry{
+			Date:          t.OccurredAt,
+			SeasonID:      t.SeasonID,
+			Type:          t.Type,
+			Category:      t.Category,
+			Description:   t.Description,
+			Amount:        t.Amount,
+			TransactionID: &id,
+		})
+	}
+	for _, s := range sales {
+		cut := s.GuildCut(rules.At(s.CreatedAt))
+		if cut == 0 {
+			continue
+		}
+		id := s.ID
+		entries = append(entries, goldLedgerEntry{
+			Date:        s.CreatedAt,
+			SeasonID:    s.SeasonID,
+			Type:        models.GoldTxBoeSale,
+			Category:    s.ItemSlot,
+			Description: fmt.Sprintf("%s sold by %s", s.ItemName, s.PlayerName),
+			Amount:      cut,
+			BoeSaleID:   &id,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date) })
+	balance := 0.0
+	for i := range entries {
+		balance += entries[i].Amount
+		entries[i].Balance = balance
+	}
+	return entries, nil
+}
+
+// GetGoldLedger returns the team's gold ledger newest first, optionally for
+// one season (season_id) and/or type, with each entry's running balance.
+// Visible to every team member, same as BoE sales.
+func GetGoldLedger(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var seasonId uint
+	if q := c.Query("season_id"); q != "" {
+		parsed, err := strconv.ParseUint(q, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+			return
+		}
+		seasonId = uint(parsed)
+	}
+	txType := c.Query("type")
+
+	entries, err := teamGoldLedger(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load gold ledger"})
+		return
+	}
+
+	filtered := []goldLedgerEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if (seasonId != 0 && e.SeasonID != seasonId) || (txType != "" && e.Type != txType) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": filtered})
+}
+
+type goldTypeTotal struct {
+	Type     string  `json:"type"`
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+}
+
+type goldSeasonBalance struct {
+	SeasonID   uint    `json:"season_id"`
+	SeasonName string  `json:"season_name"`
+	Income     float64 `json:"income"`
+	Expenses   float64 `json:"expenses"`
+	Net        float64 `json:"net"`
+	// OpeningBalance/ClosingBalance are the running guild balance before
+	// the season's first entry and after its last.
+	OpeningBalance float64         `json:"opening_balance"`
+	ClosingBalance float64         `json:"closing_balance"`
+	ByCategory     []goldTypeTotal `json:"by_category"`
+}
+
+// GetGoldBalance returns the guild's current running balance plus a
+// per-season breakdown — income, expenses, net, opening/closing balance and
+// totals per type and category. Seasons are ordered by their first entry.
+// Visible to every team member.
+func GetGoldBalance(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entries, err := teamGoldLedger(uint(teamId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load gold ledger"})
+		return
+	}
+
+	var seasons []models.Season
+	database.DB.Find(&seasons)
+	seasonNames := make(map[uint]string, len(seasons))
+	for _, s := range seasons {
+		seasonNames[s.Id] = s.Name
+	}
+
+	type categoryKey struct{ txType, category string }
+	bySeason := make(map[uint]*goldSeasonBalance)
+	categories := make(map[uint]map[categoryKey]float64)
+	seasonOrder := []uint{}
+	for _, e := range entries {
+		b := bySeason[e.SeasonID]
+		if b == nil {
+			b = &goldSeasonBalance{SeasonID: e.SeasonID, SeasonName: seasonNames[e.SeasonID], OpeningBalance: e.Balance - e.Amount}
+			bySeason[e.SeasonID] = b
+			categories[e.SeasonID] = make(map[categoryKey]float64)
+			seasonOrder = append(seasonOrder, e.SeasonID)
+		}
+		if e.Amount >= 0 {
+			b.Income += e.Amount
+		} else {
+			b.Expenses -= e.Amount
+		}
+		b.Net += e.Amount
+		b.ClosingBalance = e.Balance
+		categories[e.SeasonID][categoryKey{e.Type, e.Category}] += e.Amount
+	}
+
+	balances := make([]goldSeasonBalance, 0, len(seasonOrder))
+	for _, id := range seasonOrder {
+		b := bySeason[id]
+		b.ByCategory = []goldTypeTotal{}
+		for key, amount := range categories[id] {
+			b.ByCategory = append(b.ByCategory, goldTypeTotal{Type: key.txType, Category: key.category, Amount: amount})
+		}
+		sort.Slice(b.ByCategory, func(i, j int) bool {
+			if b.ByCategory[i].Type != b.ByCategory[j].Type {
+				return b.ByCategory[i].Type < b.ByCategory[j].Type
+			}
+			return b.ByCategory[i].Category < b.ByCategory[j].Category
+		})
+		balances = append(balances, *b)
+	}
+
+	balance := 0.0
+	if len(entries) > 0 {
+		balance = entries[len(entries)-1].Balance
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balance": balance, "seasons": balances})
+}
+
+type goldTransactionPayload struct {
+	Type        string `json:"type"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	// Amount is positive; the sign comes from Type (see
+	// goldTransactionSigns), except for adjustments, which keep their own.
+	Amount float64 `json:"amount"`
+	// OccurredAt defaults to now, and decides which season the transaction
+	// counts toward.
+	OccurredAt *time.Time `json:"occurred_at"`
+}
+
+// applyGoldTransactionPayload validates the payload onto t, resolving the
+// season from the date. Writes a 400 and returns false if invalid.
+func applyGoldTransactionPayload(c *gin.Context, t *models.GoldTransaction, payload goldTransactionPayload) bool {
+	sign, valid := goldTransactionSigns[payload.Type]
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction type"})
+		return false
+	}
+	if sign != 0 && payload.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be positive"})
+		return false
+	}
+	if payload.Amount == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount can't be zero"})
+		return false
+	}
+
+	t.OccurredAt = time.Now()
+	if payload.OccurredAt != nil {
+		t.OccurredAt = *payload.OccurredAt
+	}
+	var seasons []models.Season
+	database.DB.Order("start_date").Find(&seasons)
+	if season, found := seasonForDate(seasons, t.OccurredAt); found {
+		t.SeasonID = season.Id
+	} else if id, ok := currentSeasonID(); ok && payload.OccurredAt == nil {
+		t.SeasonID = id
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no season covers that date"})
+		return false
+	}
+
+	t.Type = payload.Type
+	t.Category = payload.Category
+	t.Description = payload.Description
+	t.Amount = payload.Amount
+	if sign != 0 {
+		t.Amount = sign * payload.Amount
+	}
+	return true
+}
+
+// CreateGoldTransaction records a manual ledger entry. Loot-council/admin/
+// owner only, same as BoE sales.
+func CreateGoldTransaction(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload goldTransactionPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	transaction := models.GoldTransaction{TeamID: uint(teamId), CreatedByUserID: user.ID}
+	if !applyGoldTransactionPayload(c, &transaction, payload) {
+		return
+	}
+	if err := database.DB.Create(&transaction).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// UpdateGoldTransaction edits a manual ledger entry. BoE income can't be
+// edited here — edit the sale instead. Loot-council/admin/owner only.
+func UpdateGoldTransaction(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	transactionId, err := strconv.ParseUint(c.Param("transactionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload goldTransactionPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	var transaction models.GoldTransaction
+	if err := database.DB.Where("id = ? AND team_id = ?", transactionId, teamId).First(&transaction).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query transaction"})
+		return
+	}
+
+	if !applyGoldTransactionPayload(c, &transaction, payload) {
+		return
+	}
+	if err := database.DB.Save(&transaction).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+func DeleteGoldTransaction(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	transactionId, err := strconv.ParseUint(c.Param("transactionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := database.DB.Where("id = ? AND team_id = ?", transactionId, teamId).Delete(&models.GoldTransaction{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
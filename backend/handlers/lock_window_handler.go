@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// activeWishlistLock reports whether any of the team's lock windows covers
+// now, and the latest end among those that do (nil if any of them is
+// open-ended) — overlapping windows lock until the last one lets go.
+func activeWishlistLock(teamId uint, now time.Time) (locked bool, until *time.Time, err error) {
+	var windows []models.WishlistLockWindow
+	if err := database.DB.Where("team_id = ?", teamId).Find(&windows).Error; err != nil {
+		return false, nil, fmt.Errorf("fetching lock windows: %w", err)
+	}
+	openEnded := false
+	for _, w := range windows {
+		active, windowUntil := w.ActiveAt(now)
+		if !active {
+			continue
+		}
+		locked = true
+		if windowUntil == nil {
+			openEnded = true
+		} else if until == nil || windowUntil.After(*until) {
+			until = windowUntil
+		}
+	}
+	if openEnded {
+		until = nil
+	}
+	return locked, until, nil
+}
+
+// checkWishlistLock gates every wishlist edit (wishes, priorities, bonus
+// rolls) on the team's lock windows. Outside a lock it's a no-op. Inside
+// one, loot council/admin/owner may still edit — lockOverride=true tells the
+// caller to flag its audit entries as such — and everyone else gets a 423
+// written for them and ok=false.
+func checkWishlistLock(c *gin.Context, teamId uint, userId uint) (lockOverride bool, ok bool) {
+	locked, until, err := activeWishlistLock(teamId, time.Now())
+	if err != nil {
+		log.Printf("Error checking wishlist lock: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check wishlist lock"})
+		return false, false
+	}
+	if !locked {
+		return false, true
+	}
+	if isLootCouncilOrAdmin(teamId, userId) {
+		return true, true
+	}
+
+	message := "Wishlists are locked for raid — ask loot council to make changes"
+	if until != nil {
+		message = fmt.Sprintf("Wishlists are locked for raid until %s — ask loot council to make changes", until.Format("Mon Jan 2 15:04 MST"))
+	}
+	c.JSON(http.StatusLocked, gin.H{"error": message, "locked_until": until})
+	return false, false
+}
+
+type wishlistLockStatus struct {
+	Locked      bool       `json:"locked"`
+	LockedUntil *time.Time `json:"locked_until"`
+}
+
+// GetWishlistLockWindows returns the team's configured lock windows plus
+// whether wishlists are locked right now, so the UI can disable editing
+// up front instead of waiting for a 423.
+func GetWishlistLockWindows(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var windows []models.WishlistLockWindow
+	database.DB.Where("team_id = ?", teamId).Order("id").Find(&windows)
+
+	locked, until, err := activeWishlistLock(uint(teamId), time.Now())
+	if err != nil {
+		log.Printf("Error checking wishlist lock: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check wishlist lock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"lock_windows": windows,
+		"status":       wishlistLockStatus{Locked: locked, LockedUntil: until},
+	})
+}
+
+type lockWindowPayload struct {
+	Kind         string       `json:"kind"`
+	Label        string       `json:"label"`
+	StartWeekday time.Weekday `json:"start_weekday"`
+	StartTime    string       `json:"start_time"`
+	EndWeekday   time.Weekday `json:"end_weekday"`
+	EndTime      string       `json:"end_time"`
+	Timezone     string       `json:"timezone"`
+	StartsAt     *time.Time   `json:"starts_at"`
+	EndsAt       *time.Time   `json:"ends_at"`
+}
+
+// CreateWishlistLockWindow adds a recurring or manual lock window. A
+// recurring window without a timezone defaults to the team region's server
+// time; a manual window without starts_at locks immediately.
+// Loot-council/admin/owner only.
+func CreateWishlistLockWindow(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload lockWindowPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	window := models.WishlistLockWindow{
+		TeamID:          uint(teamId),
+		Kind:            payload.Kind,
+		Label:           payload.Label,
+		CreatedByUserID: user.ID,
+	}
+
+	switch payload.Kind {
+	case models.LockWindowRecurring:
+		if payload.StartWeekday < time.Sunday || payload.StartWeekday > time.Saturday ||
+			payload.EndWeekday < time.Sunday || payload.EndWeekday > time.Saturday {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "weekdays must be 0 (Sunday) through 6 (Saturday)"})
+			return
+		}
+		if _, err := models.ParseClockTime(payload.StartTime); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_time must be HH:MM"})
+			return
+		}
+		if _, err := models.ParseClockTime(payload.EndTime); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_time must be HH:MM"})
+			return
+		}
+		if payload.StartWeekday == payload.EndWeekday && payload.StartTime == payload.EndTime {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lock window must not start and end at the same time"})
+			return
+		}
+		timezone := payload.Timezone
+		if timezone == "" {
+			var team models.Team
+			if err := database.DB.First(&team, teamId).Error; err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+				return
+			}
+			timezone = models.DefaultTimezoneForRegion(team.Region)
+		}
+		if _, err := time.LoadLocation(timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timezone"})
+			return
+		}
+		window.StartWeekday = payload.StartWeekday
+		window.StartTime = payload.StartTime
+		window.EndWeekday = payload.EndWeekday
+		window.EndTime = payload.EndTime
+		window.Timezone = timezone
+	case models.LockWindowManual:
+		startsAt := time.Now()
+		if payload.StartsAt != nil {
+			startsAt = *payload.StartsAt
+		}
+		if payload.EndsAt != nil && !payload.EndsAt.After(startsAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+			return
+		}
+		window.StartsAt = &startsAt
+		window.EndsAt = payload.EndsAt
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be recurring or manual"})
+		return
+	}
+
+	if err := database.DB.Create(&window).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save lock window"})
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// DeleteWishlistLockWindow removes a lock window — also how a manual
+// open-ended lock gets lifted. Loot-council/admin/owner only.
+func DeleteWishlistLockWindow(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	lockWindowId, err := strconv.ParseUint(c.Param("lockWindowId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lock window ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := database.DB.Where("id = ? AND team_id = ?", lockWindowId, teamId).Delete(&models.WishlistLockWindow{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete lock window"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var validAuditEventTypes = map[string]bool{
+	models.AuditEventItemWished:       true,
+	models.AuditEventItemUnwished:     true,
+	models.AuditEventItemObtained:     true,
+	models.AuditEventItemUnobtained:   true,
+	models.AuditEventPrioritySet:      true,
+	models.AuditEventBonusRollAdded:   true,
+	models.AuditEventBonusRollRemoved: true,
+	models.AuditEventEPAwarded:        true,
+	models.AuditEventGPCharged:        true,
+	models.AuditEventEPGPAdjusted:     true,
+	models.AuditEventEPGPDecayed:      true,
+}
+
+// lootAuditExportBatchSize bounds how many rows ExportLootAuditLog holds in
+// memory at once — a full season's log runs to thousands of rows.
+const lootAuditExportBatchSize = 500
+
+// parseAuditLogDate accepts either a bare date (2006-01-02) or a full
+// RFC3339 timestamp. isDateOnly lets "to" filters treat a bare date as
+// inclusive of that whole day.
+func parseAuditLogDate(value string) (t time.Time, isDateOnly bool, err error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// applyLootAuditLogFilters narrows a team-scoped LootAuditLog query by the
+// shared filter query params — event_type (comma-separated), character_id,
+// boss_id, difficulty, acting_user_id, and a from/to date range. Shared by
+// GetLootAuditLog, ExportLootAuditLog and GetLootAuditLogSummary so all three
+// always agree on what a filter means. Writes a 400 and returns ok=false for
+// a malformed event type, difficulty or date; the numeric ID filters are
+// ignored when unparseable, same as they always have been.
+func applyLootAuditLogFilters(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	if eventTypes := c.Query("event_type"); eventTypes != "" {
+		types := strings.Split(eventTypes, ",")
+		for i, t := range types {
+			types[i] = strings.TrimSpace(t)
+			if !validAuditEventTypes[types[i]] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid event_type '%s'", types[i])})
+				return nil, false
+			}
+		}
+		query = query.Where("event_type IN ?", types)
+	}
+	if characterId := c.Query("character_id"); characterId != "" {
+		if parsed, err := strconv.ParseUint(characterId, 10, 32); err == nil {
+			query = query.Where("character_id = ?", parsed)
+		}
+	}
+	if bossId := c.Query("boss_id"); bossId != "" {
+		if parsed, err := strconv.ParseUint(bossId, 10, 32); err == nil {
+			query = query.Where("boss_id = ?", parsed)
+		}
+	}
+	if actingUserId := c.Query("acting_user_id"); actingUserId != "" {
+		if parsed, err := strconv.ParseUint(actingUserId, 10, 32); err == nil {
+			query = query.Where("acting_user_id = ?", parsed)
+		}
+	}
+	if difficultyParam := c.Query("difficulty"); difficultyParam != "" {
+		difficulty, ok := getDifficulty(c, difficultyParam)
+		if !ok {
+			return nil, false
+		}
+		query = query.Where("difficulty = ?", difficulty)
+	}
+	if from := c.Query("from"); from != "" {
+		t, _, err := parseAuditLogDate(from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date (YYYY-MM-DD) or RFC3339 timestamp"})
+			return nil, false
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, isDateOnly, err := parseAuditLogDate(to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date (YYYY-MM-DD) or RFC3339 timestamp"})
+			return nil, false
+		}
+		if isDateOnly {
+			query = query.Where("created_at < ?", t.AddDate(0, 0, 1))
+		} else {
+			query = query.Where("created_at <= ?", t)
+		}
+	}
+	return query, true
+}
+
+// auditLogCSVRecord flattens one entry into ExportLootAuditLog's CSV column
+// order — nullable columns are written as empty cells.
+func auditLogCSVRecord(e models.LootAuditLog) []string {
+	itemId, itemName, value := "", "", ""
+	if e.ItemID != nil {
+		itemId = strconv.FormatUint(uint64(*e.ItemID), 10)
+	}
+	if e.ItemName != nil {
+		itemName = *e.ItemName
+	}
+	if e.Value != nil {
+		value = strconv.FormatUint(uint64(*e.Value), 10)
+	}
+	formatPoints := func(points *float64) string {
+		if points == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*points, 'f', -1, 64)
+	}
+	return []string{
+		strconv.FormatUint(uint64(e.ID), 10),
+		e.CreatedAt.UTC().Format(time.RFC3339),
+		e.EventType,
+		strconv.FormatUint(uint64(e.ActingUserID), 10),
+		e.ActingUserBTag,
+		strconv.FormatUint(uint64(e.CharacterID), 10),
+		e.CharacterName,
+		strconv.FormatUint(uint64(e.BossID), 10),
+		e.BossName,
+		e.Difficulty,
+		itemId,
+		itemName,
+		value,
+		formatPoints(e.EPDelta),
+		formatPoints(e.GPDelta),
+		formatPoints(e.DecayPercent),
+	}
+}
+
+var auditLogCSVHeader = []string{
+	"id", "created_at", "event_type", "acting_user_id", "acting_user_btag",
+	"character_id", "character_name", "boss_id", "boss_name", "difficulty",
+	"item_id", "item_name", "value", "ep_delta", "gp_delta", "decay_percent",
+}
+
+// ExportLootAuditLog streams every audit entry matching the shared filters
+// as a file download, oldest first — format=csv (default) or format=jsonl.
+// Rows are read in id-keyed batches rather than all at once so a whole
+// season's log never has to sit in memory. Loot-council/admin/owner only,
+// same as GetLootAuditLog.
+func ExportLootAuditLog(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or jsonl"})
+		return
+	}
+
+	// Validate filters up front, before any of the response is written.
+	if _, ok := applyLootAuditLogFilters(c, database.DB.Model(&models.LootAuditLog{})); !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("loot-audit-log-team-%d.%s", teamId, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv")
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+	}
+	c.Status(http.StatusOK)
+
+	csvWriter := csv.NewWriter(c.Writer)
+	jsonEncoder := json.NewEncoder(c.Writer)
+	if format == "csv" {
+		csvWriter.Write(auditLogCSVHeader)
+	}
+
+	var afterId uint
+	for {
+		query, _ := applyLootAuditLogFilters(c, database.DB.Where("team_id = ? AND id > ?", teamId, afterId))
+		var batch []models.LootAuditLog
+		if err := query.Order("id ASC").Limit(lootAuditExportBatchSize).Find(&batch).Error; err != nil {
+			// Headers are already sent, so the best we can do is stop and
+			// leave a truncated file rather than append a JSON error to it.
+			log.Printf("Error exporting loot audit log: %v", err)
+			break
+		}
+		var writeErr error
+		for _, e := range batch {
+			if format == "csv" {
+				csvWriter.Write(auditLogCSVRecord(e))
+			} else if err := jsonEncoder.Encode(e); err != nil {
+				writeErr = err
+				break
+			}
+		}
+		csvWriter.Flush()
+		if writeErr == nil {
+			writeErr = csvWriter.Error()
+		}
+		if writeErr != nil {
+			// Usually the client went away mid-download; stop reading batches
+			// nobody will receive.
+			log.Printf("Error writing loot audit log export: %v", writeErr)
+			break
+		}
+		c.Writer.Flush()
+		if len(batch) < lootAuditExportBatchSize {
+			break
+		}
+		afterId = batch[len(batch)-1].ID
+	}
+}
+
+type lootAuditLogWeekRow struct {
+	CharacterID   uint
+	CharacterName string
+	WeekStart     time.Time
+	EventType     string
+	Count         uint
+}
+
+type lootAuditLogWeekSummary struct {
+	CharacterID   uint            `json:"character_id"`
+	CharacterName string          `json:"character_name"`
+	WeekStart     time.Time       `json:"week_start"`
+	Total         uint            `json:"total"`
+	ByEventType   map[string]uint `json:"by_event_type"`
+}
+
+// GetLootAuditLogSummary counts audit events per character per week
+// (Postgres date_trunc weeks, starting Monday UTC), broken down by event
+// type — meant to surface wishlist churn without paging through the raw log.
+// Accepts the same filters as GetLootAuditLog. Loot-council/admin/owner only.
+func GetLootAuditLogSummary(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	query, ok := applyLootAuditLogFilters(c, database.DB.Model(&models.LootAuditLog{}).Where("team_id = ?", teamId))
+	if !ok {
+		return
+	}
+
+	var rows []lootAuditLogWeekRow
+	if err := query.
+		Select("character_id, MAX(character_name) AS character_name, date_trunc('week', created_at) AS week_start, event_type, COUNT(*) AS count").
+		Group("character_id, week_start, event_type").
+		Scan(&rows).Error; err != nil {
+		log.Printf("Error summarizing loot audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to summarize audit log"})
+		return
+	}
+
+	type summaryKey struct {
+		characterId uint
+		weekStart   time.Time
+	}
+	byKey := make(map[summaryKey]*lootAuditLogWeekSummary)
+	for _, row := range rows {
+		key := summaryKey{row.CharacterID, row.WeekStart}
+		summary, exists := byKey[key]
+		if !exists {
+			summary = &lootAuditLogWeekSummary{
+				CharacterID:   row.CharacterID,
+				CharacterName: row.CharacterName,
+				WeekStart:     row.WeekStart,
+				ByEventType:   map[string]uint{},
+			}
+			byKey[key] = summary
+		}
+		summary.Total += row.Count
+		summary.ByEventType[row.EventType] += row.Count
+	}
+
+	summaries := make([]lootAuditLogWeekSummary, 0, len(byKey))
+	for _, s := range byKey {
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if !summaries[i].WeekStart.Equal(summaries[j].WeekStart) {
+			return summaries[i].WeekStart.After(summaries[j].WeekStart)
+		}
+		return summaries[i].CharacterName < summaries[j].CharacterName
+	})
+
+	c.JSON(http.StatusOK, gin.H{"loot_audit_log_summary": summaries})
+}
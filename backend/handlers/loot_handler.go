@@ -260,6 +260,11 @@ func UpsertItemWish(c *gin.Context) {
 		return
 	}
 
+	lockOverride, ok := checkWishlistLock(c, uint(teamId), user.ID)
+	if !ok {
+		return
+	}
+
 	if payload.Wished {
 		wish := models.CharacterItemWish{CharacterID: payload.CharacterID, ItemID: payload.ItemID, Difficulty: difficulty}
 		if err := database.DB.Clauses(clause.OnConflict{
@@ -295,6 +300,7 @@ func UpsertItemWish(c *gin.Context) {
 			Difficulty:     difficulty,
 			ItemID:         &payload.ItemID,
 			ItemName:       &itemName,
+			LockOverride:   lockOverride,
 		})
 	}
 
@@ -351,6 +357,12 @@ func UpdateItemObtained(c *gin.Context) {
 		return
 	}
 
+	if payload.Obtained {
+		if _, err := applyTierTokenObtained(payload.CharacterID, payload.ItemID, difficulty, user.ID); err != nil {
+			log.Printf("Error updating tier slot from token: %v", err)
+		}
+	}
+
 	if item, character, ok := loadItemAuditContext(payload.ItemID, payload.CharacterID); ok {
 		eventType := models.AuditEventItemUnobtained
 		if payload.Obtained {
@@ -417,6 +429,11 @@ func UpsertBossPriority(c *gin.Context) {
 		return
 	}
 
+	lockOverride, ok := checkWishlistLock(c, uint(teamId), user.ID)
+	if !ok {
+		return
+	}
+
 	// Priorities must be unique per character+difficulty — reject rather
 	// than silently reassigning the conflicting boss. Bulk reordering (which
 	// legitimately needs to move a priority "through" another boss's
@@ -469,6 +486,7 @@ func UpsertBossPriority(c *gin.Context) {
 			BossName:       boss.Name,
 			Difficulty:     difficulty,
 			Value:          &value,
+			LockOverride:   lockOverride,
 		})
 	}
 
@@ -517,6 +535,11 @@ func UpsertBonusRolls(c *gin.Context) {
 		return
 	}
 
+	lockOverride, ok := checkWishlistLock(c, uint(teamId), user.ID)
+	if !ok {
+		return
+	}
+
 	var bonusRolls models.CharacterBossBonusRolls
 	result := database.DB.Where("character_id = ? AND boss_id = ? AND difficulty = ?", payload.CharacterID, bossId, difficulty).First(&bonusRolls)
 	previousCount := uint(0)
@@ -562,6 +585,7 @@ func UpsertBonusRolls(c *gin.Context) {
 				BossName:       boss.Name,
 				Difficulty:     difficulty,
 				Value:          &value,
+				LockOverride:   lockOverride,
 			})
 		}
 	}
@@ -603,17 +627,22 @@ type bossRollOverviewResponse struct {
 }
 
 // currentSeasonBosses returns every boss belonging to the season currently
-// marked IsCurrent (empty if there isn't one) — shared by GetRaidRollOverview
-// and SearchLootItems, both of which operate over "every boss in the current
-// tier" rather than a single team-selected boss.
+// marked IsCurrent (empty if there isn't one) — shared by the views that
+// operate over "every boss in the current tier" rather than a single
+// team-selected boss (raid overview, priorities, bonus-roll planner,
+// character loot summary).
 func currentSeasonBosses() []models.Boss {
 	var season models.Season
 	if err := database.DB.Where("is_current = ?", true).First(&season).Error; err != nil {
 		return []models.Boss{}
 	}
+	return seasonBosses(season.Id)
+}
 
+// seasonBosses returns every boss across every raid in the given season.
+func seasonBosses(seasonId uint) []models.Boss {
 	var raids []models.Raid
-	database.DB.Where("season_id = ?", season.Id).Find(&raids)
+	database.DB.Where("season_id = ?", seasonId).Find(&raids)
 	raidIds := make([]uint, len(raids))
 	for i, raid := range raids {
 		raidIds[i] = raid.Id
@@ -629,20 +658,69 @@ func currentSeasonBosses() []models.Boss {
 // computeBossRollStats loads a boss's items (with PrimaryStats/EligibleRoles
 // preloaded) and every character's wish/priority/bonus-roll data for
 // boss+difficulty, and returns the per-item wisher breakdown alongside the
-// per-character roll stats (priority, bonus rolls, pool size, done). Shared
-// by GetBossRollOverview (single boss, needs the item-level detail too) and
-// GetRaidRollOverview (every boss in the current tier, only needs rolls).
+// per-character roll stats (priority, bonus rolls, pool size, done). Used by
+// GetBossRollOverview (single boss, needs the item-level detail too); the
+// views over a whole tier go through computeBossesRollStats directly.
 func computeBossRollStats(bossId uint, difficulty string) ([]bossRollOverviewItem, []bossRollOverviewCharacterRoll, error) {
+	items, rolls, err := computeBossesRollStats([]uint{bossId}, difficulty)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items[bossId], rolls[bossId], nil
+}
+
+// computeBossesRollStats is computeBossRollStats for many bosses at once,
+// keyed by boss ID. Items, wishes, priorities, bonus rolls and characters
+// are each loaded in a single query for every boss and grouped in memory,
+// so a tier-wide view costs the same handful of queries as a single boss.
+// Every requested boss gets an entry, empty if it has no items or interest.
+func computeBossesRollStats(bossIds []uint, difficulty string) (map[uint][]bossRollOverviewItem, map[uint][]bossRollOverviewCharacterRoll, error) {
+	responseItems := make(map[uint][]bossRollOverviewItem, len(bossIds))
+	responseRolls := make(map[uint][]bossRollOverviewCharacterRoll, len(bossIds))
+	for _, bossId := range bossIds {
+		responseItems[bossId] = []bossRollOverviewItem{}
+		responseRolls[bossId] = []bossRollOverviewCharacterRoll{}
+	}
+	if len(bossIds) == 0 {
+		return responseItems, responseRolls, nil
+	}
+
 	var items []models.Item
-	if err := database.DB.Where("boss_id = ?", bossId).
+	if err := database.DB.Where("boss_id IN ?", bossIds).
 		Preload("PrimaryStats").
 		Preload("EligibleRoles").
 		Find(&items).Error; err != nil {
 		return nil, nil, fmt.Errorf("fetching boss items: %w", err)
 	}
 	itemIds := make([]uint, len(items))
+	bossByItem := make(map[uint]uint, len(items))
+	itemsByBoss := make(map[uint][]models.Item, len(bossIds))
 	for i, item := range items {
 		itemIds[i] = item.ID
+		bossByItem[item.ID] = item.BossID
+		itemsByBoss[item.BossID] = append(itemsByBoss[item.BossID], item)
+	}
+
+	// Per-boss state keyed by character, mirroring the single-boss version.
+	type characterStats struct {
+		priority   *uint
+		bonusRolls uint
+		wished     int
+		obtained   int
+	}
+	statsByBoss := make(map[uint]map[uint]*characterStats, len(bossIds))
+	statsFor := func(bossId, characterId uint) *characterStats {
+		byCharacter := statsByBoss[bossId]
+		if byCharacter == nil {
+			byCharacter = make(map[uint]*characterStats)
+			statsByBoss[bossId] = byCharacter
+		}
+		stats := byCharacter[characterId]
+		if stats == nil {
+			stats = &characterStats{}
+			byCharacter[characterId] = stats
+		}
+		return stats
 	}
 
 	var wishes []models.CharacterItemWish
@@ -650,64 +728,53 @@ func computeBossRollStats(bossId uint, difficulty string) ([]bossRollOverviewIte
 		database.DB.Where("item_id IN ? AND difficulty = ?", itemIds, difficulty).Find(&wishes)
 	}
 	wishesByItem := make(map[uint][]itemWisher, len(items))
-	rollCharacterIds := make(map[uint]bool)
-	doneStatsByCharacter := make(map[uint][2]int) // [0]=total wished, [1]=obtained
 	for _, w := range wishes {
 		wishesByItem[w.ItemID] = append(wishesByItem[w.ItemID], itemWisher{CharacterID: w.CharacterID, Obtained: w.Obtained})
-		rollCharacterIds[w.CharacterID] = true
-		stats := doneStatsByCharacter[w.CharacterID]
-		stats[0]++
+		stats := statsFor(bossByItem[w.ItemID], w.CharacterID)
+		stats.wished++
 		if w.Obtained {
-			stats[1]++
+			stats.obtained++
 		}
-		doneStatsByCharacter[w.CharacterID] = stats
 	}
 
 	var priorities []models.CharacterBossPriority
-	database.DB.Where("boss_id = ? AND difficulty = ?", bossId, difficulty).Find(&priorities)
-	priorityByCharacter := make(map[uint]uint, len(priorities))
+	database.DB.Where("boss_id IN ? AND difficulty = ?", bossIds, difficulty).Find(&priorities)
 	for _, p := range priorities {
-		priorityByCharacter[p.CharacterID] = p.Priority
-		rollCharacterIds[p.CharacterID] = true
+		priority := p.Priority
+		statsFor(p.BossID, p.CharacterID).priority = &priority
 	}
 
 	var bonusRollRows []models.CharacterBossBonusRolls
-	database.DB.Where("boss_id = ? AND difficulty = ?", bossId, difficulty).Find(&bonusRollRows)
-	bonusRollsByCharacter := make(map[uint]uint, len(bonusRollRows))
+	database.DB.Where("boss_id IN ? AND difficulty = ?", bossIds, difficulty).Find(&bonusRollRows)
 	for _, b := range bonusRollRows {
-		bonusRollsByCharacter[b.CharacterID] = b.Count
-		rollCharacterIds[b.CharacterID] = true
+		statsFor(b.BossID, b.CharacterID).bonusRolls = b.Count
 	}
 
-	characterIds := make([]uint, 0, len(rollCharacterIds))
-	for characterId := range rollCharacterIds {
+	characterIdSet := make(map[uint]bool)
+	for _, byCharacter := range statsByBoss {
+		for characterId := range byCharacter {
+			characterIdSet[characterId] = true
+		}
+	}
+	characterIds := make([]uint, 0, len(characterIdSet))
+	for characterId := range characterIdSet {
 		characterIds = append(characterIds, characterId)
 	}
 	var characters []models.Character
 	if len(characterIds) > 0 {
 		database.DB.Preload("Specialization.WeaponTypes").Where("id IN ?", characterIds).Find(&characters)
 	}
-	poolSizeByCharacter := make(map[uint]uint, len(characters))
+	specByCharacter := make(map[uint]*models.Specialization, len(characters))
 	for _, char := range characters {
-		if char.Specialization == nil {
-			continue
-		}
-		var poolSize uint
-		for _, item := range items {
-			if item.IsEligibleFor(*char.Specialization) {
-				poolSize++
-			}
-		}
-		poolSizeByCharacter[char.ID] = poolSize
+		specByCharacter[char.ID] = char.Specialization
 	}
 
-	responseItems := []bossRollOverviewItem{}
 	for _, item := range items {
 		wishers := wishesByItem[item.ID]
 		if wishers == nil {
 			wishers = []itemWisher{}
 		}
-		responseItems = append(responseItems, bossRollOverviewItem{
+		responseItems[item.BossID] = append(responseItems[item.BossID], bossRollOverviewItem{
 			ID:        item.ID,
 			WowItemID: item.WowItemID,
 			Name:      item.Name,
@@ -716,20 +783,24 @@ func computeBossRollStats(bossId uint, difficulty string) ([]bossRollOverviewIte
 		})
 	}
 
-	responseRolls := []bossRollOverviewCharacterRoll{}
-	for characterId := range rollCharacterIds {
-		var priority *uint
-		if p, ok := priorityByCharacter[characterId]; ok {
-			priority = &p
+	for bossId, byCharacter := range statsByBoss {
+		for characterId, stats := range byCharacter {
+			var poolSize uint
+			if spec := specByCharacter[characterId]; spec != nil {
+				for _, item := range itemsByBoss[bossId] {
+					if item.IsEligibleFor(*spec) {
+						poolSize++
+					}
+				}
+			}
+			responseRolls[bossId] = append(responseRolls[bossId], bossRollOverviewCharacterRoll{
+				CharacterID: characterId,
+				Priority:    stats.priority,
+				BonusRolls:  stats.bonusRolls,
+				PoolSize:    poolSize,
+				Done:        stats.wished > 0 && stats.wished == stats.obtained,
+			})
 		}
-		stats := doneStatsByCharacter[characterId]
-		responseRolls = append(responseRolls, bossRollOverviewCharacterRoll{
-			CharacterID: characterId,
-			Priority:    priority,
-			BonusRolls:  bonusRollsByCharacter[characterId],
-			PoolSize:    poolSizeByCharacter[characterId],
-			Done:        stats[0] > 0 && stats[0] == stats[1],
-		})
 	}
 
 	return responseItems, responseRolls, nil
@@ -828,15 +899,20 @@ func GetRaidRollOverview(c *gin.Context) {
 
 	bosses := currentSeasonBosses()
 
+	bossIds := make([]uint, len(bosses))
+	for i, boss := range bosses {
+		bossIds[i] = boss.ID
+	}
+	_, rollsByBoss, err := computeBossesRollStats(bossIds, difficulty)
+	if err != nil {
+		log.Printf("Error computing raid roll stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute raid roll stats"})
+		return
+	}
+
 	response := raidRollOverviewResponse{Bosses: []raidRollOverviewBossEntry{}}
 	for _, boss := range bosses {
-		_, rolls, err := computeBossRollStats(boss.ID, difficulty)
-		if err != nil {
-			log.Printf("Error computing roll stats for boss %d: %v", boss.ID, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute raid roll stats"})
-			return
-		}
-		response.Bosses = append(response.Bosses, raidRollOverviewBossEntry{BossID: boss.ID, Rolls: rolls})
+		response.Bosses = append(response.Bosses, raidRollOverviewBossEntry{BossID: boss.ID, Rolls: rollsByBoss[boss.ID]})
 	}
 
 	c.JSON(http.StatusOK, gin.H{"raid_roll_overview": response})
@@ -847,14 +923,138 @@ type lootItemSearchResult struct {
 	WowItemID uint   `json:"wow_item_id"`
 	Name      string `json:"name"`
 	IconUrl   string `json:"icon_url"`
+	Slot      string `json:"slot"`
+	ItemLevel uint   `json:"item_level"`
 	BossID    uint   `json:"boss_id"`
 	BossName  string `json:"boss_name"`
+	SeasonID  uint   `json:"season_id"`
+}
+
+// lootItemSearchLimit caps SearchLootItems' results. When filtering by spec
+// the query fetches lootItemSearchCandidates rows instead, since
+// IsEligibleFor runs in Go after the fact and would otherwise leave fewer
+// than lootItemSearchLimit items on a page that had more to show.
+const (
+	lootItemSearchLimit      = 20
+	lootItemSearchCandidates = 200
+)
+
+var validItemSearchStats = map[string]bool{
+	models.StatStrength:  true,
+	models.StatAgility:   true,
+	models.StatIntellect: true,
+}
+
+var validItemSearchRoles = map[string]bool{
+	models.RoleTank:   true,
+	models.RoleHealer: true,
+	models.RoleDPS:    true,
 }
 
-// SearchLootItems searches item names across every boss in the current
-// season — used by the Per-Item tab to find an item without navigating the
-// boss sidebar first. Loot-council/admin/owner only, same as every other
-// aggregate view in this feature.
+// lootItemSearchSeasonIDs resolves SearchLootItems' scope: season_id wins,
+// then every season of expansion_id, then the current season. Writes a 400
+// for unparseable IDs or a missing current season.
+func lootItemSearchSeasonIDs(c *gin.Context) ([]uint, bool) {
+	if seasonId := c.Query("season_id"); seasonId != "" {
+		parsed, err := strconv.ParseUint(seasonId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+			return nil, false
+		}
+		return []uint{uint(parsed)}, true
+	}
+	if expansionId := c.Query("expansion_id"); expansionId != "" {
+		parsed, err := strconv.ParseUint(expansionId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expansion ID"})
+			return nil, false
+		}
+		var seasonIds []uint
+		database.DB.Model(&models.Season{}).Where("expansion_id = ?", parsed).Pluck("id", &seasonIds)
+		return seasonIds, true
+	}
+	seasonId, ok := currentSeasonID()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+		return nil, false
+	}
+	return []uint{seasonId}, true
+}
+
+// applyLootItemSearchFilters narrows an items query by SearchLootItems'
+// optional filters. primary_stat and role follow IsEligibleFor's rules —
+// an item with no stat (or role) tags matches any stat (or role) — so
+// filtering by Agility still finds rings and cloaks. spec_id can't be
+// expressed in SQL without duplicating IsEligibleFor, so it's returned for
+// the caller to apply after loading; filtered=true if any filter (including
+// spec_id) was given. Writes a 400 itself for invalid values.
+func applyLootItemSearchFilters(c *gin.Context, query *gorm.DB) (result *gorm.DB, spec *models.Specialization, filtered bool, ok bool) {
+	if slot := strings.TrimSpace(c.Query("slot")); slot != "" {
+		query = query.Where("items.slot ILIKE ?", "%"+slot+"%")
+		filtered = true
+	}
+	if armorTypeId := c.Query("armor_type_id"); armorTypeId != "" {
+		parsed, err := strconv.ParseUint(armorTypeId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid armor type ID"})
+			return nil, nil, false, false
+		}
+		query = query.Where("items.armor_type_id = ?", parsed)
+		filtered = true
+	}
+	if weaponTypeId := c.Query("weapon_type_id"); weaponTypeId != "" {
+		parsed, err := strconv.ParseUint(weaponTypeId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid weapon type ID"})
+			return nil, nil, false, false
+		}
+		query = query.Where("items.weapon_type_id = ?", parsed)
+		filtered = true
+	}
+	if stat := c.Query("primary_stat"); stat != "" {
+		if !validItemSearchStats[stat] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid primary_stat '%s'", stat)})
+			return nil, nil, false, false
+		}
+		query = query.Where(`NOT EXISTS (SELECT 1 FROM item_primary_stats s WHERE s.item_id = items.id)
+			OR EXISTS (SELECT 1 FROM item_primary_stats s WHERE s.item_id = items.id AND s.stat = ?)`, stat)
+		filtered = true
+	}
+	if role := c.Query("role"); role != "" {
+		if !validItemSearchRoles[role] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid role '%s'", role)})
+			return nil, nil, false, false
+		}
+		query = query.Where(`NOT EXISTS (SELECT 1 FROM item_eligible_roles r WHERE r.item_id = items.id)
+			OR EXISTS (SELECT 1 FROM item_eligible_roles r WHERE r.item_id = items.id AND r.role = ?)`, role)
+		filtered = true
+	}
+	if specId := c.Query("spec_id"); specId != "" {
+		parsed, err := strconv.ParseUint(specId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spec ID"})
+			return nil, nil, false, false
+		}
+		var s models.Specialization
+		if err := database.DB.Preload("WeaponTypes").First(&s, parsed).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "specialization not found"})
+			return nil, nil, false, false
+		}
+		spec = &s
+		filtered = true
+	}
+	return query, spec, filtered, true
+}
+
+// SearchLootItems searches items by name — used by the Per-Item tab to find
+// an item without navigating the boss sidebar first, and by players
+// planning ahead for a re-released tier. Names are matched fuzzily (pg_trgm
+// similarity, same as SearchSpells) plus a plain substring match so short
+// partial names still hit, best match first. Scope defaults to the current
+// season; season_id or expansion_id searches older items instead. Filters
+// (slot, armor_type_id, weapon_type_id, primary_stat, role, spec_id) can be
+// combined with or used without q. Any team member may search — results
+// are catalogue data only, nothing from anyone's wishlist.
 func SearchLootItems(c *gin.Context) {
 	user, ok := getRequestingUser(c)
 	if !ok {
@@ -867,47 +1067,66 @@ func SearchLootItems(c *gin.Context) {
 		return
 	}
 
-	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+	if !isTeamMember(uint(teamId), user.ID) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
+	seasonIds, ok := lootItemSearchSeasonIDs(c)
+	if !ok {
+		return
+	}
+
+	dbQuery, spec, filtered, ok := applyLootItemSearchFilters(c, database.DB.Model(&models.Item{}))
+	if !ok {
+		return
+	}
+
 	results := []lootItemSearchResult{}
 	query := strings.TrimSpace(c.Query("q"))
-	if query == "" {
+	if (query == "" && !filtered) || len(seasonIds) == 0 {
 		c.JSON(http.StatusOK, gin.H{"items": results})
 		return
 	}
 
-	bosses := currentSeasonBosses()
-	bossIds := make([]uint, len(bosses))
-	for i, boss := range bosses {
-		bossIds[i] = boss.ID
+	dbQuery = dbQuery.Where("items.season_id IN ?", seasonIds)
+	if query != "" {
+		dbQuery = dbQuery.Where("(items.name % ? OR items.name ILIKE ?)", query, "%"+query+"%").
+			Order(clause.OrderBy{Expression: clause.Expr{SQL: "similarity(items.name, ?) DESC", Vars: []interface{}{query}, WithoutParentheses: true}})
 	}
-	if len(bossIds) == 0 {
-		c.JSON(http.StatusOK, gin.H{"items": results})
-		return
+	dbQuery = dbQuery.Order("items.season_id DESC, items.name")
+
+	limit := lootItemSearchLimit
+	if spec != nil {
+		limit = lootItemSearchCandidates
+		dbQuery = dbQuery.Preload("PrimaryStats").Preload("EligibleRoles")
 	}
 
 	var items []models.Item
-	if err := database.DB.Where("boss_id IN ? AND name ILIKE ?", bossIds, "%"+query+"%").
-		Preload("Boss").
-		Limit(20).
-		Find(&items).Error; err != nil {
+	if err := dbQuery.Preload("Boss").Limit(limit).Find(&items).Error; err != nil {
 		log.Printf("Error searching loot items: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search items"})
 		return
 	}
 
 	for _, item := range items {
+		if spec != nil && !item.IsEligibleFor(*spec) {
+			continue
+		}
 		results = append(results, lootItemSearchResult{
 			ItemID:    item.ID,
 			WowItemID: item.WowItemID,
 			Name:      item.Name,
 			IconUrl:   item.IconUrl,
+			Slot:      item.Slot,
+			ItemLevel: item.ItemLevel,
 			BossID:    item.BossID,
 			BossName:  item.Boss.Name,
+			SeasonID:  item.SeasonID,
 		})
+		if len(results) == lootItemSearchLimit {
+			break
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"items": results})
@@ -1009,12 +1228,16 @@ func GetItemRollOverview(c *gin.Context) {
 type lootAuditLogResponse struct {
 	Entries []models.LootAuditLog `json:"entries"`
 	HasMore bool                  `json:"has_more"`
+	// NextBeforeID is the before_id to pass for the next page — nil once
+	// there's nothing older left.
+	NextBeforeID *uint `json:"next_before_id"`
 }
 
 // GetLootAuditLog returns the accountability trail for a team's wishlist
 // edits — loot-council/admin/owner only. Cursor-paginated via before_id
 // (not offset-based, since this table is actively appended to while
-// someone might be paging back through it).
+// someone might be paging back through it), and filterable by everything
+// applyLootAuditLogFilters understands.
 func GetLootAuditLog(c *gin.Context) {
 	user, ok := getRequestingUser(c)
 	if !ok {
@@ -1042,22 +1265,15 @@ func GetLootAuditLog(c *gin.Context) {
 		limit = 100
 	}
 
-	query := database.DB.Where("team_id = ?", teamId)
+	query, ok := applyLootAuditLogFilters(c, database.DB.Where("team_id = ?", teamId))
+	if !ok {
+		return
+	}
 	if beforeId := c.Query("before_id"); beforeId != "" {
 		if parsed, err := strconv.ParseUint(beforeId, 10, 32); err == nil {
 			query = query.Where("id < ?", parsed)
 		}
 	}
-	if characterId := c.Query("character_id"); characterId != "" {
-		if parsed, err := strconv.ParseUint(characterId, 10, 32); err == nil {
-			query = query.Where("character_id = ?", parsed)
-		}
-	}
-	if bossId := c.Query("boss_id"); bossId != "" {
-		if parsed, err := strconv.ParseUint(bossId, 10, 32); err == nil {
-			query = query.Where("boss_id = ?", parsed)
-		}
-	}
 
 	var entries []models.LootAuditLog
 	if err := query.Order("id DESC").Limit(limit).Find(&entries).Error; err != nil {
@@ -1066,9 +1282,15 @@ func GetLootAuditLog(c *gin.Context) {
 		return
 	}
 
+	var nextBeforeId *uint
+	if len(entries) == limit {
+		nextBeforeId = &entries[len(entries)-1].ID
+	}
+
 	c.JSON(http.StatusOK, gin.H{"loot_audit_log": lootAuditLogResponse{
-		Entries: entries,
-		HasMore: len(entries) == limit,
+		Entries:      entries,
+		HasMore:      len(entries) == limit,
+		NextBeforeID: nextBeforeId,
 	}})
 }
 
@@ -1172,6 +1394,11 @@ func ReorderBossPriorities(c *gin.Context) {
 		return
 	}
 
+	lockOverride, ok := checkWishlistLock(c, uint(teamId), user.ID)
+	if !ok {
+		return
+	}
+
 	var existing []models.CharacterBossPriority
 	database.DB.Where("character_id = ? AND difficulty = ?", characterId, difficulty).Find(&existing)
 	previousPriorityByBoss := make(map[uint]uint, len(existing))
@@ -1219,6 +1446,7 @@ func ReorderBossPriorities(c *gin.Context) {
 				BossName:       boss.Name,
 				Difficulty:     difficulty,
 				Value:          &value,
+				LockOverride:   lockOverride,
 			})
 		}
 	}
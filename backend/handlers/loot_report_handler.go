@@ -0,0 +1,499 @@
+package handlers
+
+import (
+	"krankenprep/database"
+	"krankenprep/models"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Outlier flags for fairnessCharacterEntry.Flags.
+const (
+	fairnessFlagHighAwards = "high_awards"
+	fairnessFlagLowAwards  = "low_awards"
+)
+
+// Award recorder values for fairnessCharacterEntry.AwardsBySource — who
+// marked the item obtained, per the item_obtained audit entries.
+const (
+	awardSourceSelf    = "self"
+	awardSourceCouncil = "council"
+)
+
+// teamRosterCharacters returns every character belonging to any Player on
+// the team's roster, with Player preloaded.
+func teamRosterCharacters(teamId uint) []models.Character {
+	var players []models.Player
+	database.DB.Where("team_id = ?", teamId).Find(&players)
+	playerIds := make([]uint, len(players))
+	for i, p := range players {
+		playerIds[i] = p.ID
+	}
+
+	characters := []models.Character{}
+	if len(playerIds) > 0 {
+		database.DB.Preload("Player").Where("player_id IN ?", playerIds).Order("id").Find(&characters)
+	}
+	return characters
+}
+
+// medianUint returns the median of values (0 for an empty slice) — the
+// baseline fairness outliers are measured against, since a mean would get
+// dragged around by exactly the outliers it's meant to catch.
+func medianUint(values []uint) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]uint(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+	return float64(sorted[mid])
+}
+
+// fairnessFlags marks a character as an outlier when their award count is
+// at least double the team median (and at least 2 items above it, so a
+// median of 1 doesn't flag everyone with 2), or under half of it once the
+// median is meaningful.
+func fairnessFlags(awards uint, median float64) []string {
+	flags := []string{}
+	a := float64(awards)
+	if a >= median*2 && a-median >= 2 {
+		flags = append(flags, fairnessFlagHighAwards)
+	}
+	if median >= 2 && a < median/2 {
+		flags = append(flags, fairnessFlagLowAwards)
+	}
+	return flags
+}
+
+type fairnessCharacterEntry struct {
+	CharacterID   uint   `json:"character_id"`
+	CharacterName string `json:"character_name"`
+	PlayerName    string `json:"player_name"`
+	ItemsWished   uint   `json:"items_wished"`
+	ItemsObtained uint   `json:"items_obtained"`
+	// AwardsByDifficulty and AwardsBySource break ItemsObtained down by the
+	// item_obtained audit entries recorded this season — source is "self"
+	// when the character's own player marked it, "council" otherwise.
+	AwardsByDifficulty map[string]uint `json:"awards_by_difficulty"`
+	AwardsBySource     map[string]uint `json:"awards_by_source"`
+	BonusRollsSpent    uint            `json:"bonus_rolls_spent"`
+	AveragePriority    *float64        `json:"average_priority"`
+	PercentComplete    float64         `json:"percent_complete"`
+	Flags              []string        `json:"flags"`
+}
+
+type fairnessReportResponse struct {
+	SeasonID     uint                     `json:"season_id"`
+	MedianAwards float64                  `json:"median_awards"`
+	Characters   []fairnessCharacterEntry `json:"characters"`
+}
+
+// GetLootFairnessReport aggregates a whole season's loot per roster
+// character — wished vs obtained, award breakdowns, bonus rolls spent,
+// average boss priority, wishlist completion — and flags characters whose
+// award count sits far from the team median. Season comes from season_id,
+// defaulting to the current one; wishes are season-scoped through
+// Item.SeasonID, rolls/priorities through their boss's raid. An optional
+// difficulty narrows everything to Heroic or Mythic. Loot-council/admin/
+// owner only, same as every other aggregate view in this feature.
+func GetLootFairnessReport(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var difficulty string
+	if q := c.Query("difficulty"); q != "" {
+		difficulty, ok = getDifficulty(c, q)
+		if !ok {
+			return
+		}
+	}
+
+	var seasonId uint
+	if q := c.Query("season_id"); q != "" {
+		parsed, err := strconv.ParseUint(q, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+			return
+		}
+		seasonId = uint(parsed)
+	} else {
+		id, ok := currentSeasonID()
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+			return
+		}
+		seasonId = id
+	}
+
+	characters := teamRosterCharacters(uint(teamId))
+	characterIds := make([]uint, len(characters))
+	for i, ch := range characters {
+		characterIds[i] = ch.ID
+	}
+
+	bosses := seasonBosses(seasonId)
+	bossIds := make([]uint, len(bosses))
+	for i, b := range bosses {
+		bossIds[i] = b.ID
+	}
+
+	entries := make(map[uint]*fairnessCharacterEntry, len(characters))
+	for _, ch := range characters {
+		entries[ch.ID] = &fairnessCharacterEntry{
+			CharacterID:        ch.ID,
+			CharacterName:      ch.Name,
+			PlayerName:         ch.Player.Name,
+			AwardsByDifficulty: map[string]uint{},
+			AwardsBySource:     map[string]uint{},
+		}
+	}
+
+	if len(characterIds) > 0 {
+		wishQuery := database.DB.Joins("JOIN items ON items.id = character_item_wishes.item_id").
+			Where("character_item_wishes.character_id IN ? AND items.season_id = ?", characterIds, seasonId)
+		if difficulty != "" {
+			wishQuery = wishQuery.Where("character_item_wishes.difficulty = ?", difficulty)
+		}
+		var wishes []models.CharacterItemWish
+		wishQuery.Find(&wishes)
+		for _, w := range wishes {
+			entries[w.CharacterID].ItemsWished++
+			if w.Obtained {
+				entries[w.CharacterID].ItemsObtained++
+			}
+		}
+
+		// Award breakdowns come from the audit trail rather than the wish
+		// rows, since only the trail remembers who marked an item obtained.
+		// An obtain later undone is netted back out by its unobtain entry.
+		auditQuery := database.DB.Joins("JOIN items ON items.id = loot_audit_logs.item_id").
+			Where("loot_audit_logs.team_id = ? AND loot_audit_logs.character_id IN ? AND items.season_id = ? AND loot_audit_logs.event_type IN ?",
+				teamId, characterIds, seasonId, []string{models.AuditEventItemObtained, models.AuditEventItemUnobtained})
+		if difficulty != "" {
+			auditQuery = auditQuery.Where("loot_audit_logs.difficulty = ?", difficulty)
+		}
+		var auditEntries []models.LootAuditLog
+		auditQuery.Order("loot_audit_logs.id").Find(&auditEntries)
+
+		ownerByCharacter := make(map[uint]*uint, len(characters))
+		for _, ch := range characters {
+			ownerByCharacter[ch.ID] = ch.Player.UserID
+		}
+		type awardKey struct {
+			characterId uint
+			itemId      uint
+			difficulty  string
+		}
+		latestObtain := make(map[awardKey]*models.LootAuditLog)
+		for i, a := range auditEntries {
+			key := awardKey{a.CharacterID, *a.ItemID, a.Difficulty}
+			if a.EventType == models.AuditEventItemObtained {
+				latestObtain[key] = &auditEntries[i]
+			} else {
+				delete(latestObtain, key)
+			}
+		}
+		for _, a := range latestObtain {
+			entry := entries[a.CharacterID]
+			entry.AwardsByDifficulty[a.Difficulty]++
+			owner := ownerByCharacter[a.CharacterID]
+			if owner != nil && *owner == a.ActingUserID {
+				entry.AwardsBySource[awardSourceSelf]++
+			} else {
+				entry.AwardsBySource[awardSourceCouncil]++
+			}
+		}
+
+		if len(bossIds) > 0 {
+			rollQuery := database.DB.Where("character_id IN ? AND boss_id IN ?", characterIds, bossIds)
+			priorityQuery := database.DB.Where("character_id IN ? AND boss_id IN ?", characterIds, bossIds)
+			if difficulty != "" {
+				rollQuery = rollQuery.Where("difficulty = ?", difficulty)
+				priorityQuery = priorityQuery.Where("difficulty = ?", difficulty)
+			}
+
+			var rolls []models.CharacterBossBonusRolls
+			rollQuery.Find(&rolls)
+			for _, r := range rolls {
+				entries[r.CharacterID].BonusRollsSpent += r.Count
+			}
+
+			var priorities []models.CharacterBossPriority
+			priorityQuery.Find(&priorities)
+			prioritySums := make(map[uint][2]uint) // [0]=sum, [1]=count
+			for _, p := range priorities {
+				sums := prioritySums[p.CharacterID]
+				sums[0] += p.Priority
+				sums[1]++
+				prioritySums[p.CharacterID] = sums
+			}
+			for characterId, sums := range prioritySums {
+				avg := float64(sums[0]) / float64(sums[1])
+				entries[characterId].AveragePriority = &avg
+			}
+		}
+	}
+
+	awards := make([]uint, 0, len(characters))
+	for _, ch := range characters {
+		awards = append(awards, entries[ch.ID].ItemsObtained)
+	}
+	median := medianUint(awards)
+
+	response := fairnessReportResponse{SeasonID: seasonId, MedianAwards: median, Characters: []fairnessCharacterEntry{}}
+	for _, ch := range characters {
+		entry := entries[ch.ID]
+		if entry.ItemsWished > 0 {
+			entry.PercentComplete = float64(entry.ItemsObtained) / float64(entry.ItemsWished) * 100
+		}
+		entry.Flags = fairnessFlags(entry.ItemsObtained, median)
+		response.Characters = append(response.Characters, *entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"fairness_report": response})
+}
+
+// characterLootSummaryDifficulty's item IDs come from the character's wish
+// rows, so a wish made under a previous spec can reference an item that's
+// no longer in the boss's EligibleItems.
+type characterLootSummaryDifficulty struct {
+	WishedItemIDs    []uint `json:"wished_item_ids"`
+	ObtainedItemIDs  []uint `json:"obtained_item_ids"`
+	RemainingItemIDs []uint `json:"remaining_item_ids"`
+	Priority         *uint  `json:"priority"`
+	BonusRolls       uint   `json:"bonus_rolls"`
+}
+
+type characterLootSummaryBoss struct {
+	BossID    uint   `json:"boss_id"`
+	BossName  string `json:"boss_name"`
+	ShortName string `json:"short_name"`
+	RaidID    uint   `json:"raid_id"`
+	// EligibleItems is the boss's loot table filtered by IsEligibleFor —
+	// the same pool GetBossLoot shows, identical across difficulties.
+	EligibleItems []bossLootItem                            `json:"eligible_items"`
+	Difficulties  map[string]characterLootSummaryDifficulty `json:"difficulties"`
+}
+
+type characterLootCompletion struct {
+	Wished          uint    `json:"wished"`
+	Obtained        uint    `json:"obtained"`
+	Remaining       uint    `json:"remaining"`
+	PercentComplete float64 `json:"percent_complete"`
+}
+
+type characterLootSummaryResponse struct {
+	CharacterID   uint                               `json:"character_id"`
+	CharacterName string                             `json:"character_name"`
+	Bosses        []characterLootSummaryBoss         `json:"bosses"`
+	Completion    map[string]characterLootCompletion `json:"completion"`
+}
+
+// GetCharacterLootSummary is GetBossLoot for every boss in the current
+// season at once — each boss's eligible pool plus, per difficulty, the
+// character's wished/obtained/still-needed items, priority and bonus rolls,
+// and an overall completion figure per difficulty. Everything is loaded in
+// one query per table rather than per boss. An optional difficulty narrows
+// the response to Heroic or Mythic. Same visibility as GetBossLoot: the
+// owning player or loot council/admin.
+func GetCharacterLootSummary(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	characterId, err := strconv.ParseUint(c.Param("characterId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+		return
+	}
+
+	difficulties := []string{models.DifficultyHeroic, models.DifficultyMythic}
+	if q := c.Query("difficulty"); q != "" {
+		difficulty, ok := getDifficulty(c, q)
+		if !ok {
+			return
+		}
+		difficulties = []string{difficulty}
+	}
+
+	if !canAccessCharacterWishlist(uint(teamId), user.ID, uint(characterId)) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var character models.Character
+	if err := database.DB.First(&character, characterId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "character not found"})
+		return
+	}
+	if character.SpecializationID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "character has no specialization set"})
+		return
+	}
+
+	var spec models.Specialization
+	if err := database.DB.Preload("WeaponTypes").First(&spec, *character.SpecializationID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load specialization"})
+		return
+	}
+
+	bosses := currentSeasonBosses()
+	sort.SliceStable(bosses, func(i, j int) bool {
+		if bosses[i].RaidId != bosses[j].RaidId {
+			return bosses[i].RaidId < bosses[j].RaidId
+		}
+		return bosses[i].Order < bosses[j].Order
+	})
+	bossIds := make([]uint, len(bosses))
+	for i, b := range bosses {
+		bossIds[i] = b.ID
+	}
+
+	response := characterLootSummaryResponse{
+		CharacterID:   character.ID,
+		CharacterName: character.Name,
+		Bosses:        []characterLootSummaryBoss{},
+		Completion:    map[string]characterLootCompletion{},
+	}
+	for _, difficulty := range difficulties {
+		response.Completion[difficulty] = characterLootCompletion{}
+	}
+	if len(bossIds) == 0 {
+		c.JSON(http.StatusOK, gin.H{"character_loot_summary": response})
+		return
+	}
+
+	var items []models.Item
+	if err := database.DB.Where("boss_id IN ?", bossIds).
+		Preload("PrimaryStats").
+		Preload("EligibleRoles").
+		Order("id").
+		Find(&items).Error; err != nil {
+		log.Printf("Error fetching season items: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch season items"})
+		return
+	}
+	itemsByBoss := make(map[uint][]models.Item)
+	itemBoss := make(map[uint]uint, len(items))
+	for _, item := range items {
+		itemsByBoss[item.BossID] = append(itemsByBoss[item.BossID], item)
+		itemBoss[item.ID] = item.BossID
+	}
+
+	type bossDifficulty struct {
+		bossId     uint
+		difficulty string
+	}
+	states := make(map[bossDifficulty]*characterLootSummaryDifficulty)
+	for _, b := range bosses {
+		for _, difficulty := range difficulties {
+			states[bossDifficulty{b.ID, difficulty}] = &characterLootSummaryDifficulty{
+				WishedItemIDs:    []uint{},
+				ObtainedItemIDs:  []uint{},
+				RemainingItemIDs: []uint{},
+			}
+		}
+	}
+
+	var wishes []models.CharacterItemWish
+	database.DB.Where("character_id = ? AND difficulty IN ?", characterId, difficulties).Order("item_id").Find(&wishes)
+	for _, w := range wishes {
+		state, ok := states[bossDifficulty{itemBoss[w.ItemID], w.Difficulty}]
+		if !ok {
+			continue // wish on an item outside the current season
+		}
+		state.WishedItemIDs = append(state.WishedItemIDs, w.ItemID)
+		completion := response.Completion[w.Difficulty]
+		completion.Wished++
+		if w.Obtained {
+			state.ObtainedItemIDs = append(state.ObtainedItemIDs, w.ItemID)
+			completion.Obtained++
+		} else {
+			state.RemainingItemIDs = append(state.RemainingItemIDs, w.ItemID)
+			completion.Remaining++
+		}
+		response.Completion[w.Difficulty] = completion
+	}
+
+	var priorities []models.CharacterBossPriority
+	database.DB.Where("character_id = ? AND boss_id IN ? AND difficulty IN ?", characterId, bossIds, difficulties).Find(&priorities)
+	for _, p := range priorities {
+		if state, ok := states[bossDifficulty{p.BossID, p.Difficulty}]; ok {
+			priority := p.Priority
+			state.Priority = &priority
+		}
+	}
+
+	var rolls []models.CharacterBossBonusRolls
+	database.DB.Where("character_id = ? AND boss_id IN ? AND difficulty IN ?", characterId, bossIds, difficulties).Find(&rolls)
+	for _, r := range rolls {
+		if state, ok := states[bossDifficulty{r.BossID, r.Difficulty}]; ok {
+			state.BonusRolls = r.Count
+		}
+	}
+
+	for _, b := range bosses {
+		entry := characterLootSummaryBoss{
+			BossID:        b.ID,
+			BossName:      b.Name,
+			ShortName:     b.ShortName,
+			RaidID:        b.RaidId,
+			EligibleItems: []bossLootItem{},
+			Difficulties:  map[string]characterLootSummaryDifficulty{},
+		}
+		for _, item := range itemsByBoss[b.ID] {
+			if !item.IsEligibleFor(spec) {
+				continue
+			}
+			entry.EligibleItems = append(entry.EligibleItems, bossLootItem{
+				ID:        item.ID,
+				WowItemID: item.WowItemID,
+				Name:      item.Name,
+				IconUrl:   item.IconUrl,
+				ItemLevel: item.ItemLevel,
+				Slot:      item.Slot,
+			})
+		}
+		for _, difficulty := range difficulties {
+			entry.Difficulties[difficulty] = *states[bossDifficulty{b.ID, difficulty}]
+		}
+		response.Bosses = append(response.Bosses, entry)
+	}
+
+	for difficulty, completion := range response.Completion {
+		if completion.Wished > 0 {
+			completion.PercentComplete = float64(completion.Obtained) / float64(completion.Wished) * 100
+			response.Completion[difficulty] = completion
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"character_loot_summary": response})
+}
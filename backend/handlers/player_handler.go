@@ -25,6 +25,15 @@ func isTeamAdmin(teamId uint, userId uint) bool {
 	return err == nil
 }
 
+// isTeamOwner checks for the owner role specifically — for the few actions
+// admins shouldn't take on their own, like replacing the team's whole
+// season of tier sim numbers with an import.
+func isTeamOwner(teamId uint, userId uint) bool {
+	role := models.Role{}
+	err := database.DB.Where("team_id = ? AND user_id = ? AND name = ?", teamId, userId, models.RoleOwner).First(&role).Error
+	return err == nil
+}
+
 // isLootCouncilOrAdmin checks whether the given user can edit any team
 // member's loot sheet — owners/admins already have full edit rights
 // everywhere else in the app, so this accepts that same set rather than
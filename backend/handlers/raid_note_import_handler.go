@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"krankenprep/database"
+	"krankenprep/models"
+	"krankenprep/utilities"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Match kinds for rosterNameMatcher.Match, weakest last.
+const (
+	nameMatchExact = "exact"
+	nameMatchLoose = "loose"
+	nameMatchFuzzy = "fuzzy"
+)
+
+// rosterNameMatcher resolves names typed or pasted into notes to a team's
+// roster characters: first ignoring case, punctuation and realm suffix,
+// then also accents, then by NameSimilarity for typos.
+type rosterNameMatcher struct {
+	characters []models.Character
+	exact      map[string][]int
+	loose      map[string][]int
+}
+
+func newRosterNameMatcher(characters []models.Character) rosterNameMatcher {
+	m := rosterNameMatcher{characters: characters, exact: map[string][]int{}, loose: map[string][]int{}}
+	for i, ch := range characters {
+		key := utilities.NameMatchKey(ch.Name)
+		m.exact[key] = append(m.exact[key], i)
+		loose := utilities.LooseNameKey(ch.Name)
+		m.loose[loose] = append(m.loose[loose], i)
+	}
+	return m
+}
+
+// pick chooses among characters sharing a name key, preferring the one on
+// the realm the note named, if it named one.
+func (m rosterNameMatcher) pick(candidates []*models.Character, name string) *models.Character {
+	if realm := name[len(utilities.StripRealm(name)):]; realm != "" {
+		for _, ch := range candidates {
+			if utilities.NameMatchKey(ch.Realm) == utilities.NameMatchKey(realm) {
+				return ch
+			}
+		}
+	}
+	return candidates[0]
+}
+
+// Candidates returns every character name could refer to at the strongest
+// match kind that finds any, and that kind — several when characters on
+// different realms share a name. Nil if nothing is close enough to link
+// without a human.
+func (m rosterNameMatcher) Candidates(name string) ([]*models.Character, string) {
+	characters := func(indexes []int) []*models.Character {
+		out := make([]*models.Character, len(indexes))
+		for i, index := range indexes {
+			out[i] = &m.characters[index]
+		}
+		return out
+	}
+	if indexes := m.exact[utilities.NameMatchKey(utilities.StripRealm(name))]; len(indexes) > 0 {
+		return characters(indexes), nameMatchExact
+	}
+	if indexes := m.loose[utilities.LooseNameKey(name)]; len(indexes) > 0 {
+		return characters(indexes), nameMatchLoose
+	}
+	best, bestScore := -1, 0.0
+	for i, ch := range m.characters {
+		score := utilities.NameSimilarity(utilities.LooseNameKey(name), utilities.LooseNameKey(ch.Name))
+		if score >= utilities.AutoLinkMinScore && score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return nil, ""
+	}
+	return characters([]int{best}), nameMatchFuzzy
+}
+
+// Match returns the character name most likely refers to and how it was
+// matched, or nil if nothing is close enough to link without a human.
+func (m rosterNameMatcher) Match(name string) (*models.Character, string) {
+	candidates, how := m.Candidates(name)
+	if len(candidates) == 0 {
+		return nil, ""
+	}
+	return m.pick(candidates, name), how
+}
+
+// Suggest returns the closest character name for a name Match couldn't
+// link, or "" if none comes close.
+func (m rosterNameMatcher) Suggest(name string) string {
+	suggestion, best := "", 0.0
+	for _, ch := range m.characters {
+		if score := utilities.NameSimilarity(utilities.LooseNameKey(name), utilities.LooseNameKey(ch.Name)); score >= noteSuggestionMinScore && score > best {
+			suggestion, best = ch.Name, score
+		}
+	}
+	return suggestion
+}
+
+type importedAssignmentSlot struct {
+	CharacterID   uint   `json:"character_id"`
+	CharacterName string `json:"character_name"`
+	SpellID       *int   `json:"spell_id"`
+}
+
+// importedAssignment has the same shape as bossAssignmentPayload, so the
+// draft can be reviewed and sent straight to SetBossAssignments.
+type importedAssignment struct {
+	Heading    string                   `json:"heading"`
+	Subheading string                   `json:"subheading"`
+	SpellID    *int                     `json:"spell_id"`
+	Slots      []importedAssignmentSlot `json:"slots"`
+}
+
+type importedNameMatch struct {
+	Name          string `json:"name"`
+	CharacterID   uint   `json:"character_id"`
+	CharacterName string `json:"character_name"`
+	Match         string `json:"match"`
+}
+
+type unmatchedNoteLine struct {
+	Heading string `json:"heading"`
+	Text    string `json:"text"`
+}
+
+// ImportRaidNote parses a pasted in-game raid note into a draft of
+// structured assignments for the boss, without saving anything. Blocks and
+// lines are matched to the boss's AssignmentMap by heading and label (bare
+// name lines fill the heading's positional subheadings in order), names
+// on mapped lines are resolved against the team roster, and {spell:} icons
+// become line or slot spells. Nothing in the note fails the import: unknown names,
+// unknown spells and lines the map has no place for are reported
+// alongside the draft, and non-exact name matches are listed for review.
+// Visible to every team member.
+func ImportRaidNote(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	bossId, err := strconv.ParseUint(c.Param("bossId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid boss ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload struct {
+		Note string `json:"note"`
+	}
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	var boss models.Boss
+	if err := database.DB.First(&boss, bossId).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "boss not found"})
+		return
+	}
+	def, err := boss.DecodedAssignmentMap()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read assignment map"})
+		return
+	}
+
+	parsed := utilities.ParseRaidNote(payload.Note)
+	matcher := newRosterNameMatcher(teamRosterCharacters(uint(teamId)))
+
+	spellIds := []int{}
+	for _, section := range parsed.Sections {
+		for _, line := range section.Lines {
+			if line.SpellID != nil {
+				spellIds = append(spellIds, *line.SpellID)
+			}
+			for _, entry := range line.Entries {
+				if entry.SpellID != nil {
+					spellIds = append(spellIds, *entry.SpellID)
+				}
+			}
+		}
+	}
+	knownSpells := make(map[int]bool)
+	if len(spellIds) > 0 {
+		var found []int
+		database.DB.Model(&models.Spell{}).Where("spell_id IN ?", spellIds).Pluck("spell_id", &found)
+		for _, id := range found {
+			knownSpells[id] = true
+		}
+	}
+	// Spells the database doesn't know are dropped from the draft (the save
+	// would reject them) and reported once each.
+	unknownSpellIds := []int{}
+	knownSpell := func(id *int) *int {
+		if id == nil || knownSpells[*id] {
+			return id
+		}
+		if !slices.Contains(unknownSpellIds, *id) {
+			unknownSpellIds = append(unknownSpellIds, *id)
+		}
+		return nil
+	}
+
+	draft := []importedAssignment{}
+	lines := []models.BossAssignment{}
+	matches := []importedNameMatch{}
+	matchedNames := make(map[string]bool)
+	unmatched := []unmatchedNoteLine{}
+	var unknown noteIssueList
+
+	for _, section := range parsed.Sections {
+		entryIdx, _, _ := findAssignmentLine(def, section.Heading.Text, "")
+		positional := 0
+		for _, line := range section.Lines {
+			location := section.Heading.Text
+			if line.Label != "" {
+				location += " / " + line.Label
+			}
+
+			subIdx := -1
+			if entryIdx >= 0 {
+				entry := def.Assignments[entryIdx]
+				if line.Label != "" {
+					_, subIdx, _ = findAssignmentLine(def, entry.Heading, line.Label)
+				} else {
+					for j, n := 0, 0; j < len(entry.Subheadings); j++ {
+						if !entry.Subheadings[j].Positional() {
+							continue
+						}
+						if n == positional {
+							subIdx = j
+							break
+						}
+						n++
+					}
+					positional++
+				}
+			}
+
+			// A line the map has no place for is reported whole; its words
+			// aren't resolved, since free text would flood unknown_names.
+			if subIdx < 0 {
+				unmatched = append(unmatched, unmatchedNoteLine{Heading: section.Heading.Text, Text: line.Text})
+				continue
+			}
+			mapped := def.Assignments[entryIdx]
+			draft = append(draft, importedAssignment{
+				Heading:    mapped.Heading,
+				Subheading: mapped.Subheadings[subIdx].Heading,
+				Slots:      []importedAssignmentSlot{},
+			})
+			lines = append(lines, models.BossAssignment{Heading: mapped.Heading, Subheading: mapped.Subheadings[subIdx].Heading})
+			assignment, model := &draft[len(draft)-1], &lines[len(lines)-1]
+			assignment.SpellID = knownSpell(line.SpellID)
+
+			for _, entry := range line.Entries {
+				character, how := matcher.Match(entry.Name)
+				if character == nil {
+					issue := unknown.add(entry.Name, location)
+					if len(issue.Locations) == 1 {
+						issue.Suggestion = matcher.Suggest(entry.Name)
+					}
+					continue
+				}
+				if how != nameMatchExact && !matchedNames[entry.Name] {
+					matchedNames[entry.Name] = true
+					matches = append(matches, importedNameMatch{Name: entry.Name, CharacterID: character.ID, CharacterName: character.Name, Match: how})
+				}
+				assignment.Slots = append(assignment.Slots, importedAssignmentSlot{CharacterID: character.ID, CharacterName: character.Name, SpellID: knownSpell(entry.SpellID)})
+				model.Slots = append(model.Slots, models.BossAssignmentSlot{Position: len(model.Slots), CharacterID: character.ID})
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"assignments":       draft,
+		"problems":          bossAssignmentProblems(def, lines),
+		"unknown_names":     unknown.list(),
+		"name_matches":      matches,
+		"unknown_spell_ids": unknownSpellIds,
+		"unmatched_lines":   unmatched,
+		"parsed":            parsed,
+	})
+}
@@ -0,0 +1,733 @@
+package handlers
+
+import (
+	"krankenprep/database"
+	"krankenprep/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultScheduleWeeks is how far ahead events are generated when a
+// schedule is created; maxScheduleWeeks caps explicit generate requests.
+const (
+	defaultScheduleWeeks = 4
+	maxScheduleWeeks     = 12
+)
+
+var validSignUpStatuses = map[string]bool{
+	models.SignUpAccept:    true,
+	models.SignUpTentative: true,
+	models.SignUpDecline:   true,
+}
+
+// generateScheduledRaids stamps out the schedule's slots from now through
+// the given number of weeks. Slots that already have an event — including
+// one since moved or deleted (see ScheduledRaid.SlotStartsAt) — are
+// skipped, so it's safe to call repeatedly. Returns how many events were
+// created.
+func generateScheduledRaids(schedule models.RaidSchedule, weeks int) (int, error) {
+	raids, err := scheduledRaidsFor(schedule, time.Now(), weeks)
+	if err != nil || len(raids) == 0 {
+		return 0, err
+	}
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Omit("SignUps").Create(&raids)
+	return int(result.RowsAffected), result.Error
+}
+
+// scheduledRaidsFor builds the events for the schedule's slots in
+// [from, from+weeks). SlotStartsAt is the slot's UTC instant, so the same
+// slot always produces the same (schedule, slot) key however far apart two
+// generate calls are.
+func scheduledRaidsFor(schedule models.RaidSchedule, from time.Time, weeks int) ([]models.ScheduledRaid, error) {
+	starts, err := schedule.OccurrencesBetween(from, from.AddDate(0, 0, 7*weeks))
+	if err != nil {
+		return nil, err
+	}
+	raids := make([]models.ScheduledRaid, len(starts))
+	for i, start := range starts {
+		slot := start.UTC()
+		raids[i] = models.ScheduledRaid{
+			TeamID:          schedule.TeamID,
+			ScheduleID:      &schedule.ID,
+			SlotStartsAt:    &slot,
+			Title:           schedule.Label,
+			StartsAt:        slot,
+			DurationMinutes: schedule.DurationMinutes,
+			Difficulty:      schedule.Difficulty,
+			CreatedByUserID: schedule.CreatedByUserID,
+		}
+	}
+	return raids, nil
+}
+
+type raidSchedulePayload struct {
+	Label           string       `json:"label"`
+	Weekday         time.Weekday `json:"weekday"`
+	StartTime       string       `json:"start_time"`
+	DurationMinutes uint         `json:"duration_minutes"`
+	Timezone        string       `json:"timezone"`
+	Difficulty      string       `json:"difficulty"`
+	Active          *bool        `json:"active"`
+}
+
+// applyRaidSchedulePayload validates payload onto schedule. A blank
+// timezone defaults to the team region's server time, same as lock
+// windows. Writes a 400 and returns false on invalid input.
+func applyRaidSchedulePayload(c *gin.Context, teamId uint, schedule *models.RaidSchedule, payload raidSchedulePayload) bool {
+	if payload.Weekday < time.Sunday || payload.Weekday > time.Saturday {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weekday must be 0 (Sunday) through 6 (Saturday)"})
+		return false
+	}
+	if _, err := models.ParseClockTime(payload.StartTime); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_time must be HH:MM"})
+		return false
+	}
+	if payload.DurationMinutes == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "duration_minutes must be positive"})
+		return false
+	}
+	difficulty, ok := getDifficulty(c, payload.Difficulty)
+	if !ok {
+		return false
+	}
+	timezone := payload.Timezone
+	if timezone == "" {
+		var team models.Team
+		if err := database.DB.First(&team, teamId).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+			return false
+		}
+		timezone = models.DefaultTimezoneForRegion(team.Region)
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timezone"})
+		return false
+	}
+
+	schedule.Label = payload.Label
+	schedule.Weekday = payload.Weekday
+	schedule.StartTime = payload.StartTime
+	schedule.DurationMinutes = payload.DurationMinutes
+	schedule.Timezone = timezone
+	schedule.Difficulty = difficulty
+	if payload.Active != nil {
+		schedule.Active = *payload.Active
+	}
+	return true
+}
+
+func GetRaidSchedules(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	schedules := []models.RaidSchedule{}
+	database.DB.Where("team_id = ?", teamId).Order("weekday, start_time").Find(&schedules)
+
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+// CreateRaidSchedule adds a recurring weekly slot and generates its events
+// for the next few weeks so people can sign up straight away.
+// Loot-council/admin/owner only.
+func CreateRaidSchedule(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload raidSchedulePayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	schedule := models.RaidSchedule{TeamID: uint(teamId), Active: true, CreatedByUserID: user.ID}
+	if !applyRaidSchedulePayload(c, uint(teamId), &schedule, payload) {
+		return
+	}
+
+	if err := database.DB.Create(&schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save raid schedule"})
+		return
+	}
+	created := 0
+	if schedule.Active {
+		if created, err = generateScheduledRaids(schedule, defaultScheduleWeeks); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate raid events"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedule": schedule, "events_created": created})
+}
+
+// UpdateRaidSchedule edits a schedule. Events already generated keep their
+// own time and sign-ups — only future generation picks up the change.
+// Loot-council/admin/owner only.
+func UpdateRaidSchedule(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	scheduleId, err := strconv.ParseUint(c.Param("scheduleId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload raidSchedulePayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	var schedule models.RaidSchedule
+	if err := database.DB.Where("id = ? AND team_id = ?", scheduleId, teamId).First(&schedule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "raid schedule not found"})
+		return
+	}
+	if !applyRaidSchedulePayload(c, uint(teamId), &schedule, payload) {
+		return
+	}
+
+	if err := database.DB.Save(&schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save raid schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteRaidSchedule removes a schedule. Its generated events stay on the
+// calendar as one-offs (ScheduleID goes NULL) so nobody's sign-ups vanish;
+// delete or cancel those individually. Loot-council/admin/owner only.
+func DeleteRaidSchedule(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	scheduleId, err := strconv.ParseUint(c.Param("scheduleId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := database.DB.Where("id = ? AND team_id = ?", scheduleId, teamId).Delete(&models.RaidSchedule{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete raid schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// GenerateScheduledRaids extends the calendar: every active schedule gets
+// its events for the next ?weeks weeks (default 4, max 12). Existing
+// events are left untouched. Loot-council/admin/owner only.
+func GenerateScheduledRaids(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	weeks := defaultScheduleWeeks
+	if q := c.Query("weeks"); q != "" {
+		parsed, err := strconv.Atoi(q)
+		if err != nil || parsed < 1 || parsed > maxScheduleWeeks {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "weeks must be between 1 and 12"})
+			return
+		}
+		weeks = parsed
+	}
+
+	var schedules []models.RaidSchedule
+	database.DB.Where("team_id = ? AND active = ?", teamId, true).Find(&schedules)
+	created := 0
+	for _, schedule := range schedules {
+		n, err := generateScheduledRaids(schedule, weeks)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate raid events"})
+			return
+		}
+		created += n
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events_created": created})
+}
+
+// GetRaidCalendar lists the team's scheduled raids with sign-ups between
+// ?from and ?to (RFC 3339). Defaults to the last day through four weeks
+// out. Visible to every team member.
+func GetRaidCalendar(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	from := time.Now().Add(-24 * time.Hour)
+	if q := c.Query("from"); q != "" {
+		parsed, err := time.Parse(time.RFC3339, q)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+			return
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 0, 7*defaultScheduleWeeks)
+	if q := c.Query("to"); q != "" {
+		parsed, err := time.Parse(time.RFC3339, q)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+			return
+		}
+		to = parsed
+	}
+
+	raids := []models.ScheduledRaid{}
+	database.DB.Preload("SignUps").
+		Where("team_id = ? AND starts_at >= ? AND starts_at < ?", teamId, from, to).
+		Order("starts_at").
+		Find(&raids)
+
+	c.JSON(http.StatusOK, gin.H{"events": raids})
+}
+
+type scheduledRaidPayload struct {
+	Title           string    `json:"title"`
+	StartsAt        time.Time `json:"starts_at"`
+	DurationMinutes uint      `json:"duration_minutes"`
+	Difficulty      string    `json:"difficulty"`
+	Note            string    `json:"note"`
+	Canceled        bool      `json:"canceled"`
+}
+
+// applyScheduledRaidPayload validates payload onto raid. Writes a 400 and
+// returns false on invalid input.
+func applyScheduledRaidPayload(c *gin.Context, raid *models.ScheduledRaid, payload scheduledRaidPayload) bool {
+	if payload.StartsAt.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "starts_at is required"})
+		return false
+	}
+	if payload.DurationMinutes == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "duration_minutes must be positive"})
+		return false
+	}
+	difficulty, ok := getDifficulty(c, payload.Difficulty)
+	if !ok {
+		return false
+	}
+	raid.Title = payload.Title
+	raid.StartsAt = payload.StartsAt.UTC()
+	raid.DurationMinutes = payload.DurationMinutes
+	raid.Difficulty = difficulty
+	raid.Note = payload.Note
+	raid.Canceled = payload.Canceled
+	return true
+}
+
+// CreateScheduledRaid adds a one-off raid night outside any schedule (an
+// extra progression night, a reclear). Loot-council/admin/owner only.
+func CreateScheduledRaid(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload scheduledRaidPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	raid := models.ScheduledRaid{TeamID: uint(teamId), CreatedByUserID: user.ID}
+	if !applyScheduledRaidPayload(c, &raid, payload) {
+		return
+	}
+
+	if err := database.DB.Omit("SignUps").Create(&raid).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save raid event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, raid)
+}
+
+// UpdateScheduledRaid edits a single calendar event — moving one night,
+// or cancelling it with canceled=true. Sign-ups are kept either way, and a
+// moved generated night keeps its original slot, so regenerating doesn't
+// put it back.
+// Loot-council/admin/owner only.
+func UpdateScheduledRaid(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	scheduledRaidId, err := strconv.ParseUint(c.Param("scheduledRaidId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid raid event ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload scheduledRaidPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	var raid models.ScheduledRaid
+	if err := database.DB.Where("id = ? AND team_id = ?", scheduledRaidId, teamId).First(&raid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "raid event not found"})
+		return
+	}
+	if !applyScheduledRaidPayload(c, &raid, payload) {
+		return
+	}
+
+	if err := database.DB.Omit("SignUps").Save(&raid).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save raid event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, raid)
+}
+
+// DeleteScheduledRaid removes a calendar event. It's a soft delete, so a
+// generated night's slot stays taken and regenerating the schedule
+// doesn't bring it back. Loot-council/admin/owner only.
+func DeleteScheduledRaid(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	scheduledRaidId, err := strconv.ParseUint(c.Param("scheduledRaidId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid raid event ID"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := database.DB.Where("id = ? AND team_id = ?", scheduledRaidId, teamId).Delete(&models.ScheduledRaid{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete raid event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+type raidSignUpPayload struct {
+	// PlayerID defaults to the requesting user's claimed player. Signing
+	// up someone else is loot-council/admin/owner only.
+	PlayerID         uint   `json:"player_id"`
+	Status           string `json:"status"`
+	CharacterID      *uint  `json:"character_id"`
+	SpecializationID *uint  `json:"specialization_id"`
+	Note             string `json:"note"`
+}
+
+// SetRaidSignUp records (or changes) a player's answer for a raid night.
+// Accepting or going tentative without a character picks the player's
+// main; a spec, if given, has to be one of that character's class.
+func SetRaidSignUp(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	scheduledRaidId, err := strconv.ParseUint(c.Param("scheduledRaidId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid raid event ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var payload raidSignUpPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	if !validSignUpStatuses[payload.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be accept, tentative or decline"})
+		return
+	}
+
+	var raid models.ScheduledRaid
+	if err := database.DB.Where("id = ? AND team_id = ?", scheduledRaidId, teamId).First(&raid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "raid event not found"})
+		return
+	}
+	if raid.Canceled {
+		c.JSON(http.StatusConflict, gin.H{"error": "raid event is canceled"})
+		return
+	}
+
+	var player models.Player
+	if payload.PlayerID == 0 {
+		if err := database.DB.Where("team_id = ? AND user_id = ?", teamId, user.ID).First(&player).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "claim a roster player before signing up"})
+			return
+		}
+	} else {
+		if err := database.DB.Where("id = ? AND team_id = ?", payload.PlayerID, teamId).First(&player).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "player not found"})
+			return
+		}
+		ownClaim := player.UserID != nil && *player.UserID == user.ID
+		if !ownClaim && !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+	}
+
+	signUp := models.RaidSignUp{ScheduledRaidID: raid.ID, PlayerID: player.ID, Status: payload.Status, Note: payload.Note}
+	if payload.Status != models.SignUpDecline {
+		var character models.Character
+		query := database.DB.Where("player_id = ?", player.ID)
+		if payload.CharacterID != nil {
+			query = query.Where("id = ?", *payload.CharacterID)
+		} else {
+			query = query.Order("is_main desc, id")
+		}
+		if err := query.First(&character).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "character not found for this player"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query character"})
+			return
+		}
+		signUp.CharacterID = &character.ID
+
+		if payload.SpecializationID != nil {
+			var spec models.Specialization
+			if err := database.DB.Preload("Class").First(&spec, *payload.SpecializationID).Error; err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "specialization not found"})
+				return
+			}
+			if spec.Class.Name != character.Class {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "specialization does not match the character's class"})
+				return
+			}
+			signUp.SpecializationID = &spec.ID
+		}
+	}
+
+	err = database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scheduled_raid_id"}, {Name: "player_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "character_id", "specialization_id", "note", "updated_at"}),
+	}).Create(&signUp).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save sign-up"})
+		return
+	}
+
+	database.DB.Where("scheduled_raid_id = ? AND player_id = ?", raid.ID, player.ID).First(&signUp)
+	c.JSON(http.StatusOK, signUp)
+}
+
+// GetProjectedComp projects the raid night's composition from sign-ups:
+// role, armor-type and buff/utility coverage for everyone who accepted,
+// and again with tentatives included. Each sign-up's spec is the one
+// chosen at sign-up, else the character's own spec; roles come from
+// Specialization.Role. Roster players who haven't answered are listed so
+// officers can chase them. Visible to every team member.
+func GetProjectedComp(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	scheduledRaidId, err := strconv.ParseUint(c.Param("scheduledRaidId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid raid event ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var raid models.ScheduledRaid
+	if err := database.DB.Where("id = ? AND team_id = ?", scheduledRaidId, teamId).First(&raid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "raid event not found"})
+		return
+	}
+
+	var signUps []models.RaidSignUp
+	database.DB.Preload("Player").
+		Preload("Character.Specialization.ArmorType").
+		Preload("Character.Specialization.Tags").
+		Preload("Specialization.ArmorType").
+		Preload("Specialization.Tags").
+		Where("scheduled_raid_id = ?", raid.ID).
+		Find(&signUps)
+
+	accepted := newCompProjection()
+	withTentative := newCompProjection()
+	declined := []compMember{}
+	answered := make(map[uint]bool, len(signUps))
+	for _, s := range signUps {
+		answered[s.PlayerID] = true
+		spec := s.Specialization
+		if spec == nil && s.Character != nil {
+			spec = s.Character.Specialization
+		}
+		m := newCompMember(s.Character, spec)
+		m.PlayerID = s.PlayerID
+		m.PlayerName = s.Player.Name
+		m.Status = s.Status
+
+		switch s.Status {
+		case models.SignUpAccept:
+			accepted.add(m)
+			withTentative.add(m)
+		case models.SignUpTentative:
+			withTentative.add(m)
+		default:
+			declined = append(declined, m)
+		}
+	}
+	accepted.finish()
+	withTentative.finish()
+
+	var players []models.Player
+	database.DB.Where("team_id = ?", teamId).Order("name").Find(&players)
+	noResponse := []gin.H{}
+	for _, p := range players {
+		if !answered[p.ID] {
+			noResponse = append(noResponse, gin.H{"player_id": p.ID, "player_name": p.Name})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event":          raid,
+		"accepted":       accepted,
+		"with_tentative": withTentative,
+		"declined":       declined,
+		"no_response":    noResponse,
+	})
+}
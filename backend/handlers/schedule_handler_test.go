@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"krankenprep/models"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestScheduledRaidsFor(t *testing.T) {
+	schedule := models.RaidSchedule{
+		ID:              3,
+		TeamID:          7,
+		Label:           "Progression",
+		Weekday:         time.Wednesday,
+		StartTime:       "20:00",
+		DurationMinutes: 180,
+		Timezone:        "Europe/Paris",
+		Difficulty:      "mythic",
+		CreatedByUserID: 11,
+	}
+	utc := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		from      time.Time
+		weeks     int
+		wantSlots []time.Time
+	}{
+		{"one week", utc(time.March, 23, 12), 1, []time.Time{utc(time.March, 25, 19)}},
+		{"across DST", utc(time.March, 23, 12), 2, []time.Time{utc(time.March, 25, 19), utc(time.April, 1, 18)}},
+		// A later call overlapping the first must produce the same slot key
+		// for the night they share, or the unique index can't dedupe it.
+		{"overlapping later call", utc(time.March, 30, 9), 2, []time.Time{utc(time.April, 1, 18), utc(time.April, 8, 18)}},
+		{"slot already started", utc(time.March, 25, 19).Add(time.Minute), 1, []time.Time{utc(time.April, 1, 18)}},
+		{"no weeks", utc(time.March, 23, 12), 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raids, err := scheduledRaidsFor(schedule, tt.from, tt.weeks)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(raids) != len(tt.wantSlots) {
+				t.Fatalf("got %d raids, want %d", len(raids), len(tt.wantSlots))
+			}
+			for i, raid := range raids {
+				want := tt.wantSlots[i]
+				if raid.SlotStartsAt == nil || !raid.SlotStartsAt.Equal(want) || raid.SlotStartsAt.Location() != time.UTC {
+					t.Errorf("raid %d SlotStartsAt = %v, want %v", i, raid.SlotStartsAt, want)
+				}
+				if !raid.StartsAt.Equal(want) {
+					t.Errorf("raid %d StartsAt = %v, want %v", i, raid.StartsAt, want)
+				}
+				if raid.ScheduleID == nil || *raid.ScheduleID != schedule.ID {
+					t.Errorf("raid %d ScheduleID = %v, want %d", i, raid.ScheduleID, schedule.ID)
+				}
+				if raid.TeamID != schedule.TeamID || raid.Title != schedule.Label || raid.DurationMinutes != schedule.DurationMinutes ||
+					raid.Difficulty != schedule.Difficulty || raid.CreatedByUserID != schedule.CreatedByUserID {
+					t.Errorf("raid %d = %+v, want the schedule's team, label, duration, difficulty and creator", i, raid)
+				}
+			}
+		})
+	}
+
+	if _, err := scheduledRaidsFor(models.RaidSchedule{StartTime: "20:00", Timezone: "Nowhere/Special"}, utc(time.March, 23, 12), 1); err == nil {
+		t.Error("expected an error for an unknown timezone")
+	}
+}
@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"krankenprep/database"
 	"krankenprep/models"
+	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var validTierSlots = map[string]bool{
@@ -89,8 +95,143 @@ type tierSlotPayload struct {
 	Source string `json:"source"`
 }
 
+// saveCharacterTierSlot is the single write path for CharacterTierSlot:
+// it saves the row, appends a CharacterTierSlotChange when the source
+// actually changed, and refreshes the team's TierSetSnapshot for the week.
+// History/snapshot failures are logged rather than failing the save — the
+// slot itself is the source of truth.
+func saveCharacterTierSlot(tierSlot *models.CharacterTierSlot, previousSource, origin string, actingUserId *uint) error {
+	if err := database.DB.Save(tierSlot).Error; err != nil {
+		return fmt.Errorf("saving tier slot: %w", err)
+	}
+	if previousSource == tierSlot.Source {
+		return nil
+	}
+
+	change := models.CharacterTierSlotChange{
+		CharacterID:    tierSlot.CharacterID,
+		Slot:           tierSlot.Slot,
+		PreviousSource: previousSource,
+		Source:         tierSlot.Source,
+		Origin:         origin,
+		ActingUserID:   actingUserId,
+	}
+	if err := database.DB.Create(&change).Error; err != nil {
+		log.Printf("Error recording tier slot change: %v", err)
+	}
+
+	var character models.Character
+	if err := database.DB.Preload("Player").First(&character, tierSlot.CharacterID).Error; err != nil {
+		log.Printf("Error loading character for tier snapshot: %v", err)
+		return nil
+	}
+	if err := refreshTierSetSnapshot(character.Player.TeamID, time.Now()); err != nil {
+		log.Printf("Error refreshing tier snapshot: %v", err)
+	}
+	return nil
+}
+
+// refreshTierSetSnapshot recomputes the team's set-count distribution and
+// upserts it as the current season's snapshot for now's week. A no-op
+// without a current season.
+func refreshTierSetSnapshot(teamId uint, now time.Time) error {
+	seasonId, ok := currentSeasonID()
+	if !ok {
+		return nil
+	}
+
+	characters := teamRosterCharacters(teamId)
+	characterIds := make([]uint, len(characters))
+	for i, ch := range characters {
+		characterIds[i] = ch.ID
+	}
+	setCounts := make(map[uint]int, len(characters))
+	if len(characterIds) > 0 {
+		var slots []models.CharacterTierSlot
+		if err := database.DB.Where("character_id IN ?", characterIds).Find(&slots).Error; err != nil {
+			return fmt.Errorf("fetching tier slots: %w", err)
+		}
+		for _, s := range slots {
+			if models.TierSourceCountsTowardSet(s.Source) {
+				setCounts[s.CharacterID]++
+			}
+		}
+	}
+
+	distribution := make([]uint, len(validTierSlots)+1)
+	snapshot := models.TierSetSnapshot{
+		TeamID:     teamId,
+		SeasonID:   seasonId,
+		WeekStart:  startOfWeekUTC(now),
+		RosterSize: uint(len(characters)),
+	}
+	for _, ch := range characters {
+		count := setCounts[ch.ID]
+		distribution[count]++
+		if count >= 2 {
+			snapshot.With2pc++
+		}
+		if count >= 4 {
+			snapshot.With4pc++
+		}
+	}
+	distributionJSON, err := json.Marshal(distribution)
+	if err != nil {
+		return fmt.Errorf("encoding distribution: %w", err)
+	}
+	snapshot.Distribution = distributionJSON
+
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "team_id"}, {Name: "week_start"}},
+		DoUpdates: clause.AssignmentColumns([]string{"season_id", "roster_size", "distribution", "with_2pc", "with_4pc", "updated_at"}),
+	}).Create(&snapshot).Error
+}
+
+// DefaultTierSnapshotInterval is how often StartTierSnapshotJob refreshes
+// every tracking team's current-week snapshot.
+const DefaultTierSnapshotInterval = time.Hour
+
+// StartTierSnapshotJob starts the background snapshot refresh: every
+// interval, refreshAllTierSetSnapshots rewrites the current week's
+// TierSetSnapshot for each team tracking tier this season. Slot changes
+// already refresh the snapshot as they happen; this picks up what they
+// can't see — roster changes, and the first snapshot of a new week. The
+// first run starts immediately.
+func StartTierSnapshotJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			refreshAllTierSetSnapshots(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// refreshAllTierSetSnapshots refreshes now's snapshot for every team that
+// already has one in the current season. Teams that never recorded a tier
+// change are left alone, so their coverage series still starts at their
+// first change rather than at an empty roster.
+func refreshAllTierSetSnapshots(now time.Time) {
+	seasonId, ok := currentSeasonID()
+	if !ok {
+		return
+	}
+	var teamIds []uint
+	if err := database.DB.Model(&models.TierSetSnapshot{}).Where("season_id = ?", seasonId).Distinct().Pluck("team_id", &teamIds).Error; err != nil {
+		log.Printf("Tier snapshot job: fetching teams: %v", err)
+		return
+	}
+	for _, teamId := range teamIds {
+		if err := refreshTierSetSnapshot(teamId, now); err != nil {
+			log.Printf("Tier snapshot job: refreshing team %d: %v", teamId, err)
+		}
+	}
+}
+
 // UpsertCharacterTierSlot sets a character's current tier-slot status — a
-// snapshot overwritten in place, no history kept.
+// snapshot overwritten in place, with the change itself kept as history
+// (see saveCharacterTierSlot).
 func UpsertCharacterTierSlot(c *gin.Context) {
 	user, ok := getRequestingUser(c)
 	if !ok {
@@ -130,6 +271,7 @@ func UpsertCharacterTierSlot(c *gin.Context) {
 	}
 
 	var tierSlot models.CharacterTierSlot
+	previousSource := models.TierSourceNone
 	result := database.DB.Where("character_id = ? AND slot = ?", characterId, payload.Slot).First(&tierSlot)
 	if result.Error != nil {
 		if result.Error != gorm.ErrRecordNotFound {
@@ -139,29 +281,426 @@ func UpsertCharacterTierSlot(c *gin.Context) {
 		tierSlot = models.CharacterTierSlot{
 			CharacterID: uint(characterId),
 			Slot:        payload.Slot,
-			Source:      payload.Source,
-		}
-		if err := database.DB.Create(&tierSlot).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save tier slot"})
-			return
 		}
 	} else {
-		tierSlot.Source = payload.Source
-		if err := database.DB.Save(&tierSlot).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save tier slot"})
+		previousSource = tierSlot.Source
+	}
+
+	tierSlot.Source = payload.Source
+	if err := saveCharacterTierSlot(&tierSlot, previousSource, models.TierChangeManual, &user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save tier slot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tierSlot)
+}
+
+// tierSlotOrder is the display order for path-to-set slot lists.
+var tierSlotOrder = []string{
+	models.TierSlotHead,
+	models.TierSlotShoulder,
+	models.TierSlotChest,
+	models.TierSlotGloves,
+	models.TierSlotLegs,
+}
+
+// applyTierTokenObtained fills in the character's tier slot when the item
+// they just obtained is a tier token, using the difficulty's upgrade track
+// as the source. It only ever upgrades — a Heroic token doesn't overwrite a
+// slot already tracked as Myth — and returns the saved slot, or nil if the
+// item isn't a token or nothing changed. Unobtaining a token deliberately
+// doesn't revert the slot: by then the piece may have been crafted or
+// equipped, so that's left to a manual edit in the tracker.
+func applyTierTokenObtained(characterId, itemId uint, difficulty string, actingUserId uint) (*models.CharacterTierSlot, error) {
+	var token models.TierToken
+	if err := database.DB.Where("item_id = ?", itemId).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fetching tier token: %w", err)
+	}
+
+	source := models.TierSourceForDifficulty(difficulty)
+	var tierSlot models.CharacterTierSlot
+	err := database.DB.Where("character_id = ? AND slot = ?", characterId, token.Slot).First(&tierSlot).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("fetching tier slot: %w", err)
+	}
+	previousSource := models.TierSourceNone
+	if err == nil {
+		if !models.TierSourceUpgrades(tierSlot.Source, source) {
+			return nil, nil
+		}
+		previousSource = tierSlot.Source
+	}
+
+	tierSlot.CharacterID = characterId
+	tierSlot.Slot = token.Slot
+	tierSlot.Source = source
+	if err := saveCharacterTierSlot(&tierSlot, previousSource, models.TierChangeToken, &actingUserId); err != nil {
+		return nil, err
+	}
+	return &tierSlot, nil
+}
+
+// isTeamCharacter reports whether the character is on the team's roster.
+// Team-scoped routes that take a characterId check this before reading by
+// character alone, so a member of one team can't read another's data by
+// putting their own team in the URL.
+func isTeamCharacter(teamId, characterId uint) bool {
+	var count int64
+	database.DB.Model(&models.Character{}).
+		Joins("JOIN players ON players.id = characters.player_id").
+		Where("characters.id = ? AND players.team_id = ?", characterId, teamId).
+		Count(&count)
+	return count > 0
+}
+
+// catalystCharges returns the character's catalyst weeks for the season,
+// oldest first, and the charges still available across all of them.
+func catalystCharges(characterId, seasonId uint) ([]models.CatalystChargeWeek, int, error) {
+	var weeks []models.CatalystChargeWeek
+	if err := database.DB.Where("character_id = ? AND season_id = ?", characterId, seasonId).
+		Order("week_start").
+		Find(&weeks).Error; err != nil {
+		return nil, 0, fmt.Errorf("fetching catalyst weeks: %w", err)
+	}
+	available := 0
+	for _, w := range weeks {
+		available += int(w.Gained) - int(w.Spent)
+	}
+	return weeks, available, nil
+}
+
+// GetCatalystCharges returns a character's catalyst charges for the current
+// season, week by week, plus how many are available now. Visible to every
+// team member, same as the tier tracker itself.
+func GetCatalystCharges(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	characterId, err := strconv.ParseUint(c.Param("characterId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if !isTeamCharacter(uint(teamId), uint(characterId)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "character not found"})
+		return
+	}
+
+	seasonId, ok := currentSeasonID()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+		return
+	}
+
+	weeks, available, err := catalystCharges(uint(characterId), seasonId)
+	if err != nil {
+		log.Printf("Error loading catalyst charges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch catalyst charges"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"weeks": weeks, "available": available})
+}
+
+type catalystChargePayload struct {
+	// WeekStart is any date (YYYY-MM-DD) in the week being recorded,
+	// normalized to that week's Monday; blank means the current week.
+	WeekStart string `json:"week_start"`
+	Gained    uint   `json:"gained"`
+	Spent     uint   `json:"spent"`
+}
+
+// UpsertCatalystCharges sets a character's gained/spent catalyst charges
+// for one week of the current season, rejecting an edit that would spend
+// more charges than the season has provided. Same access as editing the
+// tier tracker: the owning player or loot council/admin.
+func UpsertCatalystCharges(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	characterId, err := strconv.ParseUint(c.Param("characterId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+		return
+	}
+
+	var payload catalystChargePayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	weekStart := startOfWeekUTC(time.Now())
+	if payload.WeekStart != "" {
+		t, err := time.Parse("2006-01-02", payload.WeekStart)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "week_start must be a date (YYYY-MM-DD)"})
 			return
 		}
+		weekStart = startOfWeekUTC(t)
 	}
 
-	c.JSON(http.StatusOK, tierSlot)
+	if !canAccessCharacterWishlist(uint(teamId), user.ID, uint(characterId)) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if !isTeamCharacter(uint(teamId), uint(characterId)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "character not found"})
+		return
+	}
+
+	seasonId, ok := currentSeasonID()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+		return
+	}
+
+	weeks, available, err := catalystCharges(uint(characterId), seasonId)
+	if err != nil {
+		log.Printf("Error loading catalyst charges: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch catalyst charges"})
+		return
+	}
+
+	week := models.CatalystChargeWeek{CharacterID: uint(characterId), SeasonID: seasonId, WeekStart: weekStart}
+	for _, w := range weeks {
+		if w.WeekStart.Equal(weekStart) {
+			week = w
+			available -= int(w.Gained) - int(w.Spent)
+		}
+	}
+	if available+int(payload.Gained)-int(payload.Spent) < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot spend more catalyst charges than are available"})
+		return
+	}
+
+	week.Gained = payload.Gained
+	week.Spent = payload.Spent
+	if err := database.DB.Save(&week).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save catalyst charges"})
+		return
+	}
+
+	c.JSON(http.StatusOK, week)
+}
+
+type tierTokenWish struct {
+	ItemID     uint   `json:"item_id"`
+	ItemName   string `json:"item_name"`
+	BossID     uint   `json:"boss_id"`
+	BossName   string `json:"boss_name"`
+	Difficulty string `json:"difficulty"`
+}
+
+type tierMissingSlot struct {
+	Slot string `json:"slot"`
+	// InVault means the piece is already waiting in the Great Vault —
+	// counted toward the set as soon as it's claimed.
+	InVault bool `json:"in_vault"`
+	// TokenWishes are unobtained wishes for this slot's token, i.e. which
+	// bosses could still drop it.
+	TokenWishes []tierTokenWish `json:"token_wishes"`
+}
+
+type tierSetPath struct {
+	CharacterID              uint              `json:"character_id"`
+	CharacterName            string            `json:"character_name"`
+	OwnedSlots               []string          `json:"owned_slots"`
+	MissingSlots             []tierMissingSlot `json:"missing_slots"`
+	CatalystChargesAvailable int               `json:"catalyst_charges_available"`
+	// PiecesFor2pc/PiecesFor4pc are how many more tier pieces each set
+	// bonus still needs; *WithoutDrops says whether vault pieces plus
+	// catalyst charges alone are enough to get there.
+	PiecesFor2pc           int  `json:"pieces_for_2pc"`
+	PiecesFor4pc           int  `json:"pieces_for_4pc"`
+	Reaches2pcWithoutDrops bool `json:"reaches_2pc_without_drops"`
+	Reaches4pcWithoutDrops bool `json:"reaches_4pc_without_drops"`
+}
+
+// GetTierSetPaths computes every roster character's path to 2pc and 4pc
+// from the tier tracker: which slots they own, and for each missing one
+// whether it's in the vault and which token wishes could still fill it,
+// plus available catalyst charges (each converts one non-tier piece into
+// any missing slot). Visible to every team member.
+func GetTierSetPaths(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	seasonId, ok := currentSeasonID()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+		return
+	}
+
+	characters := teamRosterCharacters(uint(teamId))
+	characterIds := make([]uint, len(characters))
+	for i, ch := range characters {
+		characterIds[i] = ch.ID
+	}
+
+	paths := []tierSetPath{}
+	if len(characterIds) == 0 {
+		c.JSON(http.StatusOK, gin.H{"tier_set_paths": paths})
+		return
+	}
+
+	var slots []models.CharacterTierSlot
+	database.DB.Where("character_id IN ?", characterIds).Find(&slots)
+	sourceBySlot := make(map[uint]map[string]string)
+	for _, s := range slots {
+		if sourceBySlot[s.CharacterID] == nil {
+			sourceBySlot[s.CharacterID] = map[string]string{}
+		}
+		sourceBySlot[s.CharacterID][s.Slot] = s.Source
+	}
+
+	var catalystRows []struct {
+		CharacterID uint
+		Available   int
+	}
+	database.DB.Model(&models.CatalystChargeWeek{}).
+		Select("character_id, SUM(gained) - SUM(spent) AS available").
+		Where("character_id IN ? AND season_id = ?", characterIds, seasonId).
+		Group("character_id").
+		Scan(&catalystRows)
+	catalystByCharacter := make(map[uint]int, len(catalystRows))
+	for _, r := range catalystRows {
+		catalystByCharacter[r.CharacterID] = r.Available
+	}
+
+	var tokenRows []struct {
+		CharacterID uint
+		Slot        string
+		ItemID      uint
+		ItemName    string
+		BossID      uint
+		BossName    string
+		Difficulty  string
+	}
+	database.DB.Table("character_item_wishes").
+		Select("character_item_wishes.character_id, tier_tokens.slot, items.id AS item_id, items.name AS item_name, bosses.id AS boss_id, bosses.name AS boss_name, character_item_wishes.difficulty").
+		Joins("JOIN tier_tokens ON tier_tokens.item_id = character_item_wishes.item_id").
+		Joins("JOIN items ON items.id = character_item_wishes.item_id").
+		Joins("JOIN bosses ON bosses.id = items.boss_id").
+		Where("character_item_wishes.character_id IN ? AND character_item_wishes.obtained = ? AND items.season_id = ?", characterIds, false, seasonId).
+		Order("bosses.id, character_item_wishes.difficulty").
+		Scan(&tokenRows)
+	type characterSlot struct {
+		characterId uint
+		slot        string
+	}
+	tokenWishes := make(map[characterSlot][]tierTokenWish)
+	for _, r := range tokenRows {
+		key := characterSlot{r.CharacterID, r.Slot}
+		tokenWishes[key] = append(tokenWishes[key], tierTokenWish{
+			ItemID:     r.ItemID,
+			ItemName:   r.ItemName,
+			BossID:     r.BossID,
+			BossName:   r.BossName,
+			Difficulty: r.Difficulty,
+		})
+	}
+
+	for _, ch := range characters {
+		path := tierSetPath{
+			CharacterID:              ch.ID,
+			CharacterName:            ch.Name,
+			OwnedSlots:               []string{},
+			MissingSlots:             []tierMissingSlot{},
+			CatalystChargesAvailable: catalystByCharacter[ch.ID],
+		}
+		inVault := 0
+		for _, slot := range tierSlotOrder {
+			source := sourceBySlot[ch.ID][slot]
+			if models.TierSourceCountsTowardSet(source) {
+				path.OwnedSlots = append(path.OwnedSlots, slot)
+				continue
+			}
+			missing := tierMissingSlot{
+				Slot:        slot,
+				InVault:     source == models.TierSourceInVault,
+				TokenWishes: tokenWishes[characterSlot{ch.ID, slot}],
+			}
+			if missing.TokenWishes == nil {
+				missing.TokenWishes = []tierTokenWish{}
+			}
+			if missing.InVault {
+				inVault++
+			}
+			path.MissingSlots = append(path.MissingSlots, missing)
+		}
+
+		owned := len(path.OwnedSlots)
+		path.PiecesFor2pc = max(0, 2-owned)
+		path.PiecesFor4pc = max(0, 4-owned)
+		// Catalyst charges can only fill missing slots the vault isn't
+		// already covering.
+		guaranteed := inVault + min(max(0, path.CatalystChargesAvailable), len(path.MissingSlots)-inVault)
+		path.Reaches2pcWithoutDrops = guaranteed >= path.PiecesFor2pc
+		path.Reaches4pcWithoutDrops = guaranteed >= path.PiecesFor4pc
+		paths = append(paths, path)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tier_set_paths": paths})
+}
+
+// tierSimEntriesFor scopes a TierSimEntry query to the rows a team reads
+// for a season: its own imported rows if it has any that season, else the
+// shared hand-maintained rows (TeamID 0). Whole-season rather than per-spec
+// fallback, so an import never ends up mixed with older shared numbers.
+func tierSimEntriesFor(teamId, seasonId uint) *gorm.DB {
+	var own int64
+	database.DB.Model(&models.TierSimEntry{}).Where("team_id = ? AND season_id = ?", teamId, seasonId).Count(&own)
+	if own == 0 {
+		teamId = 0
+	}
+	return database.DB.Where("team_id = ? AND season_id = ?", teamId, seasonId)
 }
 
 // GetTierSimData returns the current season's community tier-sim benefit
 // data, joined with each entry's Specialization/Class/ArmorType for grouping
 // and coloring client-side, plus a last_updated timestamp (the newest
 // UpdatedAt among the returned rows) so the UI can show how fresh the data
-// is. This dataset is read-only from the app's side — populated by hand
-// directly in the database, no import endpoint exists.
+// is. The dataset itself is maintained through ImportTierSimEntries.
 func GetTierSimData(c *gin.Context) {
 	user, ok := getRequestingUser(c)
 	if !ok {
@@ -186,7 +725,7 @@ func GetTierSimData(c *gin.Context) {
 	}
 
 	var entries []models.TierSimEntry
-	database.DB.Where("season_id = ?", season.Id).
+	tierSimEntriesFor(uint(teamId), season.Id).
 		Preload("Specialization.Class").
 		Preload("Specialization.ArmorType").
 		Find(&entries)
@@ -201,3 +740,339 @@ func GetTierSimData(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"tier_sim_entries": entries, "last_updated": lastUpdated})
 }
+
+type tierTokenCandidate struct {
+	CharacterID   uint    `json:"character_id"`
+	CharacterName string  `json:"character_name"`
+	PlayerName    string  `json:"player_name"`
+	SpecID        uint    `json:"specialization_id"`
+	SpecName      string  `json:"specialization_name"`
+	BuildLabel    *string `json:"build_label"`
+	// BuildAssumed is set when the character's own BuildLabel had no sim
+	// row, so the spec's best-gaining build was used instead.
+	BuildAssumed bool `json:"build_assumed"`
+	SetCount     int  `json:"set_count"`
+	// OwnsSlot means the character already has an owned piece in the
+	// token's slot — the token only upgrades its track, the set count
+	// doesn't move, so MarginalGain is 0.
+	OwnsSlot bool `json:"owns_slot"`
+	// NextBreakpoint is 2 or 4 (0 once at 4pc); PiecesToBreakpoint counts
+	// this token. GainAtBreakpoint is the raw score gain on reaching it,
+	// MarginalGain that gain split evenly across the pieces still needed.
+	NextBreakpoint      int      `json:"next_breakpoint"`
+	PiecesToBreakpoint  int      `json:"pieces_to_breakpoint"`
+	CompletesBreakpoint bool     `json:"completes_breakpoint"`
+	GainAtBreakpoint    *float64 `json:"gain_at_breakpoint"`
+	PercentAtBreakpoint *float64 `json:"percent_at_breakpoint"`
+	MarginalGain        *float64 `json:"marginal_gain"`
+}
+
+// tierBreakpointGain returns the raw and percent score gain from the set
+// count's current bonus to its next breakpoint (0 past 4pc).
+func tierBreakpointGain(entry models.TierSimEntry, setCount int) (gain float64, percent float64) {
+	from, to := entry.Score0pc, entry.Score2pc
+	switch {
+	case setCount >= 4:
+		return 0, 0
+	case setCount >= 2:
+		from, to = entry.Score2pc, entry.Score4pc
+	}
+	gain = to - from
+	if from != 0 {
+		percent = gain / from * 100
+	}
+	return gain, percent
+}
+
+// GetTierTokenRanking ranks the roster characters who could use a tier
+// token — spec wears armor_type_id — by how much raid DPS the piece is
+// worth to them: the score gain from their current set bonus to the next
+// breakpoint (2pc, then 4pc) per TierSimEntry, divided by the pieces still
+// needed to get there, so a character one piece from 4pc outranks one who
+// needs three. Characters already owning the slot, or with no sim data for
+// their spec, sort last. Loot-council/admin/owner only, like the other
+// distribution views.
+func GetTierTokenRanking(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	armorTypeId, err := strconv.ParseUint(c.Query("armor_type_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing armor_type_id"})
+		return
+	}
+
+	slot := c.Query("slot")
+	if !validTierSlots[slot] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tier slot"})
+		return
+	}
+
+	if !isLootCouncilOrAdmin(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	seasonId, ok := currentSeasonID()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+		return
+	}
+
+	var specs []models.Specialization
+	database.DB.Where("armor_type_id = ?", armorTypeId).Find(&specs)
+	specById := make(map[uint]models.Specialization, len(specs))
+	specIds := make([]uint, len(specs))
+	for i, s := range specs {
+		specById[s.ID] = s
+		specIds[i] = s.ID
+	}
+
+	var eligible []models.Character
+	for _, ch := range teamRosterCharacters(uint(teamId)) {
+		if ch.SpecializationID == nil {
+			continue
+		}
+		if _, ok := specById[*ch.SpecializationID]; ok {
+			eligible = append(eligible, ch)
+		}
+	}
+
+	candidates := []tierTokenCandidate{}
+	if len(eligible) == 0 {
+		c.JSON(http.StatusOK, gin.H{"candidates": candidates})
+		return
+	}
+
+	characterIds := make([]uint, len(eligible))
+	for i, ch := range eligible {
+		characterIds[i] = ch.ID
+	}
+	var slots []models.CharacterTierSlot
+	database.DB.Where("character_id IN ?", characterIds).Find(&slots)
+	setCounts := make(map[uint]int)
+	ownsSlot := make(map[uint]bool)
+	for _, s := range slots {
+		if !models.TierSourceCountsTowardSet(s.Source) {
+			continue
+		}
+		setCounts[s.CharacterID]++
+		if s.Slot == slot {
+			ownsSlot[s.CharacterID] = true
+		}
+	}
+
+	var simEntries []models.TierSimEntry
+	tierSimEntriesFor(uint(teamId), seasonId).Where("specialization_id IN ?", specIds).Order("build_label").Find(&simEntries)
+	entriesBySpec := make(map[uint][]models.TierSimEntry)
+	for _, e := range simEntries {
+		entriesBySpec[e.SpecializationID] = append(entriesBySpec[e.SpecializationID], e)
+	}
+
+	for _, ch := range eligible {
+		spec := specById[*ch.SpecializationID]
+		candidate := tierTokenCandidate{
+			CharacterID:   ch.ID,
+			CharacterName: ch.Name,
+			PlayerName:    ch.Player.Name,
+			SpecID:        spec.ID,
+			SpecName:      spec.Name,
+			SetCount:      setCounts[ch.ID],
+			OwnsSlot:      ownsSlot[ch.ID],
+		}
+		switch {
+		case candidate.SetCount < 2:
+			candidate.NextBreakpoint = 2
+		case candidate.SetCount < 4:
+			candidate.NextBreakpoint = 4
+		}
+		if candidate.NextBreakpoint > 0 {
+			candidate.PiecesToBreakpoint = candidate.NextBreakpoint - candidate.SetCount
+			candidate.CompletesBreakpoint = candidate.PiecesToBreakpoint == 1 && !candidate.OwnsSlot
+		}
+
+		var chosen *models.TierSimEntry
+		var bestGain float64
+		for i, e := range entriesBySpec[spec.ID] {
+			gain, _ := tierBreakpointGain(e, candidate.SetCount)
+			if e.BuildLabel == ch.BuildLabel {
+				chosen = &entriesBySpec[spec.ID][i]
+				candidate.BuildAssumed = false
+				break
+			}
+			if chosen == nil || gain > bestGain {
+				chosen = &entriesBySpec[spec.ID][i]
+				bestGain = gain
+				candidate.BuildAssumed = true
+			}
+		}
+		if chosen != nil {
+			label := chosen.BuildLabel
+			candidate.BuildLabel = &label
+			gain, percent := tierBreakpointGain(*chosen, candidate.SetCount)
+			marginal := 0.0
+			if !candidate.OwnsSlot && candidate.PiecesToBreakpoint > 0 {
+				marginal = gain / float64(candidate.PiecesToBreakpoint)
+			}
+			candidate.GainAtBreakpoint = &gain
+			candidate.PercentAtBreakpoint = &percent
+			candidate.MarginalGain = &marginal
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.OwnsSlot != b.OwnsSlot {
+			return !a.OwnsSlot
+		}
+		if (a.MarginalGain == nil) != (b.MarginalGain == nil) {
+			return a.MarginalGain != nil
+		}
+		if a.MarginalGain != nil && *a.MarginalGain != *b.MarginalGain {
+			return *a.MarginalGain > *b.MarginalGain
+		}
+		return a.CompletesBreakpoint && !b.CompletesBreakpoint
+	})
+
+	c.JSON(http.StatusOK, gin.H{"candidates": candidates})
+}
+
+// GetCharacterTierSlotHistory returns every recorded change to a
+// character's tier slots, newest first. Visible to every team member, same
+// as the tracker itself.
+func GetCharacterTierSlotHistory(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	characterId, err := strconv.ParseUint(c.Param("characterId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if !isTeamCharacter(uint(teamId), uint(characterId)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "character not found"})
+		return
+	}
+
+	changes := []models.CharacterTierSlotChange{}
+	database.DB.Where("character_id = ?", characterId).Order("id DESC").Find(&changes)
+
+	c.JSON(http.StatusOK, gin.H{"history": changes})
+}
+
+type tierCoverageWeek struct {
+	WeekStart    time.Time       `json:"week_start"`
+	RosterSize   uint            `json:"roster_size"`
+	Distribution json.RawMessage `json:"distribution"`
+	With2pc      uint            `json:"with_2pc"`
+	With4pc      uint            `json:"with_4pc"`
+	Percent2pc   float64         `json:"percent_2pc"`
+	Percent4pc   float64         `json:"percent_4pc"`
+	// CarriedForward marks a week with no tier changes, filled in from the
+	// previous week's snapshot.
+	CarriedForward bool `json:"carried_forward"`
+}
+
+// GetTierSetCoverage returns the team's 2pc/4pc coverage week by week for a
+// season (default current), from the first snapshot through the current
+// week (or the last snapshot, for a past season) — weeks without a change
+// repeat the previous week's numbers so the series has no gaps. Read-only:
+// snapshots are written by slot changes and StartTierSnapshotJob, so a
+// roster change with no tier edit since shows up within the job's interval.
+// Visible to every team member.
+func GetTierSetCoverage(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	currentId, hasCurrent := currentSeasonID()
+	seasonId := currentId
+	if q := c.Query("season_id"); q != "" {
+		parsed, err := strconv.ParseUint(q, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+			return
+		}
+		seasonId = uint(parsed)
+	} else if !hasCurrent {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+		return
+	}
+
+	isCurrent := hasCurrent && seasonId == currentId
+	now := time.Now()
+
+	var snapshots []models.TierSetSnapshot
+	database.DB.Where("team_id = ? AND season_id = ?", teamId, seasonId).Order("week_start").Find(&snapshots)
+
+	weeks := []tierCoverageWeek{}
+	if len(snapshots) == 0 {
+		c.JSON(http.StatusOK, gin.H{"season_id": seasonId, "weeks": weeks})
+		return
+	}
+
+	last := snapshots[len(snapshots)-1].WeekStart
+	if isCurrent {
+		last = startOfWeekUTC(now)
+	}
+	next := 0
+	var previous *models.TierSetSnapshot
+	for week := snapshots[0].WeekStart; !week.After(last); week = week.AddDate(0, 0, 7) {
+		carried := true
+		if next < len(snapshots) && snapshots[next].WeekStart.Equal(week) {
+			previous = &snapshots[next]
+			next++
+			carried = false
+		}
+		entry := tierCoverageWeek{
+			WeekStart:      week,
+			RosterSize:     previous.RosterSize,
+			Distribution:   json.RawMessage(previous.Distribution),
+			With2pc:        previous.With2pc,
+			With4pc:        previous.With4pc,
+			CarriedForward: carried,
+		}
+		if entry.RosterSize > 0 {
+			entry.Percent2pc = float64(entry.With2pc) / float64(entry.RosterSize) * 100
+			entry.Percent4pc = float64(entry.With4pc) / float64(entry.RosterSize) * 100
+		}
+		weeks = append(weeks, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"season_id": seasonId, "weeks": weeks})
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"krankenprep/utilities"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tierSimCSVColumns maps a normalized header (see utilities.NameMatchKey) to
+// the field it fills — several spellings per field since the community
+// spreadsheet's headers have changed between seasons ("0p ST" vs "0pc").
+var tierSimCSVColumns = map[string]string{
+	"class":          "class",
+	"spec":           "spec",
+	"specialization": "spec",
+	"build":          "build",
+	"buildlabel":     "build",
+	"herotalent":     "build",
+	"0p":             "0pc",
+	"0pc":            "0pc",
+	"0pst":           "0pc",
+	"score0pc":       "0pc",
+	"2p":             "2pc",
+	"2pc":            "2pc",
+	"2pst":           "2pc",
+	"score2pc":       "2pc",
+	"4p":             "4pc",
+	"4pc":            "4pc",
+	"4pst":           "4pc",
+	"score4pc":       "4pc",
+}
+
+var requiredTierSimColumns = []string{"class", "spec", "0pc", "2pc", "4pc"}
+
+type tierSimImportPayload struct {
+	CSV string `json:"csv"`
+	// SeasonID defaults to the current season.
+	SeasonID *uint `json:"season_id"`
+}
+
+type tierSimImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type tierSimImportPreview struct {
+	SeasonID  uint                         `json:"season_id"`
+	Created   uint                         `json:"created"`
+	Updated   uint                         `json:"updated"`
+	Unchanged uint                         `json:"unchanged"`
+	Changes   []models.TierSimImportChange `json:"changes"`
+	Errors    []tierSimImportRowError      `json:"errors"`
+}
+
+// buildTierSimImport does everything preview and import share: owner
+// check, season resolution, CSV parsing, spec resolution and the diff
+// against existing entries. Writes its own error response and returns
+// ok=false on failure; per-row problems are collected in preview.Errors
+// instead.
+func buildTierSimImport(c *gin.Context) (preview tierSimImportPreview, teamId uint, user *models.User, ok bool) {
+	user, userOk := getRequestingUser(c)
+	if !userOk {
+		return preview, 0, nil, false
+	}
+
+	parsedTeamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return preview, 0, nil, false
+	}
+	teamId = uint(parsedTeamId)
+
+	if !isTeamOwner(teamId, user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return preview, 0, nil, false
+	}
+
+	var payload tierSimImportPayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return preview, 0, nil, false
+	}
+
+	if payload.SeasonID != nil {
+		var season models.Season
+		if err := database.DB.First(&season, *payload.SeasonID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "season not found"})
+			return preview, 0, nil, false
+		}
+		preview.SeasonID = season.Id
+	} else {
+		seasonId, ok := currentSeasonID()
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no current season set"})
+			return preview, 0, nil, false
+		}
+		preview.SeasonID = seasonId
+	}
+
+	reader := csv.NewReader(strings.NewReader(payload.CSV))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid CSV: %v", err)})
+		return preview, 0, nil, false
+	}
+	if len(records) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV must have a header row and at least one data row"})
+		return preview, 0, nil, false
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		if field, known := tierSimCSVColumns[utilities.NameMatchKey(header)]; known {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	for _, field := range requiredTierSimColumns {
+		if _, found := columns[field]; !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("CSV is missing a %s column", field)})
+			return preview, 0, nil, false
+		}
+	}
+
+	var specs []models.Specialization
+	database.DB.Preload("Class").Find(&specs)
+	specByName := make(map[string]models.Specialization, len(specs))
+	for _, s := range specs {
+		specByName[utilities.NameMatchKey(s.Class.Name)+"|"+utilities.NameMatchKey(s.Name)] = s
+	}
+
+	var existing []models.TierSimEntry
+	database.DB.Where("team_id = ? AND season_id = ?", teamId, preview.SeasonID).Find(&existing)
+	type entryKey struct {
+		specId     uint
+		buildLabel string
+	}
+	existingByKey := make(map[entryKey]models.TierSimEntry, len(existing))
+	for _, e := range existing {
+		existingByKey[entryKey{e.SpecializationID, e.BuildLabel}] = e
+	}
+
+	cell := func(record []string, field string) string {
+		i, found := columns[field]
+		if !found || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	preview.Changes = []models.TierSimImportChange{}
+	preview.Errors = []tierSimImportRowError{}
+	seen := make(map[entryKey]int)
+	for i, record := range records[1:] {
+		line := i + 2
+		className, specName := cell(record, "class"), cell(record, "spec")
+		if className == "" && specName == "" {
+			continue // blank spacer row, common in the spreadsheet
+		}
+		spec, found := specByName[utilities.NameMatchKey(className)+"|"+utilities.NameMatchKey(specName)]
+		if !found {
+			preview.Errors = append(preview.Errors, tierSimImportRowError{Line: line, Error: fmt.Sprintf("unknown spec %q %q", specName, className)})
+			continue
+		}
+
+		var scores [3]float64
+		var scoreErr error
+		for j, field := range []string{"0pc", "2pc", "4pc"} {
+			if scores[j], scoreErr = utilities.ParseSpreadsheetNumber(cell(record, field)); scoreErr != nil {
+				preview.Errors = append(preview.Errors, tierSimImportRowError{Line: line, Error: fmt.Sprintf("invalid %s score %q", field, cell(record, field))})
+				break
+			}
+		}
+		if scoreErr != nil {
+			continue
+		}
+
+		buildLabel := cell(record, "build")
+		key := entryKey{spec.ID, buildLabel}
+		if firstLine, dup := seen[key]; dup {
+			preview.Errors = append(preview.Errors, tierSimImportRowError{Line: line, Error: fmt.Sprintf("duplicate of line %d", firstLine)})
+			continue
+		}
+		seen[key] = line
+
+		change := models.TierSimImportChange{
+			Action:           models.TierSimImportCreate,
+			SpecializationID: spec.ID,
+			ClassName:        spec.Class.Name,
+			SpecName:         spec.Name,
+			BuildLabel:       buildLabel,
+			Score0pc:         scores[0],
+			Score2pc:         scores[1],
+			Score4pc:         scores[2],
+		}
+		if prev, exists := existingByKey[key]; exists {
+			change.Action = models.TierSimImportUpdate
+			if prev.Score0pc == scores[0] && prev.Score2pc == scores[1] && prev.Score4pc == scores[2] {
+				change.Action = models.TierSimImportUnchanged
+			} else {
+				change.Previous0pc, change.Previous2pc, change.Previous4pc = &prev.Score0pc, &prev.Score2pc, &prev.Score4pc
+			}
+		}
+		switch change.Action {
+		case models.TierSimImportCreate:
+			preview.Created++
+		case models.TierSimImportUpdate:
+			preview.Updated++
+		default:
+			preview.Unchanged++
+		}
+		preview.Changes = append(preview.Changes, change)
+	}
+
+	return preview, teamId, user, true
+}
+
+// PreviewTierSimImport parses a community tier-sim spreadsheet export and
+// reports what importing it would do — per row, create/update/unchanged
+// with the scores being replaced, plus per-line errors — without writing
+// anything. Owner only.
+func PreviewTierSimImport(c *gin.Context) {
+	preview, _, _, ok := buildTierSimImport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"preview": preview})
+}
+
+// ImportTierSimEntries applies a tier-sim CSV to the team's own entries:
+// every row is upserted on idx_season_spec_build (team, season, spec, build
+// label), and the import is recorded as a TierSimImport. Other teams keep
+// reading their own or the shared rows. All-or-nothing — any row error
+// rejects the whole file with the same errors preview would show, so a
+// half-applied spreadsheet never ends up mixed with last week's numbers.
+// Entries absent from the CSV are left alone. Owner only.
+func ImportTierSimEntries(c *gin.Context) {
+	preview, teamId, user, ok := buildTierSimImport(c)
+	if !ok {
+		return
+	}
+	if len(preview.Errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV has invalid rows", "preview": preview})
+		return
+	}
+
+	changesJSON, err := json.Marshal(preview.Changes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record import"})
+		return
+	}
+	record := models.TierSimImport{
+		SeasonID:         preview.SeasonID,
+		TeamID:           teamId,
+		ImportedByUserID: user.ID,
+		ImportedByBTag:   user.BTag,
+		Created:          preview.Created,
+		Updated:          preview.Updated,
+		Unchanged:        preview.Unchanged,
+		Changes:          changesJSON,
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, change := range preview.Changes {
+			if change.Action == models.TierSimImportUnchanged {
+				continue
+			}
+			entry := models.TierSimEntry{
+				TeamID:           teamId,
+				SeasonID:         preview.SeasonID,
+				SpecializationID: change.SpecializationID,
+				BuildLabel:       change.BuildLabel,
+				Score0pc:         change.Score0pc,
+				Score2pc:         change.Score2pc,
+				Score4pc:         change.Score4pc,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "team_id"}, {Name: "season_id"}, {Name: "specialization_id"}, {Name: "build_label"}},
+				DoUpdates: clause.AssignmentColumns([]string{"score_0pc", "score_2pc", "score_4pc", "updated_at"}),
+			}).Create(&entry).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		log.Printf("Error importing tier sim entries: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import tier sim entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"import": record})
+}
+
+// GetTierSimImports lists the team's recorded imports for a season
+// (default current), newest first, so anyone reading the team's tier-sim
+// numbers can see who last changed them and when. Visible to every team member.
+func GetTierSimImports(c *gin.Context) {
+	user, ok := getRequestingUser(c)
+	if !ok {
+		return
+	}
+
+	teamId, err := strconv.ParseUint(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if !isTeamMember(uint(teamId), user.ID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var seasonId uint
+	if q := c.Query("season_id"); q != "" {
+		parsed, err := strconv.ParseUint(q, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season ID"})
+			return
+		}
+		seasonId = uint(parsed)
+	} else {
+		id, ok := currentSeasonID()
+		if !ok {
+			c.JSON(http.StatusOK, gin.H{"imports": []models.TierSimImport{}})
+			return
+		}
+		seasonId = id
+	}
+
+	imports := []models.TierSimImport{}
+	database.DB.Where("team_id = ? AND season_id = ?", teamId, seasonId).Order("id DESC").Find(&imports)
+
+	c.JSON(http.StatusOK, gin.H{"imports": imports})
+}
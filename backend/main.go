@@ -28,6 +28,13 @@ func main() {
 		log.Fatalf("Failed to initialize Descope client: %v", err)
 	}
 
+	if bnetClient, err := utilities.NewBnetClientFromEnv(); err != nil {
+		log.Printf("Gear sync disabled: %v", err)
+	} else {
+		handlers.StartGearSyncJob(bnetClient, handlers.DefaultGearSyncInterval)
+	}
+	handlers.StartTierSnapshotJob(handlers.DefaultTierSnapshotInterval)
+
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
 		AllowOrigins: []string{
@@ -67,6 +74,7 @@ func main() {
 		protected.GET("/servers", handlers.GetServers)
 		protected.GET("/regions", handlers.GetRegions)
 		protected.GET("/classes", handlers.GetClasses)
+		protected.GET("/classes/coverage", handlers.GetRaidCoverageChecklist)
 		protected.POST("/team", handlers.CreateTeam)
 		protected.PUT("/teams/:teamId", handlers.UpdateTeam)
 		protected.POST("/teams/wowaudit/test", handlers.TestWowAuditIntegration)
@@ -90,6 +98,28 @@ func main() {
 		protected.PUT("/characters/:characterId", handlers.UpdateCharacter)
 		protected.DELETE("/characters/:characterId", handlers.DeleteCharacter)
 
+		// Raid attendance endpoints
+		protected.GET("/teams/:teamId/raid-events", handlers.GetRaidEvents)
+		protected.POST("/teams/:teamId/raid-events", handlers.CreateRaidEvent)
+		protected.PUT("/teams/:teamId/raid-events/:eventId", handlers.UpdateRaidEvent)
+		protected.DELETE("/teams/:teamId/raid-events/:eventId", handlers.DeleteRaidEvent)
+		protected.PUT("/teams/:teamId/raid-events/:eventId/attendance", handlers.UpsertRaidAttendance)
+		protected.GET("/teams/:teamId/attendance", handlers.GetSeasonAttendance)
+
+		// Raid schedule and sign-up endpoints
+		protected.GET("/teams/:teamId/raid-schedules", handlers.GetRaidSchedules)
+		protected.POST("/teams/:teamId/raid-schedules", handlers.CreateRaidSchedule)
+		protected.POST("/teams/:teamId/raid-schedules/generate", handlers.GenerateScheduledRaids)
+		protected.PUT("/teams/:teamId/raid-schedules/:scheduleId", handlers.UpdateRaidSchedule)
+		protected.DELETE("/teams/:teamId/raid-schedules/:scheduleId", handlers.DeleteRaidSchedule)
+		protected.GET("/teams/:teamId/calendar", handlers.GetRaidCalendar)
+		protected.POST("/teams/:teamId/calendar", handlers.CreateScheduledRaid)
+		protected.PUT("/teams/:teamId/calendar/:scheduledRaidId", handlers.UpdateScheduledRaid)
+		protected.DELETE("/teams/:teamId/calendar/:scheduledRaidId", handlers.DeleteScheduledRaid)
+		protected.PUT("/teams/:teamId/calendar/:scheduledRaidId/signup", handlers.SetRaidSignUp)
+		protected.GET("/teams/:teamId/calendar/:scheduledRaidId/comp", handlers.GetProjectedComp)
+		protected.POST("/teams/:teamId/comp", handlers.BuildRaidComp)
+
 		// Section endpoints
 		protected.POST("/sections", handlers.CreateSection)
 		protected.PUT("/sections/:sectionId", handlers.UpdateSection)
@@ -110,6 +140,13 @@ func main() {
 		// Assignment note endpoints
 		protected.GET("/teams/:teamId/assignment-note/boss/:bossId", handlers.GetAssignmentNote)
 		protected.PUT("/teams/:teamId/assignment-note/boss/:bossId", handlers.UpsertAssignmentNote)
+		protected.GET("/teams/:teamId/assignment-note/boss/:bossId/validate", handlers.ValidateAssignmentNote)
+		protected.GET("/teams/:teamId/assignments/boss/:bossId", handlers.GetBossAssignments)
+		protected.PUT("/teams/:teamId/assignments/boss/:bossId", handlers.SetBossAssignments)
+		protected.GET("/teams/:teamId/assignments/boss/:bossId/note", handlers.GenerateAssignmentNote)
+		protected.POST("/teams/:teamId/assignments/boss/:bossId/import", handlers.ImportRaidNote)
+		protected.GET("/teams/:teamId/boss-roster/boss/:bossId", handlers.GetBossRoster)
+		protected.PUT("/teams/:teamId/boss-roster/boss/:bossId", handlers.SetBossRoster)
 
 		// Loot wishlist endpoints
 		protected.GET("/teams/:teamId/loot/boss/:bossId", handlers.GetBossLoot)
@@ -122,15 +159,63 @@ func main() {
 		protected.GET("/teams/:teamId/loot/items/search", handlers.SearchLootItems)
 		protected.GET("/teams/:teamId/loot/items/:itemId/overview", handlers.GetItemRollOverview)
 		protected.GET("/teams/:teamId/loot/audit-log", handlers.GetLootAuditLog)
+		protected.GET("/teams/:teamId/loot/audit-log/export", handlers.ExportLootAuditLog)
+		protected.GET("/teams/:teamId/loot/audit-log/summary", handlers.GetLootAuditLogSummary)
+		protected.GET("/teams/:teamId/loot/fairness-report", handlers.GetLootFairnessReport)
+		protected.GET("/teams/:teamId/loot/characters/:characterId/summary", handlers.GetCharacterLootSummary)
 		protected.GET("/teams/:teamId/loot/characters/:characterId/priorities", handlers.GetCharacterBossPriorities)
 		protected.PUT("/teams/:teamId/loot/characters/:characterId/priorities", handlers.ReorderBossPriorities)
+		protected.POST("/teams/:teamId/loot/characters/:characterId/droptimizer/preview", handlers.PreviewDroptimizerWishes)
+		protected.POST("/teams/:teamId/loot/characters/:characterId/droptimizer/import", handlers.ImportDroptimizerWishes)
+		protected.GET("/teams/:teamId/loot/characters/:characterId/bonus-roll-plan", handlers.GetBonusRollPlan)
+		protected.GET("/teams/:teamId/loot/lock-windows", handlers.GetWishlistLockWindows)
+		protected.POST("/teams/:teamId/loot/lock-windows", handlers.CreateWishlistLockWindow)
+		protected.DELETE("/teams/:teamId/loot/lock-windows/:lockWindowId", handlers.DeleteWishlistLockWindow)
 		protected.GET("/teams/:teamId/loot/tier-tracker", handlers.GetTeamTierSlots)
 		protected.PUT("/teams/:teamId/loot/tier-tracker/characters/:characterId", handlers.UpsertCharacterTierSlot)
+		protected.GET("/teams/:teamId/loot/tier-tracker/characters/:characterId/catalyst", handlers.GetCatalystCharges)
+		protected.PUT("/teams/:teamId/loot/tier-tracker/characters/:characterId/catalyst", handlers.UpsertCatalystCharges)
+		protected.GET("/teams/:teamId/loot/tier-tracker/characters/:characterId/history", handlers.GetCharacterTierSlotHistory)
+		protected.GET("/teams/:teamId/loot/tier-tracker/coverage", handlers.GetTierSetCoverage)
+		protected.GET("/teams/:teamId/loot/tier-tracker/paths", handlers.GetTierSetPaths)
+		protected.GET("/teams/:teamId/gear-sync", handlers.GetTeamGearSync)
+		protected.POST("/teams/:teamId/gear-sync", handlers.SyncTeamGear)
+		protected.GET("/teams/:teamId/loot/tier-tracker/token-ranking", handlers.GetTierTokenRanking)
 		protected.GET("/teams/:teamId/loot/tier-sim", handlers.GetTierSimData)
+		protected.GET("/teams/:teamId/loot/tier-sim/imports", handlers.GetTierSimImports)
+		protected.POST("/teams/:teamId/loot/tier-sim/import/preview", handlers.PreviewTierSimImport)
+		protected.POST("/teams/:teamId/loot/tier-sim/import", handlers.ImportTierSimEntries)
+		protected.PUT("/teams/:teamId/loot-mode", handlers.UpdateLootMode)
+		protected.GET("/teams/:teamId/epgp/standings", handlers.GetEPGPStandings)
+		protected.PUT("/teams/:teamId/epgp/settings", handlers.UpdateEPGPSettings)
+		protected.POST("/teams/:teamId/epgp/ep", handlers.AwardEP)
+		protected.POST("/teams/:teamId/epgp/gp", handlers.ChargeGP)
+		protected.POST("/teams/:teamId/epgp/adjust", handlers.AdjustEPGP)
+		protected.POST("/teams/:teamId/epgp/decay", handlers.ApplyEPGPDecay)
+		protected.GET("/teams/:teamId/epgp/characters/:characterId/ledger", handlers.GetEPGPLedger)
 		protected.GET("/teams/:teamId/boe", handlers.GetBoeSales)
 		protected.POST("/teams/:teamId/boe", handlers.CreateBoeSale)
+		protected.GET("/teams/:teamId/boe/suggestions", handlers.GetBoeSuggestions)
+		protected.GET("/teams/:teamId/boe/export", handlers.ExportBoeSales)
+		protected.POST("/teams/:teamId/boe/import/preview", handlers.PreviewBoeImport)
+		protected.POST("/teams/:teamId/boe/import", handlers.ImportBoeSales)
 		protected.PUT("/teams/:teamId/boe/:boeSaleId", handlers.UpdateBoeSale)
 		protected.DELETE("/teams/:teamId/boe/:boeSaleId", handlers.DeleteBoeSale)
+		protected.GET("/teams/:teamId/boe/payouts", handlers.GetBoePayouts)
+		protected.POST("/teams/:teamId/boe/payouts", handlers.CreateBoePayout)
+		protected.DELETE("/teams/:teamId/boe/payouts/:payoutId", handlers.DeleteBoePayout)
+		protected.GET("/teams/:teamId/boe/balances", handlers.GetBoeBalances)
+		protected.GET("/teams/:teamId/boe/outstanding", handlers.GetBoeOutstanding)
+		protected.GET("/teams/:teamId/boe/analytics", handlers.GetBoeAnalytics)
+		protected.GET("/teams/:teamId/boe/analytics/compare", handlers.GetBoeSeasonComparison)
+		protected.GET("/teams/:teamId/boe/split-rules", handlers.GetBoeSplitRules)
+		protected.POST("/teams/:teamId/boe/split-rules", handlers.CreateBoeSplitRule)
+		protected.DELETE("/teams/:teamId/boe/split-rules/:ruleId", handlers.DeleteBoeSplitRule)
+		protected.GET("/teams/:teamId/gold/ledger", handlers.GetGoldLedger)
+		protected.GET("/teams/:teamId/gold/balance", handlers.GetGoldBalance)
+		protected.POST("/teams/:teamId/gold/transactions", handlers.CreateGoldTransaction)
+		protected.PUT("/teams/:teamId/gold/transactions/:transactionId", handlers.UpdateGoldTransaction)
+		protected.DELETE("/teams/:teamId/gold/transactions/:transactionId", handlers.DeleteGoldTransaction)
 
 		// Spell endpoints
 		protected.GET("/spells/search", handlers.SearchSpells)
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AssignmentMapDef is the decoded shape of Boss.AssignmentMap — the same
+// structure the frontend's AssignmentMap type describes: headings, each
+// with subheadings that take up to AvailableSlots characters.
+type AssignmentMapDef struct {
+	Assignments []AssignmentEntryDef `json:"assignments"`
+}
+
+type AssignmentEntryDef struct {
+	Heading        string                    `json:"heading"`
+	HeadingAlias   *string                   `json:"heading_alias,omitempty"`
+	Information    string                    `json:"information,omitempty"`
+	Description    string                    `json:"description,omitempty"`
+	RaidplanID     string                    `json:"raidplan_id,omitempty"`
+	AvailableSlots int                       `json:"available_slots"`
+	Subheadings    []AssignmentSubheadingDef `json:"subheadings"`
+}
+
+type AssignmentSubheadingDef struct {
+	Heading        string  `json:"heading"`
+	HeadingAlias   *string `json:"heading_alias,omitempty"`
+	Description    string  `json:"description,omitempty"`
+	AvailableSlots int     `json:"available_slots"`
+	RaidplanIndex  *int    `json:"raidplan_index,omitempty"`
+}
+
+// NoteHeading is the line the heading is written as in a note — its alias
+// if it has one (e.g. "intstart", which addons key off), else the heading.
+func (e AssignmentEntryDef) NoteHeading() string {
+	if e.HeadingAlias != nil && strings.TrimSpace(*e.HeadingAlias) != "" {
+		return *e.HeadingAlias
+	}
+	return e.Heading
+}
+
+// Positional reports whether the subheading is written as a bare line of
+// names with no label — an explicitly empty alias, same rule as the
+// frontend's usesPositionalMatch.
+func (s AssignmentSubheadingDef) Positional() bool {
+	if s.HeadingAlias != nil {
+		return strings.TrimSpace(*s.HeadingAlias) == ""
+	}
+	return strings.TrimSpace(s.Heading) == ""
+}
+
+// NoteLabel is the "Label:" a non-positional subheading is written with.
+func (s AssignmentSubheadingDef) NoteLabel() string {
+	if s.HeadingAlias != nil && strings.TrimSpace(*s.HeadingAlias) != "" {
+		return *s.HeadingAlias
+	}
+	return s.Heading
+}
+
+// DecodedAssignmentMap parses the boss's AssignmentMap. A boss without one
+// decodes to an empty map.
+func (b Boss) DecodedAssignmentMap() (AssignmentMapDef, error) {
+	var def AssignmentMapDef
+	if len(b.AssignmentMap) == 0 || string(b.AssignmentMap) == "null" {
+		return def, nil
+	}
+	if err := json.Unmarshal(b.AssignmentMap, &def); err != nil {
+		return def, fmt.Errorf("decode assignment map for boss %d: %w", b.ID, err)
+	}
+	return def, nil
+}
+
+// BossAssignment is one filled-in assignment line: the characters on one
+// subheading of one heading of the boss's AssignmentMap, in order. Heading
+// and Subheading hold the map's own Heading strings (not aliases), which
+// is how lines are matched back to the map. SpellID optionally puts a
+// spell icon in front of the line in the generated note.
+type BossAssignment struct {
+	ID              uint                 `json:"id" gorm:"primaryKey"`
+	TeamID          uint                 `json:"team_id" gorm:"uniqueIndex:idx_team_boss_assignment_line"`
+	Team            Team                 `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	BossID          uint                 `json:"boss_id" gorm:"uniqueIndex:idx_team_boss_assignment_line"`
+	Boss            Boss                 `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Heading         string               `json:"heading" gorm:"uniqueIndex:idx_team_boss_assignment_line"`
+	Subheading      string               `json:"subheading" gorm:"uniqueIndex:idx_team_boss_assignment_line"`
+	SpellID         *int                 `json:"spell_id"`
+	Slots           []BossAssignmentSlot `json:"slots" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UpdatedByUserID uint                 `json:"updated_by_user_id"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// BossAssignmentSlot is one character's place in a BossAssignment. A
+// character can appear on several lines, but only once per line. SpellID
+// is the cooldown they're assigned to use there, if any.
+type BossAssignmentSlot struct {
+	ID               uint      `json:"-" gorm:"primaryKey"`
+	BossAssignmentID uint      `json:"-" gorm:"uniqueIndex:idx_assignment_slot_position"`
+	Position         int       `json:"position" gorm:"uniqueIndex:idx_assignment_slot_position"`
+	CharacterID      uint      `json:"character_id"`
+	Character        Character `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SpellID          *int      `json:"spell_id"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Attendance status values for RaidAttendance.Status.
+const (
+	AttendancePresent = "present"
+	// AttendanceLate still counts as attending, but is reported separately.
+	AttendanceLate = "late"
+	// AttendanceBench means available but sat out — counts as attending,
+	// since being benched isn't the player's choice.
+	AttendanceBench  = "bench"
+	AttendanceAbsent = "absent"
+	// AttendanceExcused is an absence agreed in advance; it's left out of
+	// the player's attendance percentage entirely rather than held against
+	// them.
+	AttendanceExcused = "excused"
+)
+
+// RaidEvent is one raid night a team actually ran. SeasonID is stamped from
+// Date when the event is recorded, so attendance percentages stay
+// per-season like everything else.
+type RaidEvent struct {
+	ID              uint             `json:"id" gorm:"primaryKey"`
+	TeamID          uint             `json:"team_id" gorm:"index"`
+	Team            Team             `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SeasonID        uint             `json:"season_id" gorm:"index"`
+	Season          Season           `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	Date            time.Time        `json:"date" gorm:"index"`
+	Difficulty      string           `json:"difficulty"`
+	Note            string           `json:"note"`
+	Bosses          []RaidEventBoss  `json:"bosses" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Attendance      []RaidAttendance `json:"attendance" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedByUserID uint             `json:"created_by_user_id"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// RaidEventBoss is a boss the team pulled during a RaidEvent.
+type RaidEventBoss struct {
+	ID          uint `json:"id" gorm:"primaryKey"`
+	RaidEventID uint `json:"raid_event_id" gorm:"uniqueIndex:idx_event_boss"`
+	BossID      uint `json:"boss_id" gorm:"uniqueIndex:idx_event_boss"`
+	Boss        Boss `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Pulls       uint `json:"pulls"`
+	Killed      bool `json:"killed"`
+}
+
+// RaidAttendance is one character's status for a RaidEvent. Attendance is
+// recorded per character — that's what's actually in the raid — and rolled
+// up per Player for percentages, since a player who swaps to an alt for
+// the night still showed up.
+type RaidAttendance struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	RaidEventID uint      `json:"raid_event_id" gorm:"uniqueIndex:idx_event_character"`
+	CharacterID uint      `json:"character_id" gorm:"uniqueIndex:idx_event_character"`
+	Character   Character `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Status      string    `json:"status"`
+	Note        string    `json:"note"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
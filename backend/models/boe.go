@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"gorm.io/datatypes"
+)
 
 // Item slot values for BoeSale.ItemSlot — a fixed set of real WoW gear
 // slots a BoE piece can drop in.
@@ -24,11 +30,12 @@ const (
 // BoeSale is a team-scoped record of a single BoE item sale — unlike
 // TierSimEntry, this is not global reference data, it's the guild's own
 // financial log. GuildCut/PlayerCut are deliberately not columns: they're
-// pure functions of SalePrice + SoldToPlayer (see below), computed on read
-// so there's nothing that can ever drift out of sync with the split rule.
+// pure functions of SalePrice + SoldToPlayer and the team's BoeSplitRule in
+// effect at CreatedAt (see below), computed on read so there's nothing that
+// can ever drift out of sync with the split rule.
 type BoeSale struct {
 	ID              uint      `json:"id" gorm:"primaryKey"`
-	TeamID          uint      `json:"team_id" gorm:"index"`
+	TeamID          uint      `json:"team_id" gorm:"index;uniqueIndex:idx_boe_team_import_key"`
 	Team            Team      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	SeasonID        uint      `json:"season_id" gorm:"index"`
 	Season          Season    `json:"season" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
@@ -40,21 +47,164 @@ type BoeSale struct {
 	CreatedByUserID uint      `json:"created_by_user_id"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	// PlayerID/WowItemID optionally tie the free-text PlayerName/ItemName to
+	// a roster Player and a catalog Item, so per-player and per-item
+	// reporting survives typos. Both are optional — plenty of BoEs are world
+	// drops the catalog doesn't have, and sellers can be trial raiders not
+	// yet on the roster. Linking copies the canonical name into the text
+	// field, so name-based grouping (payouts) agrees with the link.
+	PlayerID  *uint   `json:"player_id" gorm:"index"`
+	Player    *Player `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	WowItemID *uint   `json:"wow_item_id" gorm:"index"`
+	Item      *Item   `json:"-" gorm:"foreignKey:WowItemID;references:WowItemID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	// ImportKey identifies a sale that came from a CSV import by the content
+	// of its source row, so re-uploading the same spreadsheet skips rows
+	// already imported. Nil for sales entered in the app.
+	ImportKey *string `json:"-" gorm:"uniqueIndex:idx_boe_team_import_key"`
+}
+
+// GuildCut is the guild's share of the sale under rule — which must be the
+// rule in effect when the sale was made (BoeSplitRules.At(CreatedAt)).
+func (b BoeSale) GuildCut(rule BoeSplitRule) float64 {
+	guild, _ := rule.Cuts(b.SalePrice, b.SoldToPlayer)
+	return guild
+}
+
+// PlayerCut is the seller's share of the sale under rule.
+func (b BoeSale) PlayerCut(rule BoeSplitRule) float64 {
+	_, player := rule.Cuts(b.SalePrice, b.SoldToPlayer)
+	return player
+}
+
+// Split rule kinds for BoeSplitRule.Kind.
+const (
+	// BoeSplitPercent gives the guild GuildPercent of every sale.
+	BoeSplitPercent = "percent"
+	// BoeSplitFlatFee gives the guild FlatFee per sale (never more than the
+	// sale price itself) and the seller everything else.
+	BoeSplitFlatFee = "flat_fee"
+	// BoeSplitTiered picks a GuildPercent by sale price from Tiers.
+	BoeSplitTiered = "tiered"
+)
+
+// BoeSplitTier is one bracket of a tiered split: a sale priced at MinPrice
+// or above (up to the next tier's MinPrice) gives the guild GuildPercent of
+// the whole price — brackets aren't marginal like tax bands, since that's
+// not how guilds quote them ("30% on anything over 100k").
+type BoeSplitTier struct {
+	MinPrice     float64 `json:"min_price"`
+	GuildPercent float64 `json:"guild_percent"`
+}
+
+// BoeSplitRule is one version of a team's BoE split. Rules are append-only —
+// changing the split means adding a rule with a later EffectiveFrom, or a
+// backdated one over a stretch with no sales (e.g. a team's baseline before
+// its first sale) — and each sale is cut by whichever rule was in effect
+// when it was made, so old sales keep the split everyone agreed to at the
+// time.
+type BoeSplitRule struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	TeamID        uint      `json:"team_id" gorm:"index"`
+	Team          Team      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	EffectiveFrom time.Time `json:"effective_from" gorm:"index"`
+	Kind          string    `json:"kind"`
+	// GuildPercent is 0-100, used by BoeSplitPercent.
+	GuildPercent float64 `json:"guild_percent"`
+	// FlatFee is the guild's gold per sale, used by BoeSplitFlatFee.
+	FlatFee float64 `json:"flat_fee"`
+	// Tiers is a []BoeSplitTier, used by BoeSplitTiered.
+	Tiers datatypes.JSON `json:"tiers"`
+	// SoldToPlayerWaivesGuildCut is the original house rule: when an item is
+	// sold directly to a fellow player the guild forgoes its cut entirely so
+	// the buyer only pays the seller's share. The seller's cut doesn't grow —
+	// the guild's share is just never collected by anyone.
+	SoldToPlayerWaivesGuildCut bool      `json:"sold_to_player_waives_guild_cut"`
+	CreatedByUserID            uint      `json:"created_by_user_id"`
+	CreatedAt                  time.Time `json:"created_at"`
 }
 
-// GuildCut is 50% of the sale price, except when the item was sold directly
-// to a fellow player — the guild forgoes its cut entirely so the buyer only
-// pays 50% market value.
-func (b BoeSale) GuildCut() float64 {
-	if b.SoldToPlayer {
-		return 0
+// DefaultBoeSplitRule is the split every team starts on, and the one sales
+// made before a team's first configured rule stay on: 50/50, with the
+// guild's half waived for player sales.
+var DefaultBoeSplitRule = BoeSplitRule{
+	Kind:                       BoeSplitPercent,
+	GuildPercent:               50,
+	SoldToPlayerWaivesGuildCut: true,
+}
+
+// DecodedTiers returns Tiers sorted by MinPrice, or nil if unset/invalid.
+func (r BoeSplitRule) DecodedTiers() []BoeSplitTier {
+	var tiers []BoeSplitTier
+	if len(r.Tiers) == 0 || json.Unmarshal(r.Tiers, &tiers) != nil {
+		return nil
 	}
-	return b.SalePrice * 0.5
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinPrice < tiers[j].MinPrice })
+	return tiers
 }
 
-// PlayerCut is always 50% of the sale price — a player-sale doesn't bump
-// this to 100%, it just means the guild's would-be 50% is never collected
-// by anyone.
-func (b BoeSale) PlayerCut() float64 {
-	return b.SalePrice * 0.5
+// Cuts splits a sale price into the guild's and the seller's shares.
+func (r BoeSplitRule) Cuts(salePrice float64, soldToPlayer bool) (guild, player float64) {
+	switch r.Kind {
+	case BoeSplitFlatFee:
+		guild = min(r.FlatFee, salePrice)
+	case BoeSplitTiered:
+		percent := 0.0
+		for _, t := range r.DecodedTiers() {
+			if salePrice >= t.MinPrice {
+				percent = t.GuildPercent
+			}
+		}
+		guild = salePrice * percent / 100
+	default:
+		guild = salePrice * r.GuildPercent / 100
+	}
+	player = salePrice - guild
+	if soldToPlayer && r.SoldToPlayerWaivesGuildCut {
+		guild = 0
+	}
+	return guild, player
+}
+
+// BoeSplitRules is a team's rule history, sorted by EffectiveFrom.
+type BoeSplitRules []BoeSplitRule
+
+// At returns the rule in effect at t — the latest one whose EffectiveFrom
+// isn't after it — or DefaultBoeSplitRule if t predates them all.
+func (rules BoeSplitRules) At(t time.Time) BoeSplitRule {
+	rule := DefaultBoeSplitRule
+	for _, r := range rules {
+		if r.EffectiveFrom.After(t) {
+			break
+		}
+		rule = r
+	}
+	return rule
+}
+
+// Payout status values for a BoeSale's player cut, computed on read from
+// BoePayout rows (see the payout allocation in the BoE payout handlers) —
+// never stored, for the same reason the cuts aren't.
+const (
+	BoePayoutUnpaid  = "unpaid"
+	BoePayoutPartial = "partial"
+	BoePayoutPaid    = "paid"
+)
+
+// BoePayout is gold actually handed to a player against their BoE player
+// cuts. BoeSaleID ties a payout to one sale; a payout without one is a lump
+// sum, applied to the player's oldest outstanding sales first. PlayerName
+// matches BoeSale.PlayerName case-insensitively, which is what groups a
+// player's sales and payouts into one running balance across seasons.
+type BoePayout struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TeamID       uint      `json:"team_id" gorm:"index"`
+	Team         Team      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	BoeSaleID    *uint     `json:"boe_sale_id" gorm:"index"`
+	BoeSale      *BoeSale  `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	PlayerName   string    `json:"player_name"`
+	Amount       float64   `json:"amount"`
+	Note         string    `json:"note"`
+	PaidByUserID uint      `json:"paid_by_user_id"`
+	PaidAt       time.Time `json:"paid_at"`
+	CreatedAt    time.Time `json:"created_at"`
 }
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoeSplitRuleCuts(t *testing.T) {
+	tiered := BoeSplitRule{
+		Kind: BoeSplitTiered,
+		// Deliberately out of order — DecodedTiers sorts by MinPrice.
+		Tiers: []byte(`[{"min_price":100000,"guild_percent":30},{"min_price":0,"guild_percent":10},{"min_price":50000,"guild_percent":20}]`),
+	}
+
+	tests := []struct {
+		name         string
+		rule         BoeSplitRule
+		salePrice    float64
+		soldToPlayer bool
+		wantGuild    float64
+		wantPlayer   float64
+	}{
+		{"default 50/50", DefaultBoeSplitRule, 10000, false, 5000, 5000},
+		{"default waives guild cut for player sales", DefaultBoeSplitRule, 10000, true, 0, 5000},
+		{"percent", BoeSplitRule{Kind: BoeSplitPercent, GuildPercent: 25}, 10000, false, 2500, 7500},
+		{"percent without waiver", BoeSplitRule{Kind: BoeSplitPercent, GuildPercent: 25}, 10000, true, 2500, 7500},
+		{"flat fee", BoeSplitRule{Kind: BoeSplitFlatFee, FlatFee: 1000}, 10000, false, 1000, 9000},
+		{"flat fee capped at sale price", BoeSplitRule{Kind: BoeSplitFlatFee, FlatFee: 1000}, 600, false, 600, 0},
+		{"flat fee waived", BoeSplitRule{Kind: BoeSplitFlatFee, FlatFee: 1000, SoldToPlayerWaivesGuildCut: true}, 10000, true, 0, 9000},
+		{"tiered lowest bracket", tiered, 20000, false, 2000, 18000},
+		{"tiered bracket floor is inclusive", tiered, 50000, false, 10000, 40000},
+		{"tiered whole price, not marginal", tiered, 200000, false, 60000, 140000},
+		{"tiered with no tiers takes nothing", BoeSplitRule{Kind: BoeSplitTiered}, 20000, false, 0, 20000},
+		{"tiered with invalid tiers takes nothing", BoeSplitRule{Kind: BoeSplitTiered, Tiers: []byte(`not json`)}, 20000, false, 0, 20000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guild, player := tt.rule.Cuts(tt.salePrice, tt.soldToPlayer)
+			if guild != tt.wantGuild || player != tt.wantPlayer {
+				t.Fatalf("Cuts(%v, %v) = (%v, %v), want (%v, %v)", tt.salePrice, tt.soldToPlayer, guild, player, tt.wantGuild, tt.wantPlayer)
+			}
+		})
+	}
+}
+
+func TestBoeSplitRulesAt(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+	rules := BoeSplitRules{
+		{ID: 1, Kind: BoeSplitPercent, GuildPercent: 30, EffectiveFrom: day(5)},
+		{ID: 2, Kind: BoeSplitFlatFee, FlatFee: 500, EffectiveFrom: day(10)},
+		{ID: 3, Kind: BoeSplitPercent, GuildPercent: 40, EffectiveFrom: day(20)},
+	}
+
+	tests := []struct {
+		name   string
+		rules  BoeSplitRules
+		at     time.Time
+		wantID uint
+	}{
+		{"no rules", nil, day(15), 0},
+		{"before the first rule", rules, day(4), 0},
+		{"on a rule's start", rules, day(5), 1},
+		{"between rules", rules, day(9), 1},
+		{"just before the next rule", rules, day(10).Add(-time.Second), 1},
+		{"next rule", rules, day(10), 2},
+		{"after the last rule", rules, day(30), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rules.At(tt.at)
+			if rule.ID != tt.wantID {
+				t.Fatalf("At(%v) = rule %d, want rule %d", tt.at, rule.ID, tt.wantID)
+			}
+			if tt.wantID == 0 && (rule.Kind != DefaultBoeSplitRule.Kind || rule.GuildPercent != DefaultBoeSplitRule.GuildPercent) {
+				t.Fatalf("At(%v) = %+v, want DefaultBoeSplitRule", tt.at, rule)
+			}
+		})
+	}
+}
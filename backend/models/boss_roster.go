@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// BossRosterEntry is one character selected to play a boss, and the spec
+// they'll play it on — a nil SpecializationID means their usual spec. It's
+// keyed per team and boss like AssignmentNote (no difficulty), since it's
+// the roster the note is written for. One character per player per boss
+// is enforced by the handler rather than an index, so swapping a player
+// onto their alt is a single replace.
+type BossRosterEntry struct {
+	ID               uint            `json:"id" gorm:"primaryKey"`
+	TeamID           uint            `json:"team_id" gorm:"uniqueIndex:idx_team_boss_character"`
+	Team             Team            `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	BossID           uint            `json:"boss_id" gorm:"uniqueIndex:idx_team_boss_character"`
+	Boss             Boss            `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CharacterID      uint            `json:"character_id" gorm:"uniqueIndex:idx_team_boss_character"`
+	Character        Character       `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SpecializationID *uint           `json:"specialization_id"`
+	Specialization   *Specialization `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
@@ -13,6 +13,9 @@ type Character struct {
 	IsMain           bool            `json:"is_main"`
 	SpecializationID *uint           `json:"specialization_id"`
 	Specialization   *Specialization `json:"specialization,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	CreatedAt        time.Time       `json:"created_at"`
-	UpdatedAt        time.Time       `json:"updated_at"`
+	// BuildLabel is the hero-talent build the character plays, matched
+	// against TierSimEntry.BuildLabel for their spec. Blank means unknown.
+	BuildLabel string    `json:"build_label"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
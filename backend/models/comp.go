@@ -0,0 +1,65 @@
+package models
+
+// Raid coverage tag values for SpecializationTag.Tag. A tag means "a raid
+// with this spec has it covered" — the spec brings the buff itself, or the
+// utility is baseline/near-universally talented for it.
+const (
+	TagArcaneIntellect     = "arcane_intellect"
+	TagBattleShout         = "battle_shout"
+	TagPowerWordFortitude  = "power_word_fortitude"
+	TagMarkOfTheWild       = "mark_of_the_wild"
+	TagSkyfury             = "skyfury"
+	TagBlessingOfTheBronze = "blessing_of_the_bronze"
+	TagMysticTouch         = "mystic_touch"
+	TagChaosBrand          = "chaos_brand"
+	TagHuntersMark         = "hunters_mark"
+	TagBloodlust           = "bloodlust"
+	TagBattleRes           = "battle_res"
+	TagRaidDefensive       = "raid_defensive"
+	TagMovement            = "movement"
+	TagHealthstone         = "healthstone"
+	TagPowerInfusion       = "power_infusion"
+)
+
+// Kind values for RaidCoverageCheck.Kind.
+const (
+	CoverageBuff    = "buff"
+	CoverageDebuff  = "debuff"
+	CoverageUtility = "utility"
+)
+
+// RaidCoverageCheck is one line of the comp builder's checklist. Tags on
+// Specialization are the data; this is the list of what's worth asking
+// about, in display order.
+type RaidCoverageCheck struct {
+	Tag   string `json:"tag"`
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+}
+
+var RaidCoverageChecklist = []RaidCoverageCheck{
+	{Tag: TagArcaneIntellect, Kind: CoverageBuff, Label: "Arcane Intellect"},
+	{Tag: TagBattleShout, Kind: CoverageBuff, Label: "Battle Shout"},
+	{Tag: TagPowerWordFortitude, Kind: CoverageBuff, Label: "Power Word: Fortitude"},
+	{Tag: TagMarkOfTheWild, Kind: CoverageBuff, Label: "Mark of the Wild"},
+	{Tag: TagSkyfury, Kind: CoverageBuff, Label: "Skyfury"},
+	{Tag: TagBlessingOfTheBronze, Kind: CoverageBuff, Label: "Blessing of the Bronze"},
+	{Tag: TagMysticTouch, Kind: CoverageDebuff, Label: "Mystic Touch"},
+	{Tag: TagChaosBrand, Kind: CoverageDebuff, Label: "Chaos Brand"},
+	{Tag: TagHuntersMark, Kind: CoverageDebuff, Label: "Hunter's Mark"},
+	{Tag: TagBloodlust, Kind: CoverageUtility, Label: "Bloodlust / Heroism"},
+	{Tag: TagBattleRes, Kind: CoverageUtility, Label: "Battle resurrection"},
+	{Tag: TagRaidDefensive, Kind: CoverageUtility, Label: "Raid-wide defensive"},
+	{Tag: TagMovement, Kind: CoverageUtility, Label: "Raid movement"},
+	{Tag: TagHealthstone, Kind: CoverageUtility, Label: "Healthstones"},
+	{Tag: TagPowerInfusion, Kind: CoverageUtility, Label: "Power Infusion"},
+}
+
+// SpecializationTag is one raid coverage tag a Specialization brings. Kept
+// as rows rather than a column so the comp builder can ask "who brings X"
+// in SQL, same as ItemEligibleRole.
+type SpecializationTag struct {
+	ID               uint   `json:"-" gorm:"primaryKey"`
+	SpecializationID uint   `json:"-" gorm:"uniqueIndex:idx_spec_tag"`
+	Tag              string `json:"tag" gorm:"uniqueIndex:idx_spec_tag"`
+}
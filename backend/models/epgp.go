@@ -0,0 +1,134 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Loot mode values for Team.LootMode. Council is the default — loot council
+// decisions informed by wishlists and bonus-roll priorities. EPGP swaps
+// that for a points ledger (EPGPLedgerEntry) where priority is EP/GP.
+const (
+	LootModeCouncil = "council"
+	LootModeEPGP    = "epgp"
+)
+
+// Kind values for EPGPLedgerEntry.Kind.
+const (
+	EPGPKindAttendance = "ep_attendance"
+	EPGPKindBossKill   = "ep_boss_kill"
+	EPGPKindItem       = "gp_item"
+	EPGPKindAdjustment = "adjustment"
+	EPGPKindDecay      = "decay"
+)
+
+// EPGPSettings holds a team's EPGP tuning — one row per team, created the
+// first time the team saves settings or applies decay; until then reads get
+// DefaultEPGPSettings. Only meaningful when the team's LootMode is
+// LootModeEPGP.
+type EPGPSettings struct {
+	ID     uint `json:"id" gorm:"primaryKey"`
+	TeamID uint `json:"team_id" gorm:"uniqueIndex"`
+	Team   Team `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// EPPerAttendance/EPPerBossKill are the default awards when an EP
+	// request doesn't carry its own amount.
+	EPPerAttendance float64 `json:"ep_per_attendance"`
+	EPPerBossKill   float64 `json:"ep_per_boss_kill"`
+	// BaseGP is both the GP charged for a reference-item-level, weight-1
+	// slot, and the floor added to every GP total when computing priority
+	// so a fresh character with 0 GP doesn't divide by zero.
+	BaseGP             float64    `json:"base_gp"`
+	ReferenceItemLevel uint       `json:"reference_item_level"`
+	DecayPercent       float64    `json:"decay_percent"`
+	LastDecayAt        *time.Time `json:"last_decay_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// DefaultEPGPSettings are the values a team starts with — standard EPGP
+// community defaults (10% weekly decay, GP doubling every 26 item levels).
+func DefaultEPGPSettings(teamId uint) EPGPSettings {
+	return EPGPSettings{
+		TeamID:             teamId,
+		EPPerAttendance:    10,
+		EPPerBossKill:      10,
+		BaseGP:             100,
+		ReferenceItemLevel: 639,
+		DecayPercent:       10,
+	}
+}
+
+// epgpSlotWeights maps a fragment of Item.Slot (free text scraped from
+// Wowhead, so matched case-insensitively by substring) to its GP weight.
+// Checked in order — "two-hand" must be matched before "hand", and
+// "off hand"/"held in off-hand" before "hand" too.
+var epgpSlotWeights = []struct {
+	fragment string
+	weight   float64
+}{
+	{"two-hand", 2},
+	{"held in off-hand", 0.5},
+	{"off hand", 0.5},
+	{"one-hand", 1.5},
+	{"main hand", 1.5},
+	{"ranged", 2},
+	{"head", 1},
+	{"chest", 1},
+	{"legs", 1},
+	{"trinket", 1.25},
+	{"shoulder", 0.75},
+	{"hands", 0.75},
+	{"waist", 0.75},
+	{"feet", 0.75},
+	{"neck", 0.5},
+	{"back", 0.5},
+	{"wrist", 0.5},
+	{"finger", 0.5},
+}
+
+// EPGPSlotWeight returns the GP weight for an Item.Slot value, or 1 for a
+// slot it doesn't recognize (e.g. tier tokens, which have no real slot).
+func EPGPSlotWeight(slot string) float64 {
+	lower := strings.ToLower(slot)
+	for _, w := range epgpSlotWeights {
+		if strings.Contains(lower, w.fragment) {
+			return w.weight
+		}
+	}
+	return 1
+}
+
+// ItemGP is the GP charged for receiving item: BaseGP scaled by slot
+// weight, doubling every 26 item levels above ReferenceItemLevel (and
+// halving every 26 below it), rounded to a whole number.
+func (s EPGPSettings) ItemGP(item Item) float64 {
+	levelDelta := float64(item.ItemLevel) - float64(s.ReferenceItemLevel)
+	return math.Round(s.BaseGP * EPGPSlotWeight(item.Slot) * math.Pow(2, levelDelta/26))
+}
+
+// Priority is EP divided by GP plus the BaseGP floor.
+func (s EPGPSettings) Priority(ep, gp float64) float64 {
+	return ep / (gp + s.BaseGP)
+}
+
+// EPGPLedgerEntry is one immutable change to a character's EP and/or GP —
+// standings are always summed from the ledger, never stored, so every
+// number on the standings page can be traced back to the entries that
+// produced it. EP/GP are signed deltas: adjustments and decay can be
+// negative.
+type EPGPLedgerEntry struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TeamID       uint      `json:"team_id" gorm:"index"`
+	Team         Team      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CharacterID  uint      `json:"character_id" gorm:"index"`
+	Character    Character `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Kind         string    `json:"kind"`
+	EP           float64   `json:"ep"`
+	GP           float64   `json:"gp"`
+	BossID       *uint     `json:"boss_id"`
+	ItemID       *uint     `json:"item_id"`
+	Difficulty   string    `json:"difficulty"`
+	Reason       string    `json:"reason"`
+	ActingUserID uint      `json:"acting_user_id"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
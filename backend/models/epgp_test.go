@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestEPGPSettingsItemGP(t *testing.T) {
+	settings := DefaultEPGPSettings(1) // BaseGP 100, reference item level 639
+
+	tests := []struct {
+		name      string
+		slot      string
+		itemLevel uint
+		want      float64
+	}{
+		{"reference level, weight 1", "Head", 639, 100},
+		{"one doubling above reference", "Chest", 665, 200},
+		{"one halving below reference", "Legs", 613, 50},
+		{"half a doubling rounds", "Head", 652, 141},
+		{"two-hand not read as hand", "Two-Hand", 639, 200},
+		{"held in off-hand not read as hand", "Held In Off-hand", 639, 50},
+		{"hands", "Hands", 639, 75},
+		{"one-hand", "One-Hand", 639, 150},
+		{"trinket", "Trinket", 665, 250},
+		{"matched case-insensitively", "FINGER", 639, 50},
+		{"unknown slot weighs 1", "Tier Token", 639, 100},
+		{"empty slot weighs 1", "", 639, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := settings.ItemGP(Item{Slot: tt.slot, ItemLevel: tt.itemLevel})
+			if got != tt.want {
+				t.Fatalf("ItemGP(%q, %d) = %v, want %v", tt.slot, tt.itemLevel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEPGPSettingsPriority(t *testing.T) {
+	settings := DefaultEPGPSettings(1)
+
+	tests := []struct {
+		name   string
+		ep, gp float64
+		want   float64
+	}{
+		{"fresh character", 0, 0, 0},
+		{"no GP yet uses the floor", 50, 0, 0.5},
+		{"EP over GP plus floor", 300, 200, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := settings.Priority(tt.ep, tt.gp); got != tt.want {
+				t.Fatalf("Priority(%v, %v) = %v, want %v", tt.ep, tt.gp, got, tt.want)
+			}
+		})
+	}
+}
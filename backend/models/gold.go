@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Transaction types for GoldTransaction.Type. GoldTxBoeSale is never stored:
+// a BoE sale's guild cut enters the ledger on read, straight from BoeSale,
+// for the same reason the cuts themselves aren't columns — a stored copy
+// would drift the moment a sale is edited or a split rule applies.
+const (
+	GoldTxBoeSale     = "boe_sale"
+	GoldTxConsumables = "consumables"
+	GoldTxRepairs     = "repairs"
+	GoldTxDonation    = "donation"
+	// GoldTxPayout is gold the guild pays out of its own bank (raid pay,
+	// bonuses) — not a BoePayout, which hands a seller their own cut and
+	// never was the guild's gold.
+	GoldTxPayout = "payout"
+	// GoldTxAdjustment covers anything else (e.g. the opening balance when
+	// the team starts using the ledger) and may go either way.
+	GoldTxAdjustment = "adjustment"
+)
+
+// GoldTransaction is one manually recorded movement of gold in or out of
+// the team's guild bank. Amount is signed — income positive, spending
+// negative — with the sign implied by Type for everything but adjustments.
+// Category is a free-text label within the type ("flasks", "cauldrons",
+// "raid night 3") for finer reporting.
+type GoldTransaction struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	TeamID          uint      `json:"team_id" gorm:"index"`
+	Team            Team      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SeasonID        uint      `json:"season_id" gorm:"index"`
+	Season          Season    `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	Type            string    `json:"type"`
+	Category        string    `json:"category"`
+	Description     string    `json:"description"`
+	Amount          float64   `json:"amount"`
+	OccurredAt      time.Time `json:"occurred_at" gorm:"index"`
+	CreatedByUserID uint      `json:"created_by_user_id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
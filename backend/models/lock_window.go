@@ -0,0 +1,122 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kind values for WishlistLockWindow.Kind.
+const (
+	LockWindowRecurring = "recurring"
+	LockWindowManual    = "manual"
+)
+
+// regionTimezones is the default "server time" for a Team.Region when a
+// lock window doesn't specify its own Timezone — Blizzard's EU realms run
+// on CET, and NA realms are treated as Pacific.
+var regionTimezones = map[string]string{
+	"eu": "Europe/Paris",
+	"na": "America/Los_Angeles",
+}
+
+// DefaultTimezoneForRegion returns the IANA timezone a team in region uses
+// for server time, falling back to UTC for regions without a mapping.
+func DefaultTimezoneForRegion(region string) string {
+	if tz, ok := regionTimezones[region]; ok {
+		return tz
+	}
+	return "UTC"
+}
+
+// WishlistLockWindow freezes a team's wishlists (wishes, boss priorities,
+// bonus rolls) for non-council members. A recurring window repeats every
+// week between StartWeekday/StartTime and EndWeekday/EndTime in Timezone,
+// and may wrap past the end of the week (e.g. Sunday 22:00 to Tuesday
+// 02:00). A manual window is a one-off StartsAt/EndsAt range — a nil EndsAt
+// means "locked until someone deletes the window".
+//
+// Times are "HH:MM" strings rather than a time.Time so a recurring window
+// keeps meaning "18:00 server time" across DST changes instead of drifting
+// by an hour.
+type WishlistLockWindow struct {
+	ID              uint         `json:"id" gorm:"primaryKey"`
+	TeamID          uint         `json:"team_id" gorm:"index"`
+	Team            Team         `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Kind            string       `json:"kind"`
+	Label           string       `json:"label"`
+	StartWeekday    time.Weekday `json:"start_weekday"`
+	StartTime       string       `json:"start_time"`
+	EndWeekday      time.Weekday `json:"end_weekday"`
+	EndTime         string       `json:"end_time"`
+	Timezone        string       `json:"timezone"`
+	StartsAt        *time.Time   `json:"starts_at"`
+	EndsAt          *time.Time   `json:"ends_at"`
+	CreatedByUserID uint         `json:"created_by_user_id"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+const minutesPerWeek = 7 * 24 * 60
+
+// ParseClockTime parses an "HH:MM" 24-hour time into minutes after midnight.
+func ParseClockTime(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("parse clock time %q: %w", value, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ActiveAt reports whether the window covers now, and if so when the
+// current lock ends (nil for an open-ended manual window). Windows with an
+// unparseable Timezone/StartTime/EndTime are treated as inactive — those
+// are validated on create, so this only guards against hand-edited rows.
+func (w WishlistLockWindow) ActiveAt(now time.Time) (active bool, until *time.Time) {
+	if w.Kind == LockWindowManual {
+		if w.StartsAt != nil && now.Before(*w.StartsAt) {
+			return false, nil
+		}
+		if w.EndsAt != nil && !now.Before(*w.EndsAt) {
+			return false, nil
+		}
+		return true, w.EndsAt
+	}
+
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false, nil
+	}
+	startMinute, err := ParseClockTime(w.StartTime)
+	if err != nil {
+		return false, nil
+	}
+	endMinute, err := ParseClockTime(w.EndTime)
+	if err != nil {
+		return false, nil
+	}
+
+	local := now.In(loc)
+	nowOffset := int(local.Weekday())*24*60 + local.Hour()*60 + local.Minute()
+	startOffset := int(w.StartWeekday)*24*60 + startMinute
+	endOffset := int(w.EndWeekday)*24*60 + endMinute
+
+	if startOffset <= endOffset {
+		active = nowOffset >= startOffset && nowOffset < endOffset
+	} else {
+		active = nowOffset >= startOffset || nowOffset < endOffset
+	}
+	if !active {
+		return false, nil
+	}
+
+	// Build the end from its wall-clock weekday and time rather than adding
+	// minutes to now: a lock spanning a DST change would otherwise end an
+	// hour off. Same weekday as now means later today, unless the window
+	// wraps the whole week and ends before now's time of day.
+	days := (int(w.EndWeekday) - int(local.Weekday()) + 7) % 7
+	if days == 0 && endOffset <= nowOffset {
+		days = 7
+	}
+	end := time.Date(local.Year(), local.Month(), local.Day()+days, endMinute/60, endMinute%60, 0, 0, loc)
+	return true, &end
+}
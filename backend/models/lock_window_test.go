@@ -0,0 +1,107 @@
+package models
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestWishlistLockWindowActiveAt(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(loc *time.Location, year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, loc)
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	// Tuesday 18:00 to Wednesday 02:00 server time.
+	raidNight := WishlistLockWindow{
+		Kind:         LockWindowRecurring,
+		StartWeekday: time.Tuesday,
+		StartTime:    "18:00",
+		EndWeekday:   time.Wednesday,
+		EndTime:      "02:00",
+		Timezone:     "Europe/Paris",
+	}
+	// Saturday 20:00 to Monday 02:00 — spans the last Sunday of October,
+	// when Europe/Paris falls back from CEST to CET.
+	weekend := WishlistLockWindow{
+		Kind:         LockWindowRecurring,
+		StartWeekday: time.Saturday,
+		StartTime:    "20:00",
+		EndWeekday:   time.Monday,
+		EndTime:      "02:00",
+		Timezone:     "Europe/Paris",
+	}
+	// Sunday 22:00 to Tuesday 02:00, wrapping past the end of the week.
+	wrapping := WishlistLockWindow{
+		Kind:         LockWindowRecurring,
+		StartWeekday: time.Sunday,
+		StartTime:    "22:00",
+		EndWeekday:   time.Tuesday,
+		EndTime:      "02:00",
+		Timezone:     "America/Los_Angeles",
+	}
+	// Friday 12:00 to Friday 08:00: locked all week but a few hours.
+	almostWeek := WishlistLockWindow{
+		Kind:         LockWindowRecurring,
+		StartWeekday: time.Friday,
+		StartTime:    "12:00",
+		EndWeekday:   time.Friday,
+		EndTime:      "08:00",
+		Timezone:     "UTC",
+	}
+
+	manualStart := at(time.UTC, 2026, time.March, 2, 12, 0)
+	manualEnd := at(time.UTC, 2026, time.March, 9, 12, 0)
+
+	tests := []struct {
+		name       string
+		window     WishlistLockWindow
+		now        time.Time
+		wantActive bool
+		wantUntil  *time.Time
+	}{
+		{"before start", raidNight, at(paris, 2026, time.March, 3, 17, 59), false, nil},
+		{"at start", raidNight, at(paris, 2026, time.March, 3, 18, 0), true, ptr(at(paris, 2026, time.March, 4, 2, 0))},
+		{"overnight", raidNight, at(paris, 2026, time.March, 4, 1, 30), true, ptr(at(paris, 2026, time.March, 4, 2, 0))},
+		{"at end", raidNight, at(paris, 2026, time.March, 4, 2, 0), false, nil},
+		{"now given in another zone", raidNight, at(time.UTC, 2026, time.March, 3, 20, 0), true, ptr(at(paris, 2026, time.March, 4, 2, 0))},
+		{"across DST fall back", weekend, at(paris, 2026, time.October, 24, 22, 0), true, ptr(at(paris, 2026, time.October, 26, 2, 0))},
+		{"across DST spring forward", weekend, at(paris, 2026, time.March, 28, 22, 0), true, ptr(at(paris, 2026, time.March, 30, 2, 0))},
+		{"wraps week, before sunday", wrapping, at(la, 2026, time.March, 7, 23, 0), false, nil},
+		{"wraps week, sunday night", wrapping, at(la, 2026, time.March, 8, 23, 0), true, ptr(at(la, 2026, time.March, 10, 2, 0))},
+		{"wraps week, monday", wrapping, at(la, 2026, time.March, 9, 12, 0), true, ptr(at(la, 2026, time.March, 10, 2, 0))},
+		{"same weekday, later today", almostWeek, at(time.UTC, 2026, time.March, 6, 7, 0), true, ptr(at(time.UTC, 2026, time.March, 6, 8, 0))},
+		{"same weekday, next week", almostWeek, at(time.UTC, 2026, time.March, 6, 13, 0), true, ptr(at(time.UTC, 2026, time.March, 13, 8, 0))},
+		{"same weekday, gap", almostWeek, at(time.UTC, 2026, time.March, 6, 10, 0), false, nil},
+		{"bad timezone", WishlistLockWindow{Kind: LockWindowRecurring, StartTime: "00:00", EndTime: "23:59", Timezone: "Mars/Olympus"}, at(time.UTC, 2026, time.March, 1, 12, 0), false, nil},
+		{"bad clock time", WishlistLockWindow{Kind: LockWindowRecurring, StartTime: "6pm", EndTime: "23:59", Timezone: "UTC"}, at(time.UTC, 2026, time.March, 1, 12, 0), false, nil},
+		{"manual before start", WishlistLockWindow{Kind: LockWindowManual, StartsAt: &manualStart, EndsAt: &manualEnd}, manualStart.Add(-time.Minute), false, nil},
+		{"manual inside", WishlistLockWindow{Kind: LockWindowManual, StartsAt: &manualStart, EndsAt: &manualEnd}, manualStart, true, &manualEnd},
+		{"manual at end", WishlistLockWindow{Kind: LockWindowManual, StartsAt: &manualStart, EndsAt: &manualEnd}, manualEnd, false, nil},
+		{"manual open-ended", WishlistLockWindow{Kind: LockWindowManual, StartsAt: &manualStart}, manualEnd, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, until := tt.window.ActiveAt(tt.now)
+			if active != tt.wantActive {
+				t.Fatalf("active = %v, want %v", active, tt.wantActive)
+			}
+			switch {
+			case tt.wantUntil == nil && until != nil:
+				t.Fatalf("until = %v, want nil", *until)
+			case tt.wantUntil != nil && until == nil:
+				t.Fatalf("until = nil, want %v", *tt.wantUntil)
+			case tt.wantUntil != nil && !until.Equal(*tt.wantUntil):
+				t.Fatalf("until = %v, want %v", *until, *tt.wantUntil)
+			}
+		})
+	}
+}
@@ -58,6 +58,10 @@ type Specialization struct {
 	PrimaryStat string       `json:"primary_stat"`
 	Role        string       `json:"role"`
 	WeaponTypes []WeaponType `json:"weapon_types" gorm:"many2many:specialization_weapon_types;"`
+
+	// Tags are the raid buffs/debuffs/utility the spec brings, checked by
+	// the comp builder against RaidCoverageChecklist.
+	Tags []SpecializationTag `json:"tags" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
 // Item eligibility is computed, not stored: if ArmorTypeID is set, match it
@@ -227,6 +231,10 @@ const (
 	AuditEventPrioritySet      = "priority_set"
 	AuditEventBonusRollAdded   = "bonus_roll_added"
 	AuditEventBonusRollRemoved = "bonus_roll_removed"
+	AuditEventEPAwarded        = "ep_awarded"
+	AuditEventGPCharged        = "gp_charged"
+	AuditEventEPGPAdjusted     = "epgp_adjusted"
+	AuditEventEPGPDecayed      = "epgp_decayed"
 )
 
 // LootAuditLog is an immutable record of every edit made to a character's
@@ -239,8 +247,8 @@ const (
 type LootAuditLog struct {
 	ID             uint      `json:"id" gorm:"primaryKey"`
 	TeamID         uint      `json:"team_id" gorm:"index"`
-	EventType      string    `json:"event_type"`
-	ActingUserID   uint      `json:"acting_user_id"`
+	EventType      string    `json:"event_type" gorm:"index"`
+	ActingUserID   uint      `json:"acting_user_id" gorm:"index"`
 	ActingUserBTag string    `json:"acting_user_btag"`
 	CharacterID    uint      `json:"character_id" gorm:"index"`
 	CharacterName  string    `json:"character_name"`
@@ -252,6 +260,19 @@ type LootAuditLog struct {
 	// Value is the new priority (priority_set) or resulting total
 	// (bonus_roll_added/bonus_roll_removed) — unused for the other event
 	// types.
-	Value     *uint     `json:"value"`
-	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	Value *uint `json:"value"`
+	// EPDelta/GPDelta are the signed EP/GP change an EPGP event wrote to
+	// the ledger (ep_awarded, gp_charged, epgp_adjusted, epgp_decayed), not
+	// the resulting total — totals can always be re-summed from the ledger,
+	// the change can't be recovered once later entries land. Nil when the
+	// event didn't touch that side.
+	EPDelta *float64 `json:"ep_delta"`
+	GPDelta *float64 `json:"gp_delta"`
+	// DecayPercent is the team's decay rate at the time of an epgp_decayed
+	// event, since settings can change afterwards.
+	DecayPercent *float64 `json:"decay_percent"`
+	// LockOverride marks an edit loot council made while the team's
+	// wishlists were locked for everyone else (see WishlistLockWindow).
+	LockOverride bool      `json:"lock_override"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
 }
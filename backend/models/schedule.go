@@ -0,0 +1,115 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sign-up status values for RaidSignUp.Status.
+const (
+	SignUpAccept    = "accept"
+	SignUpTentative = "tentative"
+	SignUpDecline   = "decline"
+)
+
+// RaidSchedule is one recurring weekly raid slot — e.g. Wednesday 20:00 for
+// three hours in Europe/Paris. Like WishlistLockWindow, StartTime is an
+// "HH:MM" string in Timezone rather than a time.Time, so the raid stays at
+// 20:00 server time across DST changes. Schedules don't hold sign-ups
+// themselves; they stamp out ScheduledRaids, which do.
+type RaidSchedule struct {
+	ID              uint         `json:"id" gorm:"primaryKey"`
+	TeamID          uint         `json:"team_id" gorm:"index"`
+	Team            Team         `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Label           string       `json:"label"`
+	Weekday         time.Weekday `json:"weekday"`
+	StartTime       string       `json:"start_time"`
+	DurationMinutes uint         `json:"duration_minutes"`
+	Timezone        string       `json:"timezone"`
+	Difficulty      string       `json:"difficulty"`
+	// Active schedules are the only ones new events get generated from;
+	// pausing one (e.g. over a holiday break) leaves existing events alone.
+	Active          bool      `json:"active"`
+	CreatedByUserID uint      `json:"created_by_user_id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// OccurrencesBetween returns the start of every slot in [from, to), each
+// resolved in the schedule's Timezone so a DST change moves the UTC instant
+// rather than the local start time.
+func (s RaidSchedule) OccurrencesBetween(from, to time.Time) ([]time.Time, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("load timezone %q: %w", s.Timezone, err)
+	}
+	startMinute, err := ParseClockTime(s.StartTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var starts []time.Time
+	day := from.In(loc)
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != s.Weekday {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), startMinute/60, startMinute%60, 0, 0, loc)
+		if !start.Before(from) && start.Before(to) {
+			starts = append(starts, start)
+		}
+	}
+	return starts, nil
+}
+
+// ScheduledRaid is one raid night on the team calendar — generated from a
+// RaidSchedule or added by hand (ScheduleID nil) for one-offs. It's the
+// plan, not the record: what actually happened on the night goes in a
+// RaidEvent.
+//
+// SlotStartsAt is the schedule slot a generated night came from, and the
+// (schedule, slot) unique index is what makes generating the same weeks
+// twice a no-op. It never changes when the night is moved, and deleting a
+// night only soft-deletes it, so a slot that was moved or removed by hand
+// stays taken and isn't generated again.
+type ScheduledRaid struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	TeamID          uint           `json:"team_id" gorm:"index"`
+	Team            Team           `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ScheduleID      *uint          `json:"schedule_id" gorm:"uniqueIndex:idx_schedule_slot"`
+	Schedule        *RaidSchedule  `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	SlotStartsAt    *time.Time     `json:"slot_starts_at" gorm:"uniqueIndex:idx_schedule_slot"`
+	Title           string         `json:"title"`
+	StartsAt        time.Time      `json:"starts_at" gorm:"index"`
+	DurationMinutes uint           `json:"duration_minutes"`
+	Difficulty      string         `json:"difficulty"`
+	Note            string         `json:"note"`
+	Canceled        bool           `json:"canceled"`
+	SignUps         []RaidSignUp   `json:"sign_ups" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedByUserID uint           `json:"created_by_user_id"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// RaidSignUp is one player's answer for a ScheduledRaid. Sign-ups are per
+// Player — one answer per person — with the character and spec they plan
+// to bring, which is what the projected comp is built from. A nil
+// SpecializationID falls back to the character's own spec.
+type RaidSignUp struct {
+	ID               uint            `json:"id" gorm:"primaryKey"`
+	ScheduledRaidID  uint            `json:"scheduled_raid_id" gorm:"uniqueIndex:idx_scheduled_raid_player"`
+	PlayerID         uint            `json:"player_id" gorm:"uniqueIndex:idx_scheduled_raid_player"`
+	Player           Player          `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Status           string          `json:"status"`
+	CharacterID      *uint           `json:"character_id"`
+	Character        *Character      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	SpecializationID *uint           `json:"specialization_id"`
+	Specialization   *Specialization `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Note             string          `json:"note"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestRaidScheduleOccurrencesBetween(t *testing.T) {
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	// Wednesday 20:00 in Paris. Europe/Paris moves from CET to CEST on
+	// Sunday 29 March 2026, so the slot is 19:00 UTC before and 18:00 after.
+	paris := RaidSchedule{Weekday: time.Wednesday, StartTime: "20:00", Timezone: "Europe/Paris"}
+	// Tuesday 23:00 in Los Angeles (PDT) is already Wednesday 06:00 UTC.
+	lateNight := RaidSchedule{Weekday: time.Tuesday, StartTime: "23:00", Timezone: "America/Los_Angeles"}
+
+	tests := []struct {
+		name     string
+		schedule RaidSchedule
+		from, to time.Time
+		want     []time.Time
+	}{
+		{
+			name:     "local start time kept across DST",
+			schedule: paris,
+			from:     utc(time.March, 23, 0, 0),
+			to:       utc(time.April, 6, 0, 0),
+			want:     []time.Time{utc(time.March, 25, 19, 0), utc(time.April, 1, 18, 0)},
+		},
+		{
+			name:     "from is inclusive",
+			schedule: paris,
+			from:     utc(time.April, 1, 18, 0),
+			to:       utc(time.April, 2, 0, 0),
+			want:     []time.Time{utc(time.April, 1, 18, 0)},
+		},
+		{
+			name:     "to is exclusive",
+			schedule: paris,
+			from:     utc(time.March, 30, 0, 0),
+			to:       utc(time.April, 1, 18, 0),
+			want:     nil,
+		},
+		{
+			name:     "slot already started on the first day",
+			schedule: paris,
+			from:     utc(time.April, 1, 18, 1),
+			to:       utc(time.April, 9, 0, 0),
+			want:     []time.Time{utc(time.April, 8, 18, 0)},
+		},
+		{
+			name:     "weekday is the schedule's, not UTC's",
+			schedule: lateNight,
+			from:     utc(time.April, 8, 0, 0),
+			to:       utc(time.April, 9, 0, 0),
+			want:     []time.Time{utc(time.April, 8, 6, 0)},
+		},
+		{
+			name:     "empty window",
+			schedule: paris,
+			from:     utc(time.April, 1, 0, 0),
+			to:       utc(time.April, 1, 0, 0),
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.schedule.OccurrencesBetween(tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("OccurrencesBetween = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %v, want %v", i, got[i].UTC(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRaidScheduleOccurrencesBetweenInvalid(t *testing.T) {
+	from := time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		schedule RaidSchedule
+	}{
+		{"unknown timezone", RaidSchedule{Weekday: time.Wednesday, StartTime: "20:00", Timezone: "Mars/Olympus_Mons"}},
+		{"bad start time", RaidSchedule{Weekday: time.Wednesday, StartTime: "8pm", Timezone: "Europe/Paris"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.schedule.OccurrencesBetween(from, from.AddDate(0, 0, 7))
+			if err == nil {
+				t.Fatalf("OccurrencesBetween = %v, want an error", got)
+			}
+			if got != nil {
+				t.Errorf("OccurrencesBetween returned %v alongside the error", got)
+			}
+		})
+	}
+}
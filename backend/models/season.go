@@ -43,6 +43,35 @@ type Season struct {
 	// these curated yet.
 	HeroicBonusIds string `json:"heroic_bonus_ids"`
 	MythicBonusIds string `json:"mythic_bonus_ids"`
+	// ChampionItemLevel/HeroItemLevel/MythItemLevel are the lowest item
+	// level on each upgrade track this season (anything below Champion is
+	// Veteran) — the gear sync's fallback for working out a tier piece's
+	// track when the profile API doesn't name it. Hand-curated like the
+	// bonus IDs above; 0 means not set, and the sync then leaves unnamed
+	// tracks alone rather than guessing.
+	ChampionItemLevel uint `json:"champion_item_level"`
+	HeroItemLevel     uint `json:"hero_item_level"`
+	MythItemLevel     uint `json:"myth_item_level"`
+}
+
+// TierSourceForItemLevel maps an item level to its upgrade track using the
+// season's thresholds, or "" if they aren't set. Tracks overlap at their
+// edges (a maxed Hero piece shares an item level with a low Myth one), so
+// this picks the higher track — only a fallback, see ChampionItemLevel.
+func (s Season) TierSourceForItemLevel(itemLevel uint) string {
+	if s.ChampionItemLevel == 0 || s.HeroItemLevel == 0 || s.MythItemLevel == 0 {
+		return ""
+	}
+	switch {
+	case itemLevel >= s.MythItemLevel:
+		return TierSourceMyth
+	case itemLevel >= s.HeroItemLevel:
+		return TierSourceHero
+	case itemLevel >= s.ChampionItemLevel:
+		return TierSourceChampion
+	default:
+		return TierSourceVeteran
+	}
 }
 
 type Boss struct {
@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestSeasonTierSourceForItemLevel(t *testing.T) {
+	season := Season{ChampionItemLevel: 645, HeroItemLevel: 658, MythItemLevel: 671}
+
+	tests := []struct {
+		name      string
+		season    Season
+		itemLevel uint
+		want      string
+	}{
+		{"below champion", season, 640, TierSourceVeteran},
+		{"champion floor", season, 645, TierSourceChampion},
+		{"just below hero", season, 657, TierSourceChampion},
+		{"hero floor", season, 658, TierSourceHero},
+		{"myth floor", season, 671, TierSourceMyth},
+		{"above myth", season, 700, TierSourceMyth},
+		{"thresholds unset", Season{}, 671, ""},
+		{"one threshold missing", Season{ChampionItemLevel: 645, HeroItemLevel: 658}, 671, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.season.TierSourceForItemLevel(tt.itemLevel); got != tt.want {
+				t.Fatalf("TierSourceForItemLevel(%d) = %q, want %q", tt.itemLevel, got, tt.want)
+			}
+		})
+	}
+}
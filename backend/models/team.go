@@ -12,6 +12,7 @@ type Team struct {
 	WoWAuditDataSyncDate time.Time        `json:"wowaudit_data_synced_at"`
 	WowAuditUrl          string           `json:"wowaudit_url"`
 	WowAuditApiKey       string           `json:"wowaudit_api_key"`
+	LootMode             string           `json:"loot_mode" gorm:"default:council"`
 	Roles                []Role           `json:"roles" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	Players              []Player         `json:"players" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	Sections             []Section        `json:"sections" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
 
 // Tier slot values for CharacterTierSlot.Slot — the 5 WoW tier-token slots.
 // Deliberately its own enum, decoupled from Item.Slot (which is unconstrained
@@ -27,9 +31,48 @@ const (
 	TierSourceInVault       = "In Vault"
 )
 
+// tierSourceRanks orders the upgrade-track sources that count toward the
+// 2pc/4pc set bonus, lowest first. Embellishment, In Vault and None aren't
+// owned tier pieces and so have no rank.
+var tierSourceRanks = map[string]int{
+	TierSourceVeteran:  1,
+	TierSourceChampion: 2,
+	TierSourceHero:     3,
+	TierSourceMyth:     4,
+}
+
+// TierSourceCountsTowardSet reports whether a slot with this Source is an
+// owned tier piece — the same rule the tier tracker grid colors by.
+func TierSourceCountsTowardSet(source string) bool {
+	_, ok := tierSourceRanks[source]
+	return ok
+}
+
+// TierSourceUpgrades reports whether replacing current with next is an
+// upgrade, i.e. next is an owned-piece source ranked above current (any
+// owned source beats a non-owned one).
+func TierSourceUpgrades(current, next string) bool {
+	nextRank, ok := tierSourceRanks[next]
+	if !ok {
+		return false
+	}
+	return nextRank > tierSourceRanks[current]
+}
+
+// TierSourceForDifficulty is the upgrade track a raid tier token drops on —
+// Heroic tokens are Hero track, Mythic tokens Myth track.
+func TierSourceForDifficulty(difficulty string) string {
+	if difficulty == DifficultyMythic {
+		return TierSourceMyth
+	}
+	return TierSourceHero
+}
+
 // CharacterTierSlot is a current-snapshot record of where (or whether) a
 // character got a given tier slot's piece this season — overwritten in place
-// as the character's gear changes, no history kept.
+// as the character's gear changes. Every change is also appended to
+// CharacterTierSlotChange, and rolled up into the team's TierSetSnapshot
+// for the week.
 type CharacterTierSlot struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
 	CharacterID uint      `json:"character_id" gorm:"uniqueIndex:idx_char_tier_slot"`
@@ -39,6 +82,48 @@ type CharacterTierSlot struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// Origin values for CharacterTierSlotChange.Origin.
+const (
+	TierChangeManual = "manual"
+	TierChangeToken  = "token"
+	TierChangeSync   = "sync"
+)
+
+// CharacterTierSlotChange is one change to a CharacterTierSlot — append-only
+// history, since the slot row itself is overwritten in place.
+// ActingUserID is nil for changes no user made directly.
+type CharacterTierSlotChange struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CharacterID    uint      `json:"character_id" gorm:"index"`
+	Character      Character `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Slot           string    `json:"slot"`
+	PreviousSource string    `json:"previous_source"`
+	Source         string    `json:"source"`
+	Origin         string    `json:"origin"`
+	ActingUserID   *uint     `json:"acting_user_id"`
+	CreatedAt      time.Time `json:"created_at" gorm:"index"`
+}
+
+// TierSetSnapshot is a team's set-count distribution for one week (WeekStart
+// is 00:00 UTC on that week's Monday) — Distribution[n] is how many roster
+// characters owned n tier pieces, n = 0..5. Rewritten whenever a tier slot
+// on the team changes during that week, and periodically by the snapshot
+// job (to catch roster changes) once a team has a row for the season, so
+// each week's row ends up holding the state as of the week's end; weeks
+// the job missed have no row.
+type TierSetSnapshot struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	TeamID       uint           `json:"team_id" gorm:"uniqueIndex:idx_team_tier_week"`
+	Team         Team           `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SeasonID     uint           `json:"season_id" gorm:"index"`
+	WeekStart    time.Time      `json:"week_start" gorm:"uniqueIndex:idx_team_tier_week"`
+	RosterSize   uint           `json:"roster_size"`
+	Distribution datatypes.JSON `json:"distribution"`
+	With2pc      uint           `json:"with_2pc"`
+	With4pc      uint           `json:"with_4pc"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
 // TierSimEntry is community theorycrafting data for a spec: the 0pc/2pc/4pc
 // tier-bonus scores. All raw-diff/%-gain figures for the 0-2pc, 0-4pc, and
 // 2-4pc comparisons are derived from these 3 numbers at read time rather than
@@ -46,15 +131,24 @@ type CharacterTierSlot struct {
 // identically across comparison groups, so storing all 12 columns would just
 // be redundant, driftable data.
 //
-// Read-only from the app's perspective: populated by hand directly in the
-// database each season by the team owner, not through any in-app import UI.
+// Populated each season by a team owner importing the community
+// spreadsheet as CSV (see TierSimImport), upserting on
+// idx_season_spec_build. Imported rows belong to the importing team, so one
+// owner can't rewrite every team's numbers; TeamID 0 marks the shared rows
+// still maintained by hand in the database, which a team reads until it
+// imports its own for the season (see tierSimEntriesFor).
 //
 // A spec can have more than one row per season — most specs have 2
 // competitively viable hero-talent builds with meaningfully different
 // numbers (e.g. Fire Mage "Sunfury" vs "Frostfire") — so the unique key
-// includes BuildLabel, not just (season, spec).
+// includes BuildLabel, not just (season, spec). Because rows are per team,
+// idx_season_spec_build is (team_id, season_id, specialization_id,
+// build_label) rather than the (season_id, specialization_id, build_label)
+// key originally asked for; fixWidenedUniqueIndexes rebuilds the old
+// narrower index on existing databases.
 type TierSimEntry struct {
 	ID               uint           `json:"id" gorm:"primaryKey"`
+	TeamID           uint           `json:"team_id" gorm:"not null;default:0;uniqueIndex:idx_season_spec_build"`
 	SeasonID         uint           `json:"season_id" gorm:"uniqueIndex:idx_season_spec_build"`
 	Season           Season         `json:"season" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	SpecializationID uint           `json:"specialization_id" gorm:"uniqueIndex:idx_season_spec_build"`
@@ -66,3 +160,107 @@ type TierSimEntry struct {
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 }
+
+// TierToken marks an Item as a raid tier token and records which tier slot
+// it converts into. Tokens are seeded by cmd/seed-items (see its
+// tierTokenPrefixArmorTypes) — the armor type restriction stays on the Item
+// itself, this only adds the slot, which Wowhead's tooltip doesn't carry.
+// Marking a token wish obtained fills in the matching CharacterTierSlot.
+type TierToken struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	ItemID uint   `json:"item_id" gorm:"uniqueIndex"`
+	Item   Item   `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Slot   string `json:"slot"`
+}
+
+// CatalystChargeWeek records the Revival Catalyst charges a character
+// gained and spent in one week (WeekStart is 00:00 UTC on that week's
+// Monday). Charges carry over, so what's available now is the sum of
+// Gained-Spent across every week of the season, not just the latest row.
+type CatalystChargeWeek struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	CharacterID uint      `json:"character_id" gorm:"uniqueIndex:idx_char_catalyst_week"`
+	Character   Character `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SeasonID    uint      `json:"season_id" gorm:"index"`
+	WeekStart   time.Time `json:"week_start" gorm:"uniqueIndex:idx_char_catalyst_week"`
+	Gained      uint      `json:"gained"`
+	Spent       uint      `json:"spent"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Action values for TierSimImportChange.Action.
+const (
+	TierSimImportCreate    = "create"
+	TierSimImportUpdate    = "update"
+	TierSimImportUnchanged = "unchanged"
+)
+
+// TierSimImportChange is one row of a TierSimImport — the entry's key and
+// scores as imported, plus the scores it replaced for an update.
+type TierSimImportChange struct {
+	Action           string   `json:"action"`
+	SpecializationID uint     `json:"specialization_id"`
+	ClassName        string   `json:"class_name"`
+	SpecName         string   `json:"spec_name"`
+	BuildLabel       string   `json:"build_label"`
+	Score0pc         float64  `json:"score_0pc"`
+	Score2pc         float64  `json:"score_2pc"`
+	Score4pc         float64  `json:"score_4pc"`
+	Previous0pc      *float64 `json:"previous_0pc,omitempty"`
+	Previous2pc      *float64 `json:"previous_2pc,omitempty"`
+	Previous4pc      *float64 `json:"previous_4pc,omitempty"`
+}
+
+// TierSimImport records one applied TierSimEntry CSV import: who ran it,
+// for which team, into which season, and the per-row Changes
+// ([]TierSimImportChange) — the audit trail for the team's own tier sim
+// rows. Each team only sees its own imports.
+type TierSimImport struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	SeasonID         uint           `json:"season_id" gorm:"index"`
+	TeamID           uint           `json:"team_id" gorm:"index"`
+	ImportedByUserID uint           `json:"imported_by_user_id"`
+	ImportedByBTag   string         `json:"imported_by_btag"`
+	Created          uint           `json:"created"`
+	Updated          uint           `json:"updated"`
+	Unchanged        uint           `json:"unchanged"`
+	Changes          datatypes.JSON `json:"changes"`
+	CreatedAt        time.Time      `json:"created_at" gorm:"index"`
+}
+
+// Kind values for GearSyncMismatch.Kind.
+const (
+	GearMismatchClass        = "class"
+	GearMismatchSpec         = "spec"
+	GearMismatchTierSlot     = "tier_slot"
+	GearMismatchUnknownTrack = "unknown_track"
+)
+
+// GearSyncMismatch is one disagreement between the app's data and what the
+// Blizzard profile API reports for a character. Expected is the app's
+// value, Actual the API's.
+type GearSyncMismatch struct {
+	Kind     string `json:"kind"`
+	Slot     string `json:"slot,omitempty"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// CharacterGearSync is the latest gear-sync result for a character — one
+// row per character, overwritten on each run. Tier slots the API can vouch
+// for are written straight to CharacterTierSlot (origin "sync"); everything
+// the sync won't overwrite on its own (the character's chosen spec, a
+// tracked piece that isn't equipped) is listed in Mismatches
+// ([]GearSyncMismatch) for a human to resolve. Error is set, and the rest
+// left as of the last good run, when the API call itself failed.
+type CharacterGearSync struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	CharacterID  uint           `json:"character_id" gorm:"uniqueIndex"`
+	Character    Character      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	LastSyncedAt *time.Time     `json:"last_synced_at"`
+	Error        string         `json:"error"`
+	ActiveSpec   string         `json:"active_spec"`
+	ItemLevel    uint           `json:"item_level"`
+	Mismatches   datatypes.JSON `json:"mismatches"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
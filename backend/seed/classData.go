@@ -1,5 +1,7 @@
 package seed
 
+import "krankenprep/models"
+
 // ============================================================================
 // REVIEW REQUIRED before relying on this data for real loot decisions.
 //
@@ -124,16 +126,25 @@ type specSeed struct {
 	Role        string // Tank / Healer / DPS — see models.RoleTank etc.
 	IconUrl     string
 	WeaponTypes []string
+	// Tags are the raid buffs/debuffs/utility the spec brings, for the comp
+	// builder's coverage checklist — see models.RaidCoverageChecklist.
+	Tags []string
 }
 
 // specSeeds is the full spec roster. See the REVIEW REQUIRED note above —
 // WeaponTypes lists especially need a careful pass before this is trusted.
 // Role is standard, stable WoW knowledge and should be reliable as drafted.
+// Tags mostly follow the class (every Mage brings Arcane Intellect); the
+// spec-only ones are the healer raid cooldowns (Aura Mastery, Power Word:
+// Barrier, Spirit Link Totem). Hunters are tagged for Bloodlust on the
+// assumption they bring a Ferocity pet, and talent-gated utility (Power
+// Infusion, Wind Rush, Darkness) is tagged because nearly every raid build
+// takes it — worth a look when the talent trees shift.
 var specSeeds = []specSeed{
 	// Death Knight — Plate, Strength, all specs share the same melee list.
-	{ClassName: "Death Knight", Name: "Blood", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "Tank", IconUrl: "/icons/classes/deathknight_blood.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm}},
-	{ClassName: "Death Knight", Name: "Frost", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/deathknight_frost.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm}},
-	{ClassName: "Death Knight", Name: "Unholy", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/deathknight_unholy.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm}},
+	{ClassName: "Death Knight", Name: "Blood", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "Tank", IconUrl: "/icons/classes/deathknight_blood.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm}, Tags: []string{models.TagBattleRes, models.TagRaidDefensive}},
+	{ClassName: "Death Knight", Name: "Frost", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/deathknight_frost.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm}, Tags: []string{models.TagBattleRes, models.TagRaidDefensive}},
+	{ClassName: "Death Knight", Name: "Unholy", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/deathknight_unholy.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm}, Tags: []string{models.TagBattleRes, models.TagRaidDefensive}},
 
 	// Demon Hunter — Leather. Havoc/Vengeance are Agility; Devourer (this
 	// game's third, non-standard spec) is Intellect instead — confirmed.
@@ -141,70 +152,70 @@ var specSeeds = []specSeed{
 	// weapon type (Blizzard subclass 9). Fist Weapon/Axe/Sword are still an
 	// unconfirmed placeholder guess for the rest of the list — only the
 	// Warglaive addition and Devourer's stat are confirmed.
-	{ClassName: "Demon Hunter", Name: "Havoc", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/demonhunter_havoc.png", WeaponTypes: []string{WeaponFistWeapon, WeaponAxe1H, WeaponSword1H, WeaponWarglaive}},
-	{ClassName: "Demon Hunter", Name: "Vengeance", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "Tank", IconUrl: "/icons/classes/demonhunter_vengeance.png", WeaponTypes: []string{WeaponFistWeapon, WeaponAxe1H, WeaponSword1H, WeaponWarglaive}},
-	{ClassName: "Demon Hunter", Name: "Devourer", ArmorType: ArmorLeather, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/demonhunter_devourer.jpg", WeaponTypes: []string{WeaponFistWeapon, WeaponAxe1H, WeaponSword1H, WeaponWarglaive}},
+	{ClassName: "Demon Hunter", Name: "Havoc", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/demonhunter_havoc.png", WeaponTypes: []string{WeaponFistWeapon, WeaponAxe1H, WeaponSword1H, WeaponWarglaive}, Tags: []string{models.TagChaosBrand, models.TagRaidDefensive}},
+	{ClassName: "Demon Hunter", Name: "Vengeance", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "Tank", IconUrl: "/icons/classes/demonhunter_vengeance.png", WeaponTypes: []string{WeaponFistWeapon, WeaponAxe1H, WeaponSword1H, WeaponWarglaive}, Tags: []string{models.TagChaosBrand, models.TagRaidDefensive}},
+	{ClassName: "Demon Hunter", Name: "Devourer", ArmorType: ArmorLeather, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/demonhunter_devourer.jpg", WeaponTypes: []string{WeaponFistWeapon, WeaponAxe1H, WeaponSword1H, WeaponWarglaive}, Tags: []string{models.TagChaosBrand, models.TagRaidDefensive}},
 
 	// Druid — Leather. Balance/Restoration Intellect, Feral/Guardian Agility.
-	{ClassName: "Druid", Name: "Balance", ArmorType: ArmorLeather, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/druid_balance.png", WeaponTypes: []string{WeaponDagger, WeaponFistWeapon, WeaponMace1H, WeaponStaff, WeaponPolearm}},
-	{ClassName: "Druid", Name: "Feral", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/druid_feral.png", WeaponTypes: []string{WeaponDagger, WeaponFistWeapon, WeaponMace1H, WeaponStaff, WeaponPolearm}},
-	{ClassName: "Druid", Name: "Guardian", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "Tank", IconUrl: "/icons/classes/druid_guardian.png", WeaponTypes: []string{WeaponDagger, WeaponFistWeapon, WeaponMace1H, WeaponStaff, WeaponPolearm}},
-	{ClassName: "Druid", Name: "Restoration", ArmorType: ArmorLeather, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/druid_restoration.png", WeaponTypes: []string{WeaponDagger, WeaponFistWeapon, WeaponMace1H, WeaponStaff, WeaponPolearm}},
+	{ClassName: "Druid", Name: "Balance", ArmorType: ArmorLeather, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/druid_balance.png", WeaponTypes: []string{WeaponDagger, WeaponFistWeapon, WeaponMace1H, WeaponStaff, WeaponPolearm}, Tags: []string{models.TagMarkOfTheWild, models.TagBattleRes, models.TagMovement}},
+	{ClassName: "Druid", Name: "Feral", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/druid_feral.png", WeaponTypes: []string{WeaponDagger, WeaponFistWeapon, WeaponMace1H, WeaponStaff, WeaponPolearm}, Tags: []string{models.TagMarkOfTheWild, models.TagBattleRes, models.TagMovement}},
+	{ClassName: "Druid", Name: "Guardian", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "Tank", IconUrl: "/icons/classes/druid_guardian.png", WeaponTypes: []string{WeaponDagger, WeaponFistWeapon, WeaponMace1H, WeaponStaff, WeaponPolearm}, Tags: []string{models.TagMarkOfTheWild, models.TagBattleRes, models.TagMovement}},
+	{ClassName: "Druid", Name: "Restoration", ArmorType: ArmorLeather, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/druid_restoration.png", WeaponTypes: []string{WeaponDagger, WeaponFistWeapon, WeaponMace1H, WeaponStaff, WeaponPolearm}, Tags: []string{models.TagMarkOfTheWild, models.TagBattleRes, models.TagMovement}},
 
 	// Evoker — Mail, Intellect, all specs. LOW CONFIDENCE (newest class).
-	{ClassName: "Evoker", Name: "Devastation", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/evoker_devastation.png", WeaponTypes: []string{WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponDagger}},
-	{ClassName: "Evoker", Name: "Preservation", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/evoker_preservation.png", WeaponTypes: []string{WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponDagger}},
-	{ClassName: "Evoker", Name: "Augmentation", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/evoker_augmentation.png", WeaponTypes: []string{WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponDagger}},
+	{ClassName: "Evoker", Name: "Devastation", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/evoker_devastation.png", WeaponTypes: []string{WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponDagger}, Tags: []string{models.TagBlessingOfTheBronze, models.TagBloodlust}},
+	{ClassName: "Evoker", Name: "Preservation", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/evoker_preservation.png", WeaponTypes: []string{WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponDagger}, Tags: []string{models.TagBlessingOfTheBronze, models.TagBloodlust}},
+	{ClassName: "Evoker", Name: "Augmentation", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/evoker_augmentation.png", WeaponTypes: []string{WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponDagger}, Tags: []string{models.TagBlessingOfTheBronze, models.TagBloodlust}},
 
 	// Hunter — Mail, Agility, all specs share a broad ranged+melee list.
 	// Note: "marksmenship" is a typo in the actual icon filename — copied
 	// verbatim from PropertiesPanel.tsx since that's the real asset path.
-	{ClassName: "Hunter", Name: "Beast Mastery", ArmorType: ArmorMail, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/hunter_beastmastery.png", WeaponTypes: []string{WeaponBow, WeaponGun, WeaponCrossbow, WeaponAxe1H, WeaponAxe2H, WeaponSword1H, WeaponSword2H, WeaponFistWeapon, WeaponDagger, WeaponPolearm}},
-	{ClassName: "Hunter", Name: "Marksmanship", ArmorType: ArmorMail, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/hunter_marksmenship.png", WeaponTypes: []string{WeaponBow, WeaponGun, WeaponCrossbow, WeaponAxe1H, WeaponAxe2H, WeaponSword1H, WeaponSword2H, WeaponFistWeapon, WeaponDagger, WeaponPolearm}},
-	{ClassName: "Hunter", Name: "Survival", ArmorType: ArmorMail, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/hunter_survival.png", WeaponTypes: []string{WeaponBow, WeaponGun, WeaponCrossbow, WeaponAxe1H, WeaponAxe2H, WeaponSword1H, WeaponSword2H, WeaponFistWeapon, WeaponDagger, WeaponPolearm}},
+	{ClassName: "Hunter", Name: "Beast Mastery", ArmorType: ArmorMail, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/hunter_beastmastery.png", WeaponTypes: []string{WeaponBow, WeaponGun, WeaponCrossbow, WeaponAxe1H, WeaponAxe2H, WeaponSword1H, WeaponSword2H, WeaponFistWeapon, WeaponDagger, WeaponPolearm}, Tags: []string{models.TagHuntersMark, models.TagBloodlust}},
+	{ClassName: "Hunter", Name: "Marksmanship", ArmorType: ArmorMail, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/hunter_marksmenship.png", WeaponTypes: []string{WeaponBow, WeaponGun, WeaponCrossbow, WeaponAxe1H, WeaponAxe2H, WeaponSword1H, WeaponSword2H, WeaponFistWeapon, WeaponDagger, WeaponPolearm}, Tags: []string{models.TagHuntersMark, models.TagBloodlust}},
+	{ClassName: "Hunter", Name: "Survival", ArmorType: ArmorMail, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/hunter_survival.png", WeaponTypes: []string{WeaponBow, WeaponGun, WeaponCrossbow, WeaponAxe1H, WeaponAxe2H, WeaponSword1H, WeaponSword2H, WeaponFistWeapon, WeaponDagger, WeaponPolearm}, Tags: []string{models.TagHuntersMark, models.TagBloodlust}},
 
 	// Mage — Cloth, Intellect, all specs.
-	{ClassName: "Mage", Name: "Arcane", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/mage_arcane.png", WeaponTypes: []string{WeaponSword1H, WeaponDagger, WeaponWand, WeaponStaff}},
-	{ClassName: "Mage", Name: "Fire", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/mage_fire.png", WeaponTypes: []string{WeaponSword1H, WeaponDagger, WeaponWand, WeaponStaff}},
-	{ClassName: "Mage", Name: "Frost", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/mage_frost.png", WeaponTypes: []string{WeaponSword1H, WeaponDagger, WeaponWand, WeaponStaff}},
+	{ClassName: "Mage", Name: "Arcane", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/mage_arcane.png", WeaponTypes: []string{WeaponSword1H, WeaponDagger, WeaponWand, WeaponStaff}, Tags: []string{models.TagArcaneIntellect, models.TagBloodlust}},
+	{ClassName: "Mage", Name: "Fire", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/mage_fire.png", WeaponTypes: []string{WeaponSword1H, WeaponDagger, WeaponWand, WeaponStaff}, Tags: []string{models.TagArcaneIntellect, models.TagBloodlust}},
+	{ClassName: "Mage", Name: "Frost", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/mage_frost.png", WeaponTypes: []string{WeaponSword1H, WeaponDagger, WeaponWand, WeaponStaff}, Tags: []string{models.TagArcaneIntellect, models.TagBloodlust}},
 
 	// Monk — Leather. Mistweaver Intellect, Brewmaster/Windwalker Agility.
-	{ClassName: "Monk", Name: "Brewmaster", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "Tank", IconUrl: "/icons/classes/monk_brewmaster.png", WeaponTypes: []string{WeaponStaff, WeaponPolearm, WeaponFistWeapon, WeaponSword1H, WeaponMace1H, WeaponAxe1H}},
-	{ClassName: "Monk", Name: "Mistweaver", ArmorType: ArmorLeather, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/monk_mistweaver.png", WeaponTypes: []string{WeaponStaff, WeaponPolearm, WeaponFistWeapon, WeaponSword1H, WeaponMace1H, WeaponAxe1H}},
-	{ClassName: "Monk", Name: "Windwalker", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/monk_windwalker.png", WeaponTypes: []string{WeaponStaff, WeaponPolearm, WeaponFistWeapon, WeaponSword1H, WeaponMace1H, WeaponAxe1H}},
+	{ClassName: "Monk", Name: "Brewmaster", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "Tank", IconUrl: "/icons/classes/monk_brewmaster.png", WeaponTypes: []string{WeaponStaff, WeaponPolearm, WeaponFistWeapon, WeaponSword1H, WeaponMace1H, WeaponAxe1H}, Tags: []string{models.TagMysticTouch}},
+	{ClassName: "Monk", Name: "Mistweaver", ArmorType: ArmorLeather, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/monk_mistweaver.png", WeaponTypes: []string{WeaponStaff, WeaponPolearm, WeaponFistWeapon, WeaponSword1H, WeaponMace1H, WeaponAxe1H}, Tags: []string{models.TagMysticTouch}},
+	{ClassName: "Monk", Name: "Windwalker", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/monk_windwalker.png", WeaponTypes: []string{WeaponStaff, WeaponPolearm, WeaponFistWeapon, WeaponSword1H, WeaponMace1H, WeaponAxe1H}, Tags: []string{models.TagMysticTouch}},
 
 	// Paladin — Plate. Holy Intellect, Protection/Retribution Strength.
 	// Holy and Protection are both shield-capable (confirmed).
-	{ClassName: "Paladin", Name: "Holy", ArmorType: ArmorPlate, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/paladin_holy.png", WeaponTypes: []string{WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponAxe1H, WeaponAxe2H, WeaponPolearm, WeaponShield}},
-	{ClassName: "Paladin", Name: "Protection", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "Tank", IconUrl: "/icons/classes/paladin_protection.png", WeaponTypes: []string{WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponAxe1H, WeaponAxe2H, WeaponPolearm, WeaponShield}},
-	{ClassName: "Paladin", Name: "Retribution", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/paladin_retribution.png", WeaponTypes: []string{WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponAxe1H, WeaponAxe2H, WeaponPolearm}},
+	{ClassName: "Paladin", Name: "Holy", ArmorType: ArmorPlate, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/paladin_holy.png", WeaponTypes: []string{WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponAxe1H, WeaponAxe2H, WeaponPolearm, WeaponShield}, Tags: []string{models.TagBattleRes, models.TagRaidDefensive}},
+	{ClassName: "Paladin", Name: "Protection", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "Tank", IconUrl: "/icons/classes/paladin_protection.png", WeaponTypes: []string{WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponAxe1H, WeaponAxe2H, WeaponPolearm, WeaponShield}, Tags: []string{models.TagBattleRes}},
+	{ClassName: "Paladin", Name: "Retribution", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/paladin_retribution.png", WeaponTypes: []string{WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponAxe1H, WeaponAxe2H, WeaponPolearm}, Tags: []string{models.TagBattleRes}},
 
 	// Priest — Cloth, Intellect, all specs.
-	{ClassName: "Priest", Name: "Discipline", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/priest_discipline.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponMace1H}},
-	{ClassName: "Priest", Name: "Holy", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/priest_holy.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponMace1H}},
-	{ClassName: "Priest", Name: "Shadow", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/priest_shadow.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponMace1H}},
+	{ClassName: "Priest", Name: "Discipline", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/priest_discipline.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponMace1H}, Tags: []string{models.TagPowerWordFortitude, models.TagPowerInfusion, models.TagRaidDefensive}},
+	{ClassName: "Priest", Name: "Holy", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/priest_holy.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponMace1H}, Tags: []string{models.TagPowerWordFortitude, models.TagPowerInfusion}},
+	{ClassName: "Priest", Name: "Shadow", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/priest_shadow.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponMace1H}, Tags: []string{models.TagPowerWordFortitude, models.TagPowerInfusion}},
 
 	// Rogue — Leather, Agility, all specs.
-	{ClassName: "Rogue", Name: "Assassination", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/rogue_assassination.png", WeaponTypes: []string{WeaponDagger, WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponFistWeapon}},
-	{ClassName: "Rogue", Name: "Outlaw", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/rogue_outlaw.png", WeaponTypes: []string{WeaponDagger, WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponFistWeapon}},
-	{ClassName: "Rogue", Name: "Subtlety", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/rogue_subtlety.png", WeaponTypes: []string{WeaponDagger, WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponFistWeapon}},
+	{ClassName: "Rogue", Name: "Assassination", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/rogue_assassination.png", WeaponTypes: []string{WeaponDagger, WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponFistWeapon}, Tags: []string{}},
+	{ClassName: "Rogue", Name: "Outlaw", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/rogue_outlaw.png", WeaponTypes: []string{WeaponDagger, WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponFistWeapon}, Tags: []string{}},
+	{ClassName: "Rogue", Name: "Subtlety", ArmorType: ArmorLeather, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/rogue_subtlety.png", WeaponTypes: []string{WeaponDagger, WeaponSword1H, WeaponAxe1H, WeaponMace1H, WeaponFistWeapon}, Tags: []string{}},
 
 	// Shaman — Mail. Elemental/Restoration Intellect (staff-capable),
 	// Enhancement Agility (no staff) — confirmed by user during planning.
 	// Elemental and Restoration are both shield-capable (confirmed);
 	// Enhancement is not (dual-wields).
-	{ClassName: "Shaman", Name: "Elemental", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/shaman_elemental.png", WeaponTypes: []string{WeaponMace1H, WeaponAxe1H, WeaponSword1H, WeaponDagger, WeaponStaff, WeaponShield}},
-	{ClassName: "Shaman", Name: "Enhancement", ArmorType: ArmorMail, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/shaman_enhancement.png", WeaponTypes: []string{WeaponMace1H, WeaponAxe1H, WeaponSword1H, WeaponDagger, WeaponFistWeapon}},
-	{ClassName: "Shaman", Name: "Restoration", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/shaman_restoration.png", WeaponTypes: []string{WeaponMace1H, WeaponAxe1H, WeaponSword1H, WeaponDagger, WeaponStaff, WeaponShield}},
+	{ClassName: "Shaman", Name: "Elemental", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/shaman_elemental.png", WeaponTypes: []string{WeaponMace1H, WeaponAxe1H, WeaponSword1H, WeaponDagger, WeaponStaff, WeaponShield}, Tags: []string{models.TagSkyfury, models.TagBloodlust, models.TagMovement}},
+	{ClassName: "Shaman", Name: "Enhancement", ArmorType: ArmorMail, PrimaryStat: "Agility", Role: "DPS", IconUrl: "/icons/classes/shaman_enhancement.png", WeaponTypes: []string{WeaponMace1H, WeaponAxe1H, WeaponSword1H, WeaponDagger, WeaponFistWeapon}, Tags: []string{models.TagSkyfury, models.TagBloodlust, models.TagMovement}},
+	{ClassName: "Shaman", Name: "Restoration", ArmorType: ArmorMail, PrimaryStat: "Intellect", Role: "Healer", IconUrl: "/icons/classes/shaman_restoration.png", WeaponTypes: []string{WeaponMace1H, WeaponAxe1H, WeaponSword1H, WeaponDagger, WeaponStaff, WeaponShield}, Tags: []string{models.TagSkyfury, models.TagBloodlust, models.TagMovement, models.TagRaidDefensive}},
 
 	// Warlock — Cloth, Intellect, all specs.
-	{ClassName: "Warlock", Name: "Affliction", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/warlock_affliction.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponSword1H}},
-	{ClassName: "Warlock", Name: "Demonology", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/warlock_demonology.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponSword1H}},
-	{ClassName: "Warlock", Name: "Destruction", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/warlock_destruction.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponSword1H}},
+	{ClassName: "Warlock", Name: "Affliction", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/warlock_affliction.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponSword1H}, Tags: []string{models.TagBattleRes, models.TagHealthstone, models.TagMovement}},
+	{ClassName: "Warlock", Name: "Demonology", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/warlock_demonology.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponSword1H}, Tags: []string{models.TagBattleRes, models.TagHealthstone, models.TagMovement}},
+	{ClassName: "Warlock", Name: "Destruction", ArmorType: ArmorCloth, PrimaryStat: "Intellect", Role: "DPS", IconUrl: "/icons/classes/warlock_destruction.png", WeaponTypes: []string{WeaponWand, WeaponDagger, WeaponStaff, WeaponSword1H}, Tags: []string{models.TagBattleRes, models.TagHealthstone, models.TagMovement}},
 
 	// Warrior — Plate, Strength, all specs share the broadest melee list.
 	// Protection is shield-capable (confirmed); Arms/Fury are not (two-handers).
-	{ClassName: "Warrior", Name: "Arms", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/warrior_arms.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm, WeaponDagger, WeaponFistWeapon, WeaponStaff}},
-	{ClassName: "Warrior", Name: "Fury", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/warrior_fury.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm, WeaponDagger, WeaponFistWeapon, WeaponStaff}},
-	{ClassName: "Warrior", Name: "Protection", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "Tank", IconUrl: "/icons/classes/warrior_protection.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm, WeaponDagger, WeaponFistWeapon, WeaponStaff, WeaponShield}},
+	{ClassName: "Warrior", Name: "Arms", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/warrior_arms.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm, WeaponDagger, WeaponFistWeapon, WeaponStaff}, Tags: []string{models.TagBattleShout, models.TagRaidDefensive}},
+	{ClassName: "Warrior", Name: "Fury", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "DPS", IconUrl: "/icons/classes/warrior_fury.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm, WeaponDagger, WeaponFistWeapon, WeaponStaff}, Tags: []string{models.TagBattleShout, models.TagRaidDefensive}},
+	{ClassName: "Warrior", Name: "Protection", ArmorType: ArmorPlate, PrimaryStat: "Strength", Role: "Tank", IconUrl: "/icons/classes/warrior_protection.png", WeaponTypes: []string{WeaponAxe1H, WeaponAxe2H, WeaponMace1H, WeaponMace2H, WeaponSword1H, WeaponSword2H, WeaponPolearm, WeaponDagger, WeaponFistWeapon, WeaponStaff, WeaponShield}, Tags: []string{models.TagBattleShout, models.TagRaidDefensive}},
 }
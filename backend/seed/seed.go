@@ -239,6 +239,20 @@ func SeedSpecializations(db *gorm.DB) error {
 		if err := db.Model(&spec).Association("WeaponTypes").Replace(weaponTypes); err != nil {
 			return fmt.Errorf("associating weapon types for spec %s: %w", s.Name, err)
 		}
+
+		// Tags are replaced wholesale on every boot, like WeaponTypes.
+		if err := db.Where("specialization_id = ?", spec.ID).Delete(&models.SpecializationTag{}).Error; err != nil {
+			return fmt.Errorf("clearing tags for spec %s: %w", s.Name, err)
+		}
+		if len(s.Tags) > 0 {
+			tags := make([]models.SpecializationTag, len(s.Tags))
+			for i, tag := range s.Tags {
+				tags[i] = models.SpecializationTag{SpecializationID: spec.ID, Tag: tag}
+			}
+			if err := db.Create(&tags).Error; err != nil {
+				return fmt.Errorf("seeding tags for spec %s: %w", s.Name, err)
+			}
+		}
 	}
 
 	return nil
@@ -64,3 +64,42 @@ type TypeNameField struct {
 	Type string `json:"type"`
 	Name string `json:"name"`
 }
+
+type NamedRef struct {
+	Key  HrefOnly `json:"key"`
+	Name string   `json:"name"`
+	ID   int      `json:"id"`
+}
+
+// CharacterProfileSummary is the subset of the profile API's character
+// summary (/profile/wow/character/{realm}/{name}) the gear sync reads.
+type CharacterProfileSummary struct {
+	Name           string        `json:"name"`
+	CharacterClass PlayableClass `json:"character_class"`
+	ActiveSpec     NamedRef      `json:"active_spec"`
+}
+
+// CharacterEquipment is the profile API's character equipment document
+// (/profile/wow/character/{realm}/{name}/equipment).
+type CharacterEquipment struct {
+	EquippedItems []EquippedItem `json:"equipped_items"`
+}
+
+type EquippedItem struct {
+	Item struct {
+		ID int `json:"id"`
+	} `json:"item"`
+	Slot  TypeNameField `json:"slot"`
+	Name  string        `json:"name"`
+	Level struct {
+		Value int `json:"value"`
+	} `json:"level"`
+	// Set is present only on items belonging to an item set — for the
+	// current season, that's the tier set.
+	Set *struct {
+		ItemSet NamedRef `json:"item_set"`
+	} `json:"set"`
+	NameDescription *struct {
+		DisplayString string `json:"display_string"`
+	} `json:"name_description"`
+}
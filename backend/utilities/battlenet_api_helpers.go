@@ -2,10 +2,16 @@ package utilities
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"krankenprep/types"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -72,3 +78,174 @@ func GetWowProfile(token string) (types.UserProfile, error) {
 	return userProfile, nil
 
 }
+
+// ErrBnetNotFound is returned by BnetClient lookups for a character the
+// profile API doesn't know — renamed, transferred, or never logged in
+// since the last patch.
+var ErrBnetNotFound = errors.New("battle.net: not found")
+
+const (
+	defaultBnetAPIBaseURL = "https://%s.api.blizzard.com"
+	defaultBnetTokenURL   = "https://oauth.battle.net/token"
+)
+
+// BnetClient calls the Blizzard profile API with an application
+// (client-credentials) token, refreshed shortly before it expires. Both
+// URLs are configurable so the gear sync can run against a local HTTP
+// stub: APIBaseURL may contain a %s for the region ("us", "eu", ...), or
+// none to use the same host for every region.
+type BnetClient struct {
+	APIBaseURL   string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	HTTPClient   *http.Client
+
+	mu           sync.Mutex
+	token        string
+	tokenExpires time.Time
+}
+
+// NewBnetClientFromEnv builds a BnetClient from BNET_CLIENT_ID and
+// BNET_CLIENT_SECRET (both required), with BNET_API_BASE_URL and
+// BNET_TOKEN_URL overriding the real Blizzard endpoints when set.
+func NewBnetClientFromEnv() (*BnetClient, error) {
+	client := &BnetClient{
+		APIBaseURL:   os.Getenv("BNET_API_BASE_URL"),
+		TokenURL:     os.Getenv("BNET_TOKEN_URL"),
+		ClientID:     os.Getenv("BNET_CLIENT_ID"),
+		ClientSecret: os.Getenv("BNET_CLIENT_SECRET"),
+		HTTPClient:   &http.Client{Timeout: 15 * time.Second},
+	}
+	if client.ClientID == "" || client.ClientSecret == "" {
+		return nil, fmt.Errorf("BNET_CLIENT_ID and BNET_CLIENT_SECRET environment variables must be set")
+	}
+	if client.APIBaseURL == "" {
+		client.APIBaseURL = defaultBnetAPIBaseURL
+	}
+	if client.TokenURL == "" {
+		client.TokenURL = defaultBnetTokenURL
+	}
+	return client, nil
+}
+
+// BnetRegion maps the app's region values to Blizzard's API regions — the
+// app calls North America "na", Blizzard calls it "us".
+func BnetRegion(region string) string {
+	region = strings.ToLower(strings.TrimSpace(region))
+	if region == "na" {
+		return "us"
+	}
+	return region
+}
+
+// BnetRealmSlug turns a realm name as players type it ("Twisting Nether",
+// "Mal'Ganis") into the profile API's slug ("twisting-nether", "malganis").
+// Already-slugged input passes through unchanged.
+func BnetRealmSlug(realm string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(realm)) {
+		switch {
+		case r == ' ' || r == '-':
+			b.WriteRune('-')
+		case r == '\'':
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (b *BnetClient) accessToken() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token != "" && time.Now().Before(b.tokenExpires) {
+		return b.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequest(http.MethodPost, b.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("creating battle.net token request: %w", err)
+	}
+	req.SetBasicAuth(b.ClientID, b.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := b.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("requesting battle.net token: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("battle.net token: unexpected status %s", res.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decoding battle.net token: %w", err)
+	}
+	b.token = body.AccessToken
+	// Refresh a minute early so a token never expires mid-sync.
+	b.tokenExpires = time.Now().Add(time.Duration(body.ExpiresIn)*time.Second - time.Minute)
+	return b.token, nil
+}
+
+func (b *BnetClient) getProfile(region, path string, out interface{}) error {
+	token, err := b.accessToken()
+	if err != nil {
+		return err
+	}
+
+	base := b.APIBaseURL
+	if strings.Contains(base, "%s") {
+		base = fmt.Sprintf(base, region)
+	}
+	fullURL := base + path + "?namespace=profile-" + region + "&locale=en_US"
+
+	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("creating battle.net profile request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := b.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting battle.net profile: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return ErrBnetNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("battle.net profile: unexpected status %s", res.Status)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding battle.net profile: %w", err)
+	}
+	return nil
+}
+
+func characterProfilePath(realm, name string) string {
+	return "/profile/wow/character/" + url.PathEscape(BnetRealmSlug(realm)) + "/" + url.PathEscape(strings.ToLower(name))
+}
+
+// GetCharacterProfile fetches a character's profile summary (class and
+// active spec).
+func (b *BnetClient) GetCharacterProfile(region, realm, name string) (types.CharacterProfileSummary, error) {
+	var summary types.CharacterProfileSummary
+	err := b.getProfile(BnetRegion(region), characterProfilePath(realm, name), &summary)
+	return summary, err
+}
+
+// GetCharacterEquipment fetches a character's currently equipped items.
+func (b *BnetClient) GetCharacterEquipment(region, realm, name string) (types.CharacterEquipment, error) {
+	var equipment types.CharacterEquipment
+	err := b.getProfile(BnetRegion(region), characterProfilePath(realm, name)+"/equipment", &equipment)
+	return equipment, err
+}
@@ -0,0 +1,56 @@
+package utilities
+
+import (
+	"fmt"
+	"krankenprep/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// BoePlayerKey is how BoE sales and payouts are grouped per player —
+// BoeSale/BoePayout PlayerName is free text, so matching is case- and
+// whitespace-insensitive.
+func BoePlayerKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// BoePlayerRename counts what RenameBoePlayer changed.
+type BoePlayerRename struct {
+	PayoutsRenamed int64
+	SalesLinked    int64
+}
+
+// RenameBoePlayer moves a team's BoE records from the free-text name they
+// were kept under to the linked player's canonical name. Sales and payouts
+// are grouped by BoePlayerKey, so without this linking a sale (which
+// replaces its PlayerName) would leave payouts recorded under the old name
+// unmatched — the sale shows unpaid and the payouts become orphan credit.
+// The team's other unlinked sales under the old name are linked along with
+// them so the player's sales and payouts stay together. Shared by the BoE
+// handlers and cmd/backfill-boe-links; run it in the same transaction as
+// the link itself.
+func RenameBoePlayer(tx *gorm.DB, teamId uint, oldName string, player models.Player) (BoePlayerRename, error) {
+	var rename BoePlayerRename
+	oldKey := BoePlayerKey(oldName)
+	if oldKey == "" {
+		return rename, nil
+	}
+	if oldKey != BoePlayerKey(player.Name) {
+		result := tx.Model(&models.BoePayout{}).
+			Where("team_id = ? AND LOWER(TRIM(player_name)) = ?", teamId, oldKey).
+			Update("player_name", player.Name)
+		if result.Error != nil {
+			return rename, fmt.Errorf("renaming BoE payouts: %w", result.Error)
+		}
+		rename.PayoutsRenamed = result.RowsAffected
+	}
+	result := tx.Model(&models.BoeSale{}).
+		Where("team_id = ? AND player_id IS NULL AND LOWER(TRIM(player_name)) = ?", teamId, oldKey).
+		Updates(map[string]interface{}{"player_id": player.ID, "player_name": player.Name})
+	if result.Error != nil {
+		return rename, fmt.Errorf("linking BoE sales: %w", result.Error)
+	}
+	rename.SalesLinked = result.RowsAffected
+	return rename, nil
+}
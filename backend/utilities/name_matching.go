@@ -0,0 +1,163 @@
+package utilities
+
+import (
+	"fmt"
+	"krankenprep/database"
+	"krankenprep/models"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm/clause"
+)
+
+// StripRealm drops a realm suffix from a character name ("Thrall-Area52"
+// becomes "Thrall") — rosters store the realm separately. Character names
+// can't contain a hyphen, so everything after the first one is the realm.
+func StripRealm(name string) string {
+	if i := strings.Index(name, "-"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// NameMatchKey reduces a name to lowercase letters and digits, so "Thrall",
+// " thrall" and "Thrall!" all compare equal. The spreadsheet importers key
+// column headers and class/spec/slot names by it too, so "Death Knight",
+// "DeathKnight" and "death-knight" resolve the same.
+func NameMatchKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ParseSpreadsheetNumber parses a number cell from a pasted or exported
+// spreadsheet, tolerating surrounding space and thousands separators
+// ("1,234,567").
+func ParseSpreadsheetNumber(value string) (float64, error) {
+	return strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+}
+
+// NameSimilarity scores two names from 0 (nothing in common) to 1 (same
+// NameMatchKey), as 1 minus their edit distance over the longer name's
+// length — forgiving of the one- or two-letter typos free-text entry
+// produces, while keeping short unrelated names well apart.
+func NameSimilarity(a, b string) float64 {
+	ka, kb := []rune(NameMatchKey(a)), []rune(NameMatchKey(b))
+	longest := max(len(ka), len(kb))
+	if longest == 0 {
+		return 0
+	}
+
+	prev := make([]int, len(kb)+1)
+	curr := make([]int, len(kb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ka); i++ {
+		curr[0] = i
+		for j := 1; j <= len(kb); j++ {
+			cost := 1
+			if ka[i-1] == kb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(kb)])/float64(longest)
+}
+
+// AutoLinkMinScore is the NameSimilarity a suggestion needs before anything
+// links it without a human confirming — high enough that only exact
+// matches and trivial typos ("Thral" for "Thrall") qualify.
+const AutoLinkMinScore = 0.8
+
+// PlayerSuggestion is a roster Player that a free-text name may refer to.
+// MatchedName is whichever of the player's own name or character names
+// scored best.
+type PlayerSuggestion struct {
+	PlayerID    uint    `json:"player_id"`
+	PlayerName  string  `json:"player_name"`
+	MatchedName string  `json:"matched_name"`
+	Score       float64 `json:"score"`
+}
+
+// SuggestTeamPlayers ranks the team's roster players against name, best
+// first, dropping anything below minScore. Players are matched on their own
+// name and every character's name, since BoE sellers are usually entered
+// by whichever character posted the auction.
+func SuggestTeamPlayers(teamId uint, name string, minScore float64, limit int) ([]PlayerSuggestion, error) {
+	var players []models.Player
+	if err := database.DB.Preload("Characters").Where("team_id = ?", teamId).Find(&players).Error; err != nil {
+		return nil, fmt.Errorf("fetching roster players: %w", err)
+	}
+
+	name = StripRealm(name)
+	suggestions := []PlayerSuggestion{}
+	for _, p := range players {
+		best := PlayerSuggestion{PlayerID: p.ID, PlayerName: p.Name, MatchedName: p.Name, Score: NameSimilarity(name, p.Name)}
+		for _, ch := range p.Characters {
+			if score := NameSimilarity(name, ch.Name); score > best.Score {
+				best.MatchedName = ch.Name
+				best.Score = score
+			}
+		}
+		if best.Score >= minScore {
+			suggestions = append(suggestions, best)
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].Score > suggestions[j].Score })
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// ItemSuggestion is a catalog Item a free-text item name may refer to.
+type ItemSuggestion struct {
+	WowItemID uint    `json:"wow_item_id"`
+	Name      string  `json:"name"`
+	Slot      string  `json:"slot"`
+	IconUrl   string  `json:"icon_url"`
+	Score     float64 `json:"score"`
+}
+
+// SuggestItems ranks catalog items against name using the pg_trgm index on
+// items.name (see idx_item_name_trgm), best first. Score is the trigram
+// similarity, except that an exact NameMatchKey match always scores 1 so
+// punctuation differences don't keep it from auto-linking.
+func SuggestItems(name string, minScore float64, limit int) ([]ItemSuggestion, error) {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		return []ItemSuggestion{}, nil
+	}
+
+	suggestions := []ItemSuggestion{}
+	err := database.DB.Model(&models.Item{}).
+		Select("wow_item_id, name, slot, icon_url, similarity(name, ?) AS score", trimmed).
+		Where("name % ? OR name ILIKE ?", trimmed, "%"+trimmed+"%").
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "similarity(name, ?) DESC", Vars: []interface{}{trimmed}, WithoutParentheses: true}}).
+		Limit(limit).
+		Scan(&suggestions).Error
+	if err != nil {
+		return nil, fmt.Errorf("searching items: %w", err)
+	}
+
+	kept := suggestions[:0]
+	for _, s := range suggestions {
+		if NameMatchKey(s.Name) == NameMatchKey(trimmed) {
+			s.Score = 1
+		}
+		if s.Score >= minScore {
+			kept = append(kept, s)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Score > kept[j].Score })
+	return kept, nil
+}
@@ -0,0 +1,78 @@
+package utilities
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStripRealm(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Thrall", "Thrall"},
+		{"Thrall-Area52", "Thrall"},
+		{"Thrall-Mal'Ganis-EU", "Thrall"},
+		{"-Area52", "-Area52"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := StripRealm(tt.name); got != tt.want {
+			t.Errorf("StripRealm(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNameMatchKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Thrall", "thrall"},
+		{"  thrall ", "thrall"},
+		{"Thrall!", "thrall"},
+		{"Mal'Ganis", "malganis"},
+		{"Area 52", "area52"},
+		{"Tëttybear", "tëttybear"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NameMatchKey(tt.name); got != tt.want {
+			t.Errorf("NameMatchKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		want     float64
+		autoLink bool
+	}{
+		{"identical", "Thrall", "Thrall", 1, true},
+		{"case and punctuation ignored", "thrall!", " Thrall", 1, true},
+		{"one-letter typo", "Thral", "Thrall", 5.0 / 6, true},
+		{"accent counts as a typo", "Tëttybear", "Tettybear", 8.0 / 9, true},
+		{"one substitution in a short name", "Bob", "Rob", 2.0 / 3, false},
+		{"extra word", "Dragonfang", "Dragonfang Helm", 10.0 / 14, false},
+		{"realm suffix is not stripped", "Thrall", "Thrall-Area52", 0.5, false},
+		{"unrelated", "Jaina", "Thrall", 0, false},
+		{"one side empty", "Thrall", "", 0, false},
+		{"both empty", "", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NameSimilarity(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("NameSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if reverse := NameSimilarity(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+				t.Fatalf("NameSimilarity isn't symmetric: %v one way, %v the other", got, reverse)
+			}
+			if linked := got >= AutoLinkMinScore; linked != tt.autoLink {
+				t.Fatalf("score %v auto-links = %v, want %v", got, linked, tt.autoLink)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package utilities
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Raid marker numbers for the {rtN} / named marker icons, in the game's own
+// order.
+var raidMarkerNames = map[string]int{
+	"star": 1, "circle": 2, "diamond": 3, "triangle": 4,
+	"moon": 5, "square": 6, "cross": 7, "x": 7, "skull": 8,
+}
+
+var (
+	// escapedColorRe and escapedResetRe undo the pipe doubling the game's
+	// edit box applies when a note is copied out of it, so "||cffC41E3AArx||r"
+	// reads the same as "|cffC41E3AArx|r". A bare "||" is left alone — that's
+	// a separator.
+	escapedColorRe = regexp.MustCompile(`\|\|(c[0-9a-fA-F]{8})`)
+	escapedResetRe = regexp.MustCompile(`(\S)\|\|r`)
+
+	raidNoteTokenRe = regexp.MustCompile(`\|c([0-9a-fA-F]{8})(.*?)(?:\|r|$)` +
+		`|\{spell:(\d+)\}` +
+		`|\{rt([1-8])\}` +
+		`|\{(?i:(star|circle|diamond|triangle|moon|square|cross|x|skull))\}` +
+		`|\{time:([^}]*)\}` +
+		`|\|\|` +
+		`|\{[^}]*\}` +
+		`|\|r`)
+)
+
+// RaidNoteEntry is one name on a raid note line. Group counts the "||"
+// separators before it, for lines that split players into groups. SpellID
+// is a {spell:} icon directly after the name — their assigned cooldown.
+type RaidNoteEntry struct {
+	Name    string `json:"name"`
+	Color   string `json:"color,omitempty"`
+	SpellID *int   `json:"spell_id,omitempty"`
+	Group   int    `json:"group"`
+}
+
+// RaidNoteLine is one line of a pasted note with its markup pulled apart.
+// Label is the text before the last colon (blank for bare lists of names);
+// SpellID is a {spell:} icon ahead of the first name. Heading lines keep
+// their text but no entries.
+type RaidNoteLine struct {
+	Raw     string          `json:"raw"`
+	Text    string          `json:"text"`
+	Label   string          `json:"label"`
+	SpellID *int            `json:"spell_id,omitempty"`
+	Markers []int           `json:"markers"`
+	Times   []string        `json:"times"`
+	Entries []RaidNoteEntry `json:"entries"`
+}
+
+type RaidNoteSection struct {
+	Heading RaidNoteLine   `json:"heading"`
+	Lines   []RaidNoteLine `json:"lines"`
+	// EndLine is the raw "...end" line that closed a "...start" block, if
+	// any — with Heading.Raw and each line's Raw, the block as written.
+	EndLine string `json:"end_line,omitempty"`
+}
+
+// RaidNoteTimelineTag is the tag: list of one NSRT timeline line
+// ("time:57;ph:1;tag:Arx Gruesum;spellid:51052;").
+type RaidNoteTimelineTag struct {
+	Phase   string   `json:"phase"`
+	Time    string   `json:"time"`
+	Players []string `json:"players"`
+}
+
+type ParsedRaidNote struct {
+	Sections []RaidNoteSection `json:"sections"`
+	// Skipped holds the NSRT header and timeline lines, which aren't
+	// assignment blocks; Timeline is the tag: names read from them.
+	Skipped  []string              `json:"skipped"`
+	Timeline []RaidNoteTimelineTag `json:"timeline"`
+}
+
+// noteTagKeywords are tag: values that address a group rather than a
+// character.
+var noteTagKeywords = map[string]bool{
+	"everyone": true,
+}
+
+// IsRaidNoteTimelineLine reports whether a note line is an NSRT header or
+// timeline line rather than part of an assignment block. Timeline lines
+// are told apart by their "time:" prefix rather than by containing it, so
+// {time:} timers inside assignment lines aren't mistaken for one.
+func IsRaidNoteTimelineLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "time:") || strings.Contains(trimmed, "EncounterID")
+}
+
+type raidNoteItem struct {
+	kind    string // "word", "colon", "spell", "sep"
+	text    string
+	color   string
+	spellId int
+}
+
+// ParseRaidNoteLine strips one line's markup — color codes, {spell:},
+// {rtN} and named markers, {time:} timers, "||" separators and any other
+// {icon} — keeping what each meant.
+func ParseRaidNoteLine(raw string) RaidNoteLine {
+	line := RaidNoteLine{Raw: raw, Markers: []int{}, Times: []string{}, Entries: []RaidNoteEntry{}}
+	s := escapedColorRe.ReplaceAllString(raw, "|$1")
+	s = escapedResetRe.ReplaceAllString(s, "$1|r")
+
+	var items []raidNoteItem
+	var text strings.Builder
+	addPlain := func(plain, color string) {
+		text.WriteString(plain)
+		for i, part := range strings.Split(plain, ":") {
+			if i > 0 {
+				items = append(items, raidNoteItem{kind: "colon"})
+			}
+			for _, word := range strings.Fields(part) {
+				items = append(items, raidNoteItem{kind: "word", text: word, color: color})
+			}
+		}
+	}
+
+	last := 0
+	for _, m := range raidNoteTokenRe.FindAllStringSubmatchIndex(s, -1) {
+		addPlain(s[last:m[0]], "")
+		last = m[1]
+		group := func(n int) string {
+			if m[2*n] < 0 {
+				return ""
+			}
+			return s[m[2*n]:m[2*n+1]]
+		}
+		switch token := s[m[0]:m[1]]; {
+		case group(1) != "":
+			addPlain(group(2), strings.ToUpper(group(1)[2:]))
+		case group(3) != "":
+			id, _ := strconv.Atoi(group(3))
+			items = append(items, raidNoteItem{kind: "spell", spellId: id})
+		case group(4) != "":
+			n, _ := strconv.Atoi(group(4))
+			line.Markers = append(line.Markers, n)
+		case group(5) != "":
+			line.Markers = append(line.Markers, raidMarkerNames[strings.ToLower(group(5))])
+		case strings.HasPrefix(token, "{time:"):
+			line.Times = append(line.Times, group(6))
+		case token == "||":
+			items = append(items, raidNoteItem{kind: "sep"})
+			text.WriteString(" ")
+		}
+	}
+	addPlain(s[last:], "")
+	line.Text = strings.Join(strings.Fields(text.String()), " ")
+
+	start := 0
+	for i, item := range items {
+		if item.kind == "colon" {
+			start = i + 1
+		}
+	}
+	if start > 0 {
+		label := []string{}
+		for _, item := range items[:start-1] {
+			switch {
+			case item.kind == "word":
+				label = append(label, item.text)
+			case item.kind == "spell" && line.SpellID == nil:
+				id := item.spellId
+				line.SpellID = &id
+			}
+		}
+		line.Label = strings.Join(label, " ")
+	}
+
+	group := 0
+	for _, item := range items[start:] {
+		switch item.kind {
+		case "word":
+			line.Entries = append(line.Entries, RaidNoteEntry{Name: item.text, Color: item.color, Group: group})
+		case "sep":
+			group++
+		case "spell":
+			id := item.spellId
+			if n := len(line.Entries); n > 0 {
+				line.Entries[n-1].SpellID = &id
+			} else if line.SpellID == nil {
+				line.SpellID = &id
+			}
+		}
+	}
+	return line
+}
+
+// ParseRaidNote splits a note — a pasted in-game note or a stored
+// AssignmentNote — into its assignment blocks and NSRT timeline, the one
+// parser both go through. Blocks follow the layout of cleanAndSeparate in
+// the frontend's Assignments.tsx — a heading line, then "Label: names"
+// lines until a blank line, with "...start" blocks closing on their
+// matching "...end" line — read from the markup-stripped text, so
+// class-colored names and icons parse the same as plain ones.
+func ParseRaidNote(note string) ParsedRaidNote {
+	parsed := ParsedRaidNote{Sections: []RaidNoteSection{}, Skipped: []string{}, Timeline: []RaidNoteTimelineTag{}}
+	inBlock := false
+	for _, raw := range strings.Split(note, "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		if IsRaidNoteTimelineLine(raw) {
+			parsed.Skipped = append(parsed.Skipped, raw)
+			if tag, ok := parseRaidNoteTimelineLine(raw); ok {
+				parsed.Timeline = append(parsed.Timeline, tag)
+			}
+			continue
+		}
+
+		line := ParseRaidNoteLine(raw)
+		if line.blank() {
+			inBlock = false
+			continue
+		}
+		if inBlock {
+			section := &parsed.Sections[len(parsed.Sections)-1]
+			if end, ok := raidNoteBlockEnd(section.Heading.Text); ok && strings.EqualFold(line.Text, end) {
+				section.EndLine = raw
+				inBlock = false
+				continue
+			}
+		}
+		if inBlock {
+			section := &parsed.Sections[len(parsed.Sections)-1]
+			section.Lines = append(section.Lines, line)
+			continue
+		}
+		inBlock = true
+		line.Entries = []RaidNoteEntry{}
+		parsed.Sections = append(parsed.Sections, RaidNoteSection{Heading: line, Lines: []RaidNoteLine{}})
+	}
+	return parsed
+}
+
+// blank reports whether a line has nothing on it once markup is stripped —
+// a {spell:} icon, marker or timer on its own still counts as content.
+func (l RaidNoteLine) blank() bool {
+	return l.Text == "" && l.SpellID == nil && len(l.Markers) == 0 && len(l.Times) == 0 && len(l.Entries) == 0
+}
+
+// raidNoteBlockEnd returns the line that closes a "...start" block: the
+// heading with its last "start" swapped for "end", so "intstart" closes on
+// "intend" and not on any line that happens to contain "end".
+func raidNoteBlockEnd(heading string) (string, bool) {
+	lower := strings.ToLower(heading)
+	i := strings.LastIndex(lower, "start")
+	if i < 0 {
+		return "", false
+	}
+	return lower[:i] + "end" + lower[i+len("start"):], true
+}
+
+// RawLines is the section as written in the note, markup and all.
+func (s RaidNoteSection) RawLines() []string {
+	lines := []string{s.Heading.Raw}
+	for _, line := range s.Lines {
+		lines = append(lines, line.Raw)
+	}
+	if s.EndLine != "" {
+		lines = append(lines, s.EndLine)
+	}
+	return lines
+}
+
+func parseRaidNoteTimelineLine(line string) (RaidNoteTimelineTag, bool) {
+	var tag RaidNoteTimelineTag
+	found := false
+	for _, field := range strings.Split(line, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "time":
+			tag.Time = value
+		case "ph":
+			tag.Phase = value
+		case "tag":
+			found = true
+			for _, name := range strings.Fields(value) {
+				if !noteTagKeywords[strings.ToLower(name)] {
+					tag.Players = append(tag.Players, name)
+				}
+			}
+		}
+	}
+	return tag, found && len(tag.Players) > 0
+}
+
+// nameFolds maps accented Latin letters to their base letter — the ones
+// WoW allows in character names.
+var nameFolds = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ð", "d", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+// LooseNameKey is NameMatchKey after dropping any realm suffix and folding
+// accents, so "Tëttybear-Area52" and "Tettybear" compare equal. Looser
+// than a character name really is — two different characters can share a
+// key — so only use it to match against a single team's roster.
+func LooseNameKey(name string) string {
+	return nameFolds.Replace(NameMatchKey(StripRealm(name)))
+}
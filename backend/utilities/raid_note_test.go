@@ -0,0 +1,268 @@
+package utilities
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRaidNoteLine(t *testing.T) {
+	spell := func(id int) *int { return &id }
+
+	tests := []struct {
+		name        string
+		raw         string
+		wantText    string
+		wantLabel   string
+		wantSpell   *int
+		wantMarkers []int
+		wantTimes   []string
+		wantEntries []RaidNoteEntry
+	}{
+		{
+			name:        "label and names",
+			raw:         "Tanks: Jaemsy Gruesum",
+			wantText:    "Tanks: Jaemsy Gruesum",
+			wantLabel:   "Tanks",
+			wantEntries: []RaidNoteEntry{{Name: "Jaemsy"}, {Name: "Gruesum"}},
+		},
+		{
+			name:        "bare list of names",
+			raw:         "Pkrz Zaghunt Stridur",
+			wantText:    "Pkrz Zaghunt Stridur",
+			wantEntries: []RaidNoteEntry{{Name: "Pkrz"}, {Name: "Zaghunt"}, {Name: "Stridur"}},
+		},
+		{
+			name:        "colored names",
+			raw:         "Healers: |cffc41e3aTettybear|r Funkdrip |cFF0070DDWynsloww|r",
+			wantText:    "Healers: Tettybear Funkdrip Wynsloww",
+			wantLabel:   "Healers",
+			wantEntries: []RaidNoteEntry{{Name: "Tettybear", Color: "C41E3A"}, {Name: "Funkdrip"}, {Name: "Wynsloww", Color: "0070DD"}},
+		},
+		{
+			name:        "escaped color codes",
+			raw:         "Soak: ||cffC41E3AArx||r Uchai",
+			wantText:    "Soak: Arx Uchai",
+			wantLabel:   "Soak",
+			wantEntries: []RaidNoteEntry{{Name: "Arx", Color: "C41E3A"}, {Name: "Uchai"}},
+		},
+		{
+			name:        "unterminated color runs to the end of the line",
+			raw:         "Soak: |cffC41E3AArx Uchai",
+			wantText:    "Soak: Arx Uchai",
+			wantLabel:   "Soak",
+			wantEntries: []RaidNoteEntry{{Name: "Arx", Color: "C41E3A"}, {Name: "Uchai", Color: "C41E3A"}},
+		},
+		{
+			name:        "groups split by ||",
+			raw:         "Soaks: Arx Uchai || Goomt||Pkrz",
+			wantText:    "Soaks: Arx Uchai Goomt Pkrz",
+			wantLabel:   "Soaks",
+			wantEntries: []RaidNoteEntry{{Name: "Arx"}, {Name: "Uchai"}, {Name: "Goomt", Group: 1}, {Name: "Pkrz", Group: 2}},
+		},
+		{
+			name:        "line spell and per-name spells",
+			raw:         "{spell:31821} Aura: Magicpally {spell:98008} Wynsloww",
+			wantText:    "Aura: Magicpally Wynsloww",
+			wantLabel:   "Aura",
+			wantSpell:   spell(31821),
+			wantEntries: []RaidNoteEntry{{Name: "Magicpally", SpellID: spell(98008)}, {Name: "Wynsloww"}},
+		},
+		{
+			name:        "spell ahead of the first name on a bare line",
+			raw:         "{spell:740} Tettybear",
+			wantText:    "Tettybear",
+			wantSpell:   spell(740),
+			wantEntries: []RaidNoteEntry{{Name: "Tettybear"}},
+		},
+		{
+			name:        "label splits on the last colon",
+			raw:         "Pop Crystal 1 (6:31): Zaghunt",
+			wantText:    "Pop Crystal 1 (6:31): Zaghunt",
+			wantLabel:   "Pop Crystal 1 (6 31)",
+			wantEntries: []RaidNoteEntry{{Name: "Zaghunt"}},
+		},
+		{
+			name:        "markers, timers and unknown icons",
+			raw:         "{rt8}{Skull} Kick {time:1:30}{unknown}: Arx",
+			wantText:    "Kick : Arx",
+			wantLabel:   "Kick",
+			wantMarkers: []int{8, 8},
+			wantTimes:   []string{"1:30"},
+			wantEntries: []RaidNoteEntry{{Name: "Arx"}},
+		},
+		{
+			name:      "spell only",
+			raw:       "{spell:31821}",
+			wantSpell: spell(31821),
+		},
+		{
+			name: "blank",
+			raw:  "   ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := ParseRaidNoteLine(tt.raw)
+			if line.Raw != tt.raw {
+				t.Errorf("Raw = %q, want %q", line.Raw, tt.raw)
+			}
+			if line.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", line.Text, tt.wantText)
+			}
+			if line.Label != tt.wantLabel {
+				t.Errorf("Label = %q, want %q", line.Label, tt.wantLabel)
+			}
+			if !reflect.DeepEqual(line.SpellID, tt.wantSpell) {
+				t.Errorf("SpellID = %v, want %v", line.SpellID, tt.wantSpell)
+			}
+			if len(line.Markers) != len(tt.wantMarkers) || (len(tt.wantMarkers) > 0 && !reflect.DeepEqual(line.Markers, tt.wantMarkers)) {
+				t.Errorf("Markers = %v, want %v", line.Markers, tt.wantMarkers)
+			}
+			if len(line.Times) != len(tt.wantTimes) || (len(tt.wantTimes) > 0 && !reflect.DeepEqual(line.Times, tt.wantTimes)) {
+				t.Errorf("Times = %v, want %v", line.Times, tt.wantTimes)
+			}
+			if len(line.Entries) != len(tt.wantEntries) || (len(tt.wantEntries) > 0 && !reflect.DeepEqual(line.Entries, tt.wantEntries)) {
+				t.Errorf("Entries = %+v, want %+v", line.Entries, tt.wantEntries)
+			}
+		})
+	}
+}
+
+func TestParseRaidNote(t *testing.T) {
+	// section is a parsed block reduced to what the tests check: its
+	// heading text, each line's text and the end line, if any.
+	type section struct {
+		heading string
+		lines   []string
+		end     string
+	}
+
+	tests := []struct {
+		name         string
+		note         string
+		wantSections []section
+		wantSkipped  int
+		wantTimeline []RaidNoteTimelineTag
+	}{
+		{
+			name: "blocks split on blank lines",
+			note: "Intermission Spreads\nTanks: Jaemsy Gruesum\nHealers: Tettybear\n\nP3 Sides\r\nLeft: Arx\r\n",
+			wantSections: []section{
+				{heading: "Intermission Spreads", lines: []string{"Tanks: Jaemsy Gruesum", "Healers: Tettybear"}},
+				{heading: "P3 Sides", lines: []string{"Left: Arx"}},
+			},
+		},
+		{
+			name: "colored heading and names",
+			note: "|cffff0000P2 Soaks|r\nMelee: |cffC41E3AArx|r ||cff0070DDUchai||r",
+			wantSections: []section{
+				{heading: "P2 Soaks", lines: []string{"Melee: Arx Uchai"}},
+			},
+		},
+		{
+			name: "start block closes on its own end line",
+			note: "intstart\nPkrz Zaghunt\nDefend the end: Arx\nintend\nP2 Soaks\nMelee: Arx",
+			wantSections: []section{
+				{heading: "intstart", lines: []string{"Pkrz Zaghunt", "Defend the end: Arx"}, end: "intend"},
+				{heading: "P2 Soaks", lines: []string{"Melee: Arx"}},
+			},
+		},
+		{
+			name: "end line matches ignoring case and markup",
+			note: "Kick Start\nKickers: Arx\n|cffffffffKICK END|r",
+			wantSections: []section{
+				{heading: "Kick Start", lines: []string{"Kickers: Arx"}, end: "|cffffffffKICK END|r"},
+			},
+		},
+		{
+			name: "another block's end line doesn't close the block",
+			note: "intstart\nPkrz\nphase end\nZaghunt\nintend",
+			wantSections: []section{
+				{heading: "intstart", lines: []string{"Pkrz", "phase end", "Zaghunt"}, end: "intend"},
+			},
+		},
+		{
+			name: "blocks without start keep end lines",
+			note: "Weekend Kicks\nend: Arx",
+			wantSections: []section{
+				{heading: "Weekend Kicks", lines: []string{"end: Arx"}},
+			},
+		},
+		{
+			name: "spell-only line stays in its block",
+			note: "Healing CDs\n{spell:31821}\nAura: Magicpally",
+			wantSections: []section{
+				{heading: "Healing CDs", lines: []string{"", "Aura: Magicpally"}},
+			},
+		},
+		{
+			name: "timeline lines are skipped and their tags read",
+			note: "EncounterID:3129;Difficulty:Mythic;\ntime:57;ph:1;tag:Arx Gruesum;spellid:51052;\ntime:90;ph:2;tag:everyone;\nP1 Spreads\nLeft: Arx {time:0:57}",
+			wantSections: []section{
+				{heading: "P1 Spreads", lines: []string{"Left: Arx"}},
+			},
+			wantSkipped:  3,
+			wantTimeline: []RaidNoteTimelineTag{{Phase: "1", Time: "57", Players: []string{"Arx", "Gruesum"}}},
+		},
+		{
+			name: "empty note",
+			note: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := ParseRaidNote(tt.note)
+			got := make([]section, len(parsed.Sections))
+			for i, s := range parsed.Sections {
+				got[i] = section{heading: s.Heading.Text, end: s.EndLine}
+				for _, line := range s.Lines {
+					got[i].lines = append(got[i].lines, line.Text)
+				}
+				if len(s.Heading.Entries) != 0 {
+					t.Errorf("heading %q has entries %v", s.Heading.Text, s.Heading.Entries)
+				}
+			}
+			if len(got) != len(tt.wantSections) || (len(got) > 0 && !reflect.DeepEqual(got, tt.wantSections)) {
+				t.Errorf("sections = %+v, want %+v", got, tt.wantSections)
+			}
+			if len(parsed.Skipped) != tt.wantSkipped {
+				t.Errorf("skipped %d lines (%q), want %d", len(parsed.Skipped), parsed.Skipped, tt.wantSkipped)
+			}
+			if len(parsed.Timeline) != len(tt.wantTimeline) || (len(tt.wantTimeline) > 0 && !reflect.DeepEqual(parsed.Timeline, tt.wantTimeline)) {
+				t.Errorf("timeline = %+v, want %+v", parsed.Timeline, tt.wantTimeline)
+			}
+		})
+	}
+}
+
+func TestRaidNoteSectionRawLines(t *testing.T) {
+	note := "intstart\n|cffC41E3AArx|r Uchai\nintend"
+	parsed := ParseRaidNote(note)
+	if len(parsed.Sections) != 1 {
+		t.Fatalf("got %d sections, want 1", len(parsed.Sections))
+	}
+	if got := strings.Join(parsed.Sections[0].RawLines(), "\n"); got != note {
+		t.Errorf("RawLines = %q, want %q", got, note)
+	}
+}
+
+func TestLooseNameKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Tettybear", "tettybear"},
+		{"Tëttybear", "tettybear"},
+		{"Tëttybear-Area52", "tettybear"},
+		{"ÆLFWINÉ", "aelfwine"},
+		{"Mal'Ganis", "malganis"},
+		{"Ñoño", "nono"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := LooseNameKey(tt.name); got != tt.want {
+			t.Errorf("LooseNameKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -128,7 +128,7 @@ func CreateUser(c *gin.Context, userToken *descope.Token) (models.User, error) {
 
 	wowProfileData, err := json.Marshal(bnetUserData.WowProfileData)
 	if err != nil {
-		return user, fmt.Errorf("converting wow profile data to json: %w", wowProfileData)
+		return user, fmt.Errorf("converting wow profile data to json: %w", err)
 	}
 
 	user = models.User{BTag: bnetUserData.Battletag, BNetId: bnetUserData.BlizzardUserID, DescopeUserId: descopeUserId, DescopeLoginId: descopeLoginId, FirstLogin: true, BnetProfileData: wowProfileData}